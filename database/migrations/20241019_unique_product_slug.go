@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// UniqueProductSlug suffixes duplicated product slugs ("gamis-syari",
+// "gamis-syari-2", ...) and then adds the unique index that keeps them apart.
+func UniqueProductSlug(db *gorm.DB) error {
+	var count int64
+	db.Raw(`SELECT COUNT(*)
+            FROM INFORMATION_SCHEMA.STATISTICS
+            WHERE TABLE_SCHEMA = DATABASE()
+            AND TABLE_NAME = 'produk'
+            AND INDEX_NAME = 'idx_produk_slug'`).Scan(&count)
+
+	if count > 0 {
+		return nil
+	}
+
+	type row struct {
+		ID   uint
+		Slug string
+	}
+
+	var rows []row
+	err := db.Raw(`SELECT id, slug FROM produk ORDER BY id ASC`).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, r := range rows {
+		taken[r.Slug] = true
+	}
+
+	seen := map[string]bool{}
+	for _, r := range rows {
+		if !seen[r.Slug] {
+			seen[r.Slug] = true
+			continue
+		}
+
+		candidate := r.Slug
+		for n := 2; taken[candidate]; n++ {
+			candidate = fmt.Sprintf("%s-%d", r.Slug, n)
+		}
+		taken[candidate] = true
+		seen[candidate] = true
+
+		err := db.Exec(`UPDATE produk SET slug = ? WHERE id = ?`, candidate, r.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Exec(`CREATE UNIQUE INDEX idx_produk_slug ON produk (slug)`).Error
+}
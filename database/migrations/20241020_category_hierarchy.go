@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+// CategoryHierarchy fills the path and slug of categories created before
+// categories could be nested, then adds the unique slug index.
+func CategoryHierarchy(db *gorm.DB) error {
+	err := db.Exec(`UPDATE category SET path = CONCAT('/', id, '/'), depth = 0
+                    WHERE path = '' AND id_parent IS NULL`).Error
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	db.Raw(`SELECT COUNT(*)
+            FROM INFORMATION_SCHEMA.STATISTICS
+            WHERE TABLE_SCHEMA = DATABASE()
+            AND TABLE_NAME = 'category'
+            AND INDEX_NAME = 'idx_category_slug'`).Scan(&count)
+
+	if count > 0 {
+		return nil
+	}
+
+	type row struct {
+		ID           uint
+		NamaCategory string
+		Slug         string
+	}
+
+	var rows []row
+	err = db.Raw(`SELECT id, nama_category, slug FROM category ORDER BY id ASC`).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, r := range rows {
+		if r.Slug != "" {
+			taken[r.Slug] = true
+		}
+	}
+
+	for _, r := range rows {
+		if r.Slug != "" {
+			continue
+		}
+
+		base := slug.Make(r.NamaCategory)
+		if base == "" {
+			base = "category"
+		}
+
+		candidate := base
+		for n := 2; taken[candidate]; n++ {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[candidate] = true
+
+		err := db.Exec(`UPDATE category SET slug = ? WHERE id = ?`, candidate, r.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Exec(`CREATE UNIQUE INDEX idx_category_slug ON category (slug)`).Error
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// UnifyProductPhotos numbers the photos of products that have no cover yet,
+// in upload order, and makes the first one the cover. It also drops the
+// photo_id column the old FotoProduk model added.
+func UnifyProductPhotos(db *gorm.DB) error {
+	type row struct {
+		ID       uint
+		IDProduk uint
+	}
+
+	var rows []row
+	err := db.Raw(`SELECT id, id_produk FROM foto_produk
+                   WHERE deleted_at IS NULL
+                   AND id_produk NOT IN (SELECT id_produk FROM foto_produk WHERE utama = TRUE AND deleted_at IS NULL)
+                   ORDER BY id_produk ASC, id ASC`).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	posisi := map[uint]int{}
+	for _, r := range rows {
+		n := posisi[r.IDProduk]
+		posisi[r.IDProduk] = n + 1
+
+		err := db.Exec(`UPDATE foto_produk SET posisi = ?, utama = ? WHERE id = ?`, n, n == 0, r.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var count int64
+	db.Raw(`SELECT COUNT(*)
+            FROM INFORMATION_SCHEMA.COLUMNS
+            WHERE TABLE_SCHEMA = DATABASE()
+            AND TABLE_NAME = 'foto_produk'
+            AND COLUMN_NAME = 'photo_id'`).Scan(&count)
+
+	if count == 0 {
+		return nil
+	}
+
+	return db.Exec(`ALTER TABLE foto_produk DROP COLUMN photo_id`).Error
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/gosimple/slug"
+	"gorm.io/gorm"
+)
+
+// StoreSlugs gives stores created before the public storefront a slug made
+// from their name, then adds the unique slug index.
+func StoreSlugs(db *gorm.DB) error {
+	var count int64
+	db.Raw(`SELECT COUNT(*)
+            FROM INFORMATION_SCHEMA.STATISTICS
+            WHERE TABLE_SCHEMA = DATABASE()
+            AND TABLE_NAME = 'toko'
+            AND INDEX_NAME = 'idx_toko_slug'`).Scan(&count)
+
+	if count > 0 {
+		return nil
+	}
+
+	type row struct {
+		ID       uint
+		NamaToko *string
+		Slug     string
+	}
+
+	var rows []row
+	err := db.Raw(`SELECT id, nama_toko, slug FROM toko ORDER BY id ASC`).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, r := range rows {
+		if r.Slug != "" {
+			taken[r.Slug] = true
+		}
+	}
+
+	for _, r := range rows {
+		if r.Slug != "" {
+			continue
+		}
+
+		base := ""
+		if r.NamaToko != nil {
+			base = slug.Make(*r.NamaToko)
+		}
+		if base == "" {
+			base = "toko"
+		}
+
+		candidate := base
+		for n := 2; taken[candidate]; n++ {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[candidate] = true
+
+		err := db.Exec(`UPDATE toko SET slug = ? WHERE id = ?`, candidate, r.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Exec(`CREATE UNIQUE INDEX idx_toko_slug ON toko (slug)`).Error
+}
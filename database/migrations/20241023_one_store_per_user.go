@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// OneStorePerUser merges the extra stores of users who own more than one
+// into their first store, the one their products were attached to, then adds
+// a unique index over the active store of each user. Products, reviews and
+// followers move; transaction history stays with the merged store, which is
+// soft-deleted.
+func OneStorePerUser(db *gorm.DB) error {
+	var count int64
+	db.Raw(`SELECT COUNT(*)
+            FROM INFORMATION_SCHEMA.STATISTICS
+            WHERE TABLE_SCHEMA = DATABASE()
+            AND TABLE_NAME = 'toko'
+            AND INDEX_NAME = 'idx_toko_user_aktif'`).Scan(&count)
+
+	if count > 0 {
+		return nil
+	}
+
+	type row struct {
+		ID     uint
+		IDUser uint
+	}
+
+	var rows []row
+	err := db.Raw(`SELECT id, id_user FROM toko
+                   WHERE deleted_at IS NULL
+                   AND id_user IN (SELECT id_user FROM toko WHERE deleted_at IS NULL GROUP BY id_user HAVING COUNT(*) > 1)
+                   ORDER BY id_user ASC, id ASC`).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	if len(rows) > 0 {
+		log.Printf("One store per user: %d stores belong to users with several stores", len(rows))
+	}
+
+	keep := map[uint]uint{}
+	for _, r := range rows {
+		if _, ok := keep[r.IDUser]; !ok {
+			keep[r.IDUser] = r.ID
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return mergeStore(tx, r.ID, keep[r.IDUser])
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("One store per user: merged store %d of user %d into store %d", r.ID, r.IDUser, keep[r.IDUser])
+	}
+
+	// id_user_aktif is id_user while the store is active, so a user may have
+	// deleted stores next to the one they use
+	return db.Exec(`ALTER TABLE toko
+                    ADD COLUMN id_user_aktif BIGINT UNSIGNED AS (IF(deleted_at IS NULL, id_user, NULL)) STORED,
+                    ADD UNIQUE INDEX idx_toko_user_aktif (id_user_aktif)`).Error
+}
+
+func mergeStore(tx *gorm.DB, from uint, into uint) error {
+	// SKUs are unique per store: a clashing one keeps the product id as suffix
+	result := tx.Exec(`UPDATE produk SET sku = CONCAT(sku, '-', id)
+                       WHERE id_toko = ? AND sku IN (SELECT sku FROM (SELECT sku FROM produk WHERE id_toko = ? AND sku IS NOT NULL) taken)`,
+		from, into)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("One store per user: %d products of store %d had a SKU already used in store %d and were suffixed with their id", result.RowsAffected, from, into)
+	}
+
+	statements := []string{
+		`UPDATE produk SET id_toko = @into WHERE id_toko = @from`,
+		`UPDATE ulasan SET id_toko = @into WHERE id_toko = @from`,
+		`DELETE FROM pengikut_toko WHERE id_toko = @from
+         AND id_user IN (SELECT id_user FROM (SELECT id_user FROM pengikut_toko WHERE id_toko = @into) following)`,
+		`UPDATE pengikut_toko SET id_toko = @into WHERE id_toko = @from`,
+		`UPDATE toko SET
+         jumlah_ulasan = jumlah_ulasan + (SELECT jumlah_ulasan FROM (SELECT jumlah_ulasan FROM toko WHERE id = @from) merged),
+         total_rating = total_rating + (SELECT total_rating FROM (SELECT total_rating FROM toko WHERE id = @from) merged),
+         jumlah_pengikut = (SELECT COUNT(*) FROM pengikut_toko WHERE id_toko = @into)
+         WHERE id = @into`,
+		`UPDATE toko SET jumlah_ulasan = 0, total_rating = 0, jumlah_pengikut = 0, deleted_at = NOW() WHERE id = @from`,
+	}
+
+	for _, statement := range statements {
+		err := tx.Exec(statement, map[string]interface{}{"from": from, "into": into}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// StoreOwners makes the owner of every active store a member of it with role
+// owner, so access checks only need to look at memberships. Stores created
+// before memberships existed have none.
+func StoreOwners(db *gorm.DB) error {
+	result := db.Exec(`INSERT INTO anggota_toko (id_toko, id_user, peran, izin, created_at, updated_at)
+                       SELECT toko.id, toko.id_user, 'owner', '', NOW(), NOW() FROM toko
+                       WHERE toko.deleted_at IS NULL
+                       AND NOT EXISTS (SELECT 1 FROM anggota_toko WHERE anggota_toko.id_toko = toko.id AND anggota_toko.id_user = toko.id_user)`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Store owners: added %d owners as members of their store", result.RowsAffected)
+	}
+
+	return nil
+}
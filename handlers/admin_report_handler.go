@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/spreadsheet"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminReportHandler struct {
+	AdminReportService services.AdminReportService
+}
+
+func NewAdminReportHandler(adminReportService *services.AdminReportService) AdminReportHandler {
+	return AdminReportHandler{*adminReportService}
+}
+
+// Every report takes the query parameters from and to (YYYY-MM-DD), the
+// reports with a series bucket (day or week) and the top lists limit.
+func (handler *AdminReportHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/admin/reports", middleware.JWTProtected(), middleware.RolePermissionAdmin)
+	routes.Get("/gmv", handler.GMV)
+	routes.Get("/orders", handler.OrdersByStatus)
+	routes.Get("/signups", handler.Signups)
+	routes.Get("/top-categories", handler.TopCategories)
+	routes.Get("/top-stores", handler.TopStores)
+	routes.Get("/refunds", handler.Refunds)
+	routes.Get("/:report/export", handler.Export)
+}
+
+// reportInput reads the report filters from the query
+func reportInput(c *fiber.Ctx) models.AdminReportRequest {
+	input := models.AdminReportRequest{
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Bucket: c.Query("bucket"),
+	}
+
+	if c.Query("limit") != "" {
+		if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+			input.Limit = val
+		}
+	}
+
+	return input
+}
+
+func (handler *AdminReportHandler) GMV(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.GMV(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *AdminReportHandler) OrdersByStatus(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.OrdersByStatus(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *AdminReportHandler) Signups(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.Signups(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *AdminReportHandler) TopCategories(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.TopCategories(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *AdminReportHandler) TopStores(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.TopStores(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *AdminReportHandler) Refunds(c *fiber.Ctx) error {
+	response, err := handler.AdminReportService.Refunds(reportInput(c))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// Export downloads a report, CSV unless format asks for xlsx
+func (handler *AdminReportHandler) Export(c *fiber.Ctx) error {
+	report := c.Params("report")
+	format := c.Query("format", spreadsheet.FormatCSV)
+
+	write, err := handler.AdminReportService.Export(report, reportInput(c), format)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	filename := fmt.Sprintf("laporan-%s-%s.%s", report, time.Now().Format("2006_01_02_15_04_05"), format)
+	c.Set(fiber.HeaderContentType, spreadsheet.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Headers are already sent once streaming starts, so failures can only be logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(w); err != nil {
+			log.Printf("Failed to export report %s: %v", report, err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CatalogHandler serves the public, unauthenticated storefront.
+type CatalogHandler struct {
+	ProductService        services.ProductService
+	SuggestService        services.SuggestService
+	RecommendationService services.RecommendationService
+	StoreService          services.StoreService
+	StoreAnalyticsService services.StoreAnalyticsService
+}
+
+func NewCatalogHandler(productService *services.ProductService, suggestService *services.SuggestService, recommendationService *services.RecommendationService, storeService *services.StoreService, storeAnalyticsService *services.StoreAnalyticsService) CatalogHandler {
+	return CatalogHandler{*productService, *suggestService, *recommendationService, *storeService, *storeAnalyticsService}
+}
+
+// maxSuggestions caps the suggestions returned per group.
+const maxSuggestions = 20
+
+// maxCatalogLimit caps the page size of the public product listings.
+const maxCatalogLimit = 100
+
+func (handler *CatalogHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/catalog")
+	routes.Get("/products", handler.ProductList)
+	routes.Get("/products/:slug", handler.ProductDetail)
+	routes.Get("/suggest", handler.Suggest)
+	routes.Get("/stores/:slug", handler.Storefront)
+}
+
+func (handler *CatalogHandler) ProductList(c *fiber.Ctx) error {
+	// Default values
+	limit := 10
+	page := 1
+
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = min(val, maxCatalogLimit)
+	}
+
+	if val, err := strconv.Atoi(c.Query("page")); err == nil && val > 0 {
+		page = val
+	}
+
+	keyword := c.Query("keyword")
+	sort := c.Query("sort")
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductService.GetCatalog(limit, page, keyword, sort, filter, c.IP())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *CatalogHandler) ProductDetail(c *fiber.Ctx) error {
+	response, redirect, err := handler.ProductService.GetCatalogBySlug(c.Params("slug"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	// Every visit counts towards the store's analytics
+	handler.StoreAnalyticsService.RecordView(response.ID)
+
+	// Signed-in shoppers get the product in their recently viewed list
+	if claims, err := jwt.ExtractTokenMetadata(c); err == nil {
+		handler.RecommendationService.TrackView(uint(claims.UserId), response.ID)
+	}
+
+	// Renamed product: tell the client where it lives now
+	if redirect != nil {
+		c.Set(fiber.HeaderLocation, redirect.Location)
+		return c.Status(http.StatusMovedPermanently).JSON(responder.ApiResponse{
+			Status:  true,
+			Message: "Moved Permanently",
+			Error:   nil,
+			Data: fiber.Map{
+				"redirect": redirect,
+				"product":  response,
+			},
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// Storefront shows a store's profile with its published products. The
+// product list takes the same query parameters as ProductList, store_id
+// aside.
+func (handler *CatalogHandler) Storefront(c *fiber.Ctx) error {
+	store, err := handler.StoreService.GetBySlug(c.Params("slug"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	limit := 10
+	page := 1
+
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = min(val, maxCatalogLimit)
+	}
+
+	if val, err := strconv.Atoi(c.Query("page")); err == nil && val > 0 {
+		page = val
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+	filter.StoreID = store.ID
+
+	products, err := handler.ProductService.GetCatalog(limit, page, c.Query("keyword"), c.Query("sort"), filter, c.IP())
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data: models.StorefrontResponse{
+			Store:    store,
+			Products: products,
+		},
+	})
+}
+
+func (handler *CatalogHandler) Suggest(c *fiber.Ctx) error {
+	limit := 5
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = min(val, maxSuggestions)
+	}
+
+	response, err := handler.SuggestService.Suggest(c.Query("q"), limit)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// parseProductFilter reads the optional search filters from the query string.
+func parseProductFilter(c *fiber.Ctx) (models.ProductFilter, error) {
+	filter := models.ProductFilter{
+		CityID: c.Query("city_id"),
+	}
+
+	if value := c.Query("in_stock"); value != "" {
+		in_stock, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid in_stock: %v", err)
+		}
+		filter.InStock = in_stock
+	}
+
+	if value := c.Query("include_descendants"); value != "" {
+		include_descendants, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid include_descendants: %v", err)
+		}
+		filter.IncludeDescendants = include_descendants
+	}
+
+	if value := c.Query("category_id"); value != "" {
+		category_id, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid category_id: %v", err)
+		}
+		filter.CategoryID = uint(category_id)
+	}
+
+	if value := c.Query("store_id"); value != "" {
+		store_id, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid store_id: %v", err)
+		}
+		filter.StoreID = uint(store_id)
+	}
+
+	if value := c.Query("min_price"); value != "" {
+		min_price, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid min_price: %v", err)
+		}
+		filter.MinPrice = &min_price
+	}
+
+	if value := c.Query("max_price"); value != "" {
+		max_price, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid max_price: %v", err)
+		}
+		filter.MaxPrice = &max_price
+	}
+
+	return filter, nil
+}
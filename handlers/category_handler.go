@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 )
 
 type CategoryHandler struct {
@@ -19,17 +20,24 @@ func NewCategoryHandler(categoryService *services.CategoryService) CategoryHandl
 	return CategoryHandler{*categoryService}
 }
 
+// categoryCacheControl lets clients reuse category reads for a while and
+// revalidate them with the ETag afterwards.
+const categoryCacheControl = "public, max-age=300"
+
+// Route keeps reads public so sellers can find category ids; writes stay
+// admin only.
 func (handler *CategoryHandler) Route(app *fiber.App) {
 	routes := app.Group("/api/v1/category")
-	routes.Get("/", middleware.JWTProtected(), middleware.RolePermissionAdmin, handler.CategoryList)
-	routes.Get("/:id", middleware.JWTProtected(), middleware.RolePermissionAdmin, handler.CategoryDetail)
+	routes.Get("/", etag.New(), handler.CategoryList)
+	routes.Get("/tree", etag.New(), handler.CategoryTree)
+	routes.Get("/:id", etag.New(), handler.CategoryDetail)
 	routes.Post("/", middleware.JWTProtected(), middleware.RolePermissionAdmin, handler.CategoryCreate)
 	routes.Put("/:id", middleware.JWTProtected(), middleware.RolePermissionAdmin, handler.CategoryEdit)
 	routes.Delete("/:id", middleware.JWTProtected(), middleware.RolePermissionAdmin, handler.CategoryDelete)
 }
 
 func (handler *CategoryHandler) CategoryList(c *fiber.Ctx) error {
-	responses, err := handler.CategoryService.GetAll()
+	responses, err := handler.CategoryService.GetPublicAll()
 	if err != nil {
 		//error
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
@@ -39,6 +47,7 @@ func (handler *CategoryHandler) CategoryList(c *fiber.Ctx) error {
 			Data:    nil,
 		})
 	}
+	c.Set(fiber.HeaderCacheControl, categoryCacheControl)
 	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
 		Status:  true,
 		Message: "Succeed to GET data",
@@ -47,9 +56,10 @@ func (handler *CategoryHandler) CategoryList(c *fiber.Ctx) error {
 	})
 }
 
-func (handler *CategoryHandler) CategoryDetail(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
+func (handler *CategoryHandler) CategoryTree(c *fiber.Ctx) error {
+	responses, err := handler.CategoryService.GetTree()
 	if err != nil {
+		//error
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
 			Status:  false,
 			Message: "Failed to GET data",
@@ -57,17 +67,28 @@ func (handler *CategoryHandler) CategoryDetail(c *fiber.Ctx) error {
 			Data:    nil,
 		})
 	}
+	c.Set(fiber.HeaderCacheControl, categoryCacheControl)
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
 
-	response, err := handler.CategoryService.GetById(uint(id))
+// CategoryDetail accepts either the category id or its slug.
+func (handler *CategoryHandler) CategoryDetail(c *fiber.Ctx) error {
+	response, err := handler.CategoryService.GetPublicDetail(c.Params("id"))
 	if err != nil {
 		//error
-		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+		return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
 			Status:  false,
 			Message: "Failed to GET data",
 			Error:   exceptions.NewString(err.Error()),
 			Data:    nil,
 		})
 	}
+	c.Set(fiber.HeaderCacheControl, categoryCacheControl)
 	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
 		Status:  true,
 		Message: "Succeed to GET data",
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/media"
+	"mini-project-evermos/utils/storage"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MediaHandler struct {
+	MediaService services.MediaService
+}
+
+func NewMediaHandler(mediaService *services.MediaService) MediaHandler {
+	return MediaHandler{*mediaService}
+}
+
+// Route serves uploads at the default local STORAGE_PUBLIC_URL. Fiber
+// answers HEAD on GET routes too.
+func (handler *MediaHandler) Route(app *fiber.App) {
+	app.Get("/uploads/:name", handler.Serve)
+}
+
+func (handler *MediaHandler) Serve(c *fiber.Ctx) error {
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return mediaNotFound(c)
+	}
+
+	object, err := handler.MediaService.Stat(name)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return mediaNotFound(c)
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	etag := mediaETag(object)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	if !object.ModTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, object.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	// A content hashed name never points at other bytes, so it can be cached
+	// for good. Other names can be replaced and are revalidated.
+	if media.IsHashedFile(name) {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "public, no-cache")
+	}
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	status := http.StatusOK
+	offset, length := int64(0), object.Size
+
+	// If-Range falls back to the whole file when the client's copy is stale
+	range_header := c.Get(fiber.HeaderRange)
+	if if_range := c.Get(fiber.HeaderIfRange); if_range != "" && if_range != etag {
+		range_header = ""
+	}
+	if range_header != "" {
+		start, end, ok, satisfiable := parseRange(range_header, object.Size)
+		if !satisfiable {
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", object.Size))
+			return c.SendStatus(http.StatusRequestedRangeNotSatisfiable)
+		}
+		if ok {
+			status = http.StatusPartialContent
+			offset, length = start, end-start+1
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, object.Size))
+		}
+	}
+
+	if object.ContentType != "" {
+		c.Set(fiber.HeaderContentType, object.ContentType)
+	}
+	c.Status(status)
+	if c.Method() == fiber.MethodHead {
+		c.Response().Header.SetContentLength(int(length))
+		return nil
+	}
+
+	reader, err := handler.MediaService.Open(name, offset, length)
+	if err != nil {
+		return mediaNotFound(c)
+	}
+
+	return c.SendStream(reader, int(length))
+}
+
+func mediaNotFound(c *fiber.Ctx) error {
+	return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
+		Status:  false,
+		Message: "Failed to GET data",
+		Error:   exceptions.NewString(storage.ErrNotFound.Error()),
+		Data:    nil,
+	})
+}
+
+// mediaETag is strong: hashed names already identify the bytes, other files
+// are identified by size and modification time
+func mediaETag(object storage.Object) string {
+	if media.IsHashedFile(object.Key) {
+		return `"` + strings.TrimSuffix(object.Key, filepath.Ext(object.Key)) + `"`
+	}
+	return fmt.Sprintf(`"%x-%x"`, object.Size, object.ModTime.UnixNano())
+}
+
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange reads a single byte range. ok is false when the header should
+// be ignored and the whole file sent, which is also how multiple ranges are
+// answered.
+func parseRange(header string, size int64) (start int64, end int64, ok bool, satisfiable bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, true
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, true
+	}
+
+	if first == "" {
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, true
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, true
+	}
+	if start >= size {
+		return 0, 0, false, false
+	}
+
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, true
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+
+	return start, end, true, true
+}
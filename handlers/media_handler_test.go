@@ -0,0 +1,64 @@
+package handlers
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header      string
+		size        int64
+		start       int64
+		end         int64
+		ok          bool
+		satisfiable bool
+	}{
+		{"bytes=0-99", 1000, 0, 99, true, true},
+		{"bytes=100-", 1000, 100, 999, true, true},
+		{"bytes=900-5000", 1000, 900, 999, true, true},
+		{"bytes=-100", 1000, 900, 999, true, true},
+		{"bytes=-5000", 1000, 0, 999, true, true},
+		{"bytes= 10-20", 1000, 10, 20, true, true},
+		{"bytes=999-999", 1000, 999, 999, true, true},
+
+		// Ignored, the whole file is sent
+		{"items=0-99", 1000, 0, 0, false, true},
+		{"bytes=0-99,200-299", 1000, 0, 0, false, true},
+		{"bytes=abc-", 1000, 0, 0, false, true},
+		{"bytes=20-10", 1000, 0, 0, false, true},
+		{"bytes=5", 1000, 0, 0, false, true},
+		{"bytes=-x", 1000, 0, 0, false, true},
+
+		// Not satisfiable
+		{"bytes=1000-", 1000, 0, 0, false, false},
+		{"bytes=-0", 1000, 0, 0, false, false},
+		{"bytes=0-", 0, 0, 0, false, false},
+		{"bytes=-10", 0, 0, 0, false, false},
+	}
+
+	for _, test := range tests {
+		start, end, ok, satisfiable := parseRange(test.header, test.size)
+		if ok != test.ok || satisfiable != test.satisfiable || (ok && (start != test.start || end != test.end)) {
+			t.Errorf("parseRange(%q, %d) = %d, %d, %v, %v, want %d, %d, %v, %v",
+				test.header, test.size, start, end, ok, satisfiable, test.start, test.end, test.ok, test.satisfiable)
+		}
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{``, false},
+	}
+
+	for _, test := range tests {
+		if got := etagMatches(test.header, `"abc"`); got != test.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	NotificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) NotificationHandler {
+	return NotificationHandler{*notificationService}
+}
+
+func (handler *NotificationHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/notification")
+	routes.Get("/", middleware.JWTProtected(), handler.MyNotifications)
+	routes.Put("/:id/read", middleware.JWTProtected(), handler.MarkAsRead)
+}
+
+func (handler *NotificationHandler) MyNotifications(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.NotificationService.GetByUserId(uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.NotificationService.MarkAsRead(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"mini-project-evermos/exceptions"
 	"mini-project-evermos/middleware"
@@ -8,19 +10,20 @@ import (
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/services"
 	"mini-project-evermos/utils/jwt"
+	"mini-project-evermos/utils/media"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type ProductHandler struct {
 	ProductService services.ProductService
+	MediaService   services.MediaService
 }
 
-func NewProductHandler(productService *services.ProductService) ProductHandler {
-	return ProductHandler{*productService}
+func NewProductHandler(productService *services.ProductService, mediaService *services.MediaService) ProductHandler {
+	return ProductHandler{*productService, *mediaService}
 }
 
 func (handler *ProductHandler) Route(app *fiber.App) {
@@ -29,10 +32,21 @@ func (handler *ProductHandler) Route(app *fiber.App) {
 	routes.Get("/:id", middleware.JWTProtected(), handler.ProductDetail)
 	routes.Post("/", middleware.JWTProtected(), handler.ProductCreate)
 	routes.Put("/:id", middleware.JWTProtected(), handler.ProductUpdate)
+	routes.Put("/:id/status", middleware.JWTProtected(), handler.ProductStatus)
 	routes.Delete("/:id", middleware.JWTProtected(), handler.ProductDelete)
 }
 
 func (handler *ProductHandler) GetAllProduct(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
 	// Default values
 	limit := 10
 	page := 1
@@ -51,8 +65,19 @@ func (handler *ProductHandler) GetAllProduct(c *fiber.Ctx) error {
 	}
 
 	keyword := c.FormValue("keyword")
+	status := c.FormValue("status")
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
 
-	responses, err := handler.ProductService.GetAll(limit, page, keyword)
+	responses, err := handler.ProductService.GetAll(limit, page, keyword, status, id_toko, uint(claims.UserId))
 
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
@@ -148,15 +173,50 @@ func (handler *ProductHandler) ProductCreate(c *fiber.Ctx) error {
 		})
 	}
 
+	stok_minimum, err := parseStokMinimum(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid stok_minimum",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	atribut, err := parseAtribut(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid atribut",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
 	// Create product request
 	input := models.ProductRequest{
 		NamaProduk:    c.FormValue("nama_produk"),
+		StoreID:       id_toko,
+		SKU:           c.FormValue("sku"),
 		CategoryID:    uint(category_id),
 		HargaReseller: c.FormValue("harga_reseller"),
 		HargaKonsumen: c.FormValue("harga_konsumen"),
-		Stok:          stok,
+		Stok:          &stok,
+		StokMinimum:   stok_minimum,
 		Deskripsi:     c.FormValue("deskripsi"),
-		PhotoURLs:     []string{c.FormValue("photo_url")}, // Use photo_url instead of file upload
+		PhotoURLs:     []string{media.Name(c.FormValue("photo_url"))}, // Use photo_url instead of file upload
+		Attributes:    atribut,
+		Status:        c.FormValue("status"),
 	}
 
 	response, err := handler.ProductService.Create(input, uint(user_id))
@@ -202,30 +262,82 @@ func (handler *ProductHandler) ProductUpdate(c *fiber.Ctx) error {
 		})
 	}
 
-	formFile, err := c.MultipartForm()
-	var file_name []string
-	for _, fileHeaders := range formFile.File {
-		for _, fileHeader := range fileHeaders {
-			date_now := time.Now()
-			string_date := date_now.Format("2006_01_02_15_04_05")
-
-			filename := string_date + "-" + fileHeader.Filename
-			c.SaveFile(fileHeader, fmt.Sprintf("uploads/%s", filename))
-			file_name = append(file_name, filename)
+	// Fields left out keep their current values
+	category_id := 0
+	if c.FormValue("category_id") != "" {
+		category_id, err = strconv.Atoi(c.FormValue("category_id"))
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Invalid category_id",
+				Error:   exceptions.NewString(err.Error()),
+				Data:    nil,
+			})
 		}
 	}
 
-	category_id, err := strconv.Atoi(c.FormValue("category_id"))
-	stok, err := strconv.Atoi(c.FormValue("stok"))
+	stok, err := parseStok(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid stok",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	stok_minimum, err := parseStokMinimum(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid stok_minimum",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	atribut, err := parseAtribut(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid atribut",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var file_name []string
+	if formFile, err := c.MultipartForm(); err == nil {
+		for _, fileHeaders := range formFile.File {
+			for _, fileHeader := range fileHeaders {
+				upload, err := handler.MediaService.Upload(fileHeader)
+				if err != nil {
+					for _, v := range file_name {
+						handler.MediaService.Remove(v)
+					}
+					return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+						Status:  false,
+						Message: "Failed to PUT data",
+						Error:   exceptions.NewString(err.Error()),
+						Data:    nil,
+					})
+				}
+				file_name = append(file_name, upload.Name)
+			}
+		}
+	}
 
 	input := models.ProductRequest{}
 	input.NamaProduk = c.FormValue("nama_produk")
+	input.SKU = c.FormValue("sku")
 	input.CategoryID = uint(category_id)
 	input.HargaReseller = c.FormValue("harga_reseller")
 	input.HargaKonsumen = c.FormValue("harga_konsumen")
 	input.Stok = stok
+	input.StokMinimum = stok_minimum
 	input.Deskripsi = c.FormValue("deskripsi")
 	input.PhotoURLs = file_name // Changed from Photos to PhotoURLs
+	input.Attributes = atribut
 
 	response, err := handler.ProductService.Update(input, uint(id), uint(user_id))
 
@@ -288,3 +400,99 @@ func (handler *ProductHandler) ProductDelete(c *fiber.Ctx) error {
 		Data:    response,
 	})
 }
+
+func (handler *ProductHandler) ProductStatus(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ProductStatusRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductService.ChangeStatus(uint(id), uint(claims.UserId), input.Status)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// parseStok reads the stock of an update. It returns nil when it was not
+// sent, which keeps the current stock.
+func parseStok(c *fiber.Ctx) (*int, error) {
+	if c.FormValue("stok") == "" {
+		return nil, nil
+	}
+
+	stok, err := strconv.Atoi(c.FormValue("stok"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &stok, nil
+}
+
+// parseStokMinimum reads the optional low-stock threshold, returning nil when it was not sent.
+func parseStokMinimum(c *fiber.Ctx) (*int, error) {
+	if c.FormValue("stok_minimum") == "" {
+		return nil, nil
+	}
+
+	stok_minimum, err := strconv.Atoi(c.FormValue("stok_minimum"))
+	if err != nil {
+		return nil, err
+	}
+	if stok_minimum < 0 {
+		return nil, errors.New("stok_minimum must not be negative")
+	}
+
+	return &stok_minimum, nil
+}
+
+// parseAtribut reads the category attribute values, sent as a JSON object
+// such as {"bahan":"katun"}. It returns nil when they were not sent.
+func parseAtribut(c *fiber.Ctx) (map[string]string, error) {
+	if c.FormValue("atribut") == "" {
+		return nil, nil
+	}
+
+	atribut := map[string]string{}
+	if err := json.Unmarshal([]byte(c.FormValue("atribut")), &atribut); err != nil {
+		return nil, fmt.Errorf("atribut must be a JSON object of strings: %v", err)
+	}
+
+	return atribut, nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"mini-project-evermos/utils/spreadsheet"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImportHandler struct {
+	ProductImportService services.ProductImportService
+}
+
+func NewProductImportHandler(productImportService *services.ProductImportService) ProductImportHandler {
+	return ProductImportHandler{*productImportService}
+}
+
+// Route must be registered before ProductHandler, whose /:id would
+// otherwise catch /export.
+func (handler *ProductImportHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/product")
+	routes.Post("/import", middleware.JWTProtected(), handler.Import)
+	routes.Get("/import/:id", middleware.JWTProtected(), handler.ImportStatus)
+	routes.Get("/export", middleware.JWTProtected(), handler.Export)
+}
+
+func (handler *ProductImportHandler) Import(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	dry_run := false
+	if value := c.FormValue("dry_run"); value != "" {
+		dry_run, err = strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Invalid dry_run",
+				Error:   exceptions.NewString(err.Error()),
+				Data:    nil,
+			})
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid file",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid file",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid file",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductImportService.Import(id_toko, uint(claims.UserId), fileHeader.Filename, data, dry_run)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusAccepted).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ProductImportHandler) ImportStatus(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductImportService.GetById(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ProductImportHandler) Export(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	format := c.Query("format", spreadsheet.FormatCSV)
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	write, err := handler.ProductImportService.Export(id_toko, uint(claims.UserId), format)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	filename := fmt.Sprintf("produk-%s.%s", time.Now().Format("2006_01_02_15_04_05"), format)
+	c.Set(fiber.HeaderContentType, spreadsheet.ContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Headers are already sent once streaming starts, so failures can only be logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(w); err != nil {
+			log.Printf("Failed to export products: %v", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
@@ -3,13 +3,11 @@ package handlers
 import (
 	"mini-project-evermos/exceptions"
 	"mini-project-evermos/middleware"
-	"mini-project-evermos/models"
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/services"
 	"net/http"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 type ProductLogHandler struct {
@@ -20,42 +18,14 @@ func NewProductLogHandler(productLogService *services.ProductLogService) Product
 	return ProductLogHandler{*productLogService}
 }
 
+// Product logs are snapshots that transactions point at, written by product
+// edits and checkout only, so there are no routes to add, change or remove
+// them.
 func (handler *ProductLogHandler) Route(app *fiber.App) {
 	routes := app.Group("/api/v1/product-logs")
 	routes.Get("/", middleware.JWTProtected(), handler.GetAll)
 	routes.Get("/:id", middleware.JWTProtected(), handler.GetById)
-	routes.Post("/", middleware.JWTProtected(), handler.Create)
-	routes.Put("/:id", middleware.JWTProtected(), handler.Update)
-	routes.Delete("/:id", middleware.JWTProtected(), handler.Delete)
-}
-
-func (handler *ProductLogHandler) Create(c *fiber.Ctx) error {
-	var input models.ProductLogProcess
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
-			Status:  false,
-			Message: "Failed to parse request",
-			Error:   exceptions.NewString(err.Error()),
-			Data:    nil,
-		})
-	}
-
-	response, err := handler.ProductLogService.Create(input)
-	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
-			Status:  false,
-			Message: "Failed to create product log",
-			Error:   exceptions.NewString(err.Error()),
-			Data:    nil,
-		})
-	}
-
-	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
-		Status:  true,
-		Message: "Successfully created product log",
-		Error:   nil,
-		Data:    response,
-	})
+	routes.Get("/product/:id/price-history", middleware.JWTProtected(), handler.GetPriceHistory)
 }
 
 // Add new handler method
@@ -108,8 +78,7 @@ func (handler *ProductLogHandler) GetById(c *fiber.Ctx) error {
 	})
 }
 
-// Add new handler method
-func (handler *ProductLogHandler) Update(c *fiber.Ctx) error {
+func (handler *ProductLogHandler) GetPriceHistory(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
@@ -120,68 +89,11 @@ func (handler *ProductLogHandler) Update(c *fiber.Ctx) error {
 		})
 	}
 
-	var input models.ProductLogProcess
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
-			Status:  false,
-			Message: "Failed to parse request",
-			Error:   exceptions.NewString(err.Error()),
-			Data:    nil,
-		})
-	}
-
-	// Attempt update
-	response, err := handler.ProductLogService.Update(uint(id), input)
-	if err != nil {
-		if err.Error() == "record not found" {
-			return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
-				Status:  false,
-				Message: "Product log not found",
-				Error:   exceptions.NewString(err.Error()),
-				Data:    nil,
-			})
-		}
-		return c.Status(http.StatusInternalServerError).JSON(responder.ApiResponse{
-			Status:  false,
-			Message: "Failed to update product log",
-			Error:   exceptions.NewString(err.Error()),
-			Data:    nil,
-		})
-	}
-
-	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
-		Status:  true,
-		Message: "Successfully updated product log",
-		Error:   nil,
-		Data:    response,
-	})
-}
-
-// Add new handler method
-func (handler *ProductLogHandler) Delete(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
+	response, err := handler.ProductLogService.GetPriceHistory(uint(id))
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
 			Status:  false,
-			Message: "Invalid ID format",
-			Error:   exceptions.NewString(err.Error()),
-			Data:    nil,
-		})
-	}
-
-	response, err := handler.ProductLogService.Delete(uint(id))
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
-				Status:  false,
-				Message: "Product log not found",
-				Error:   exceptions.NewString(err.Error()),
-				Data:    nil,
-			})
-		}
-		return c.Status(http.StatusInternalServerError).JSON(responder.ApiResponse{
-			Status:  false,
-			Message: "Failed to delete product log",
+			Message: "Failed to get price history",
 			Error:   exceptions.NewString(err.Error()),
 			Data:    nil,
 		})
@@ -189,7 +101,7 @@ func (handler *ProductLogHandler) Delete(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
 		Status:  true,
-		Message: "Successfully deleted product log",
+		Message: "Successfully retrieved price history",
 		Error:   nil,
 		Data:    response,
 	})
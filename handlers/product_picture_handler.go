@@ -1,70 +1,411 @@
 package handlers
 
 import (
+	"fmt"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
 	"mini-project-evermos/models"
-	"mini-project-evermos/models/entities"
-	"mini-project-evermos/repositories"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
 	"net/http"
+	"strconv"
 
-	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
 )
 
-type Handler struct {
-	productPictureRepo repositories.ProductPictureRepository
+type ProductPictureHandler struct {
+	ProductPictureService services.ProductPictureService
+	MediaService          services.MediaService
 }
 
-func NewHandler(productPictureRepo repositories.ProductPictureRepository) *Handler {
-	return &Handler{
-		productPictureRepo: productPictureRepo,
+func NewProductPictureHandler(productPictureService *services.ProductPictureService, mediaService *services.MediaService) ProductPictureHandler {
+	return ProductPictureHandler{*productPictureService, *mediaService}
+}
+
+func (handler *ProductPictureHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1")
+	routes.Get("/product-photos/:id", middleware.JWTProtected(), handler.GetById)
+	routes.Get("/product/:id/photos", middleware.JWTProtected(), handler.GetByProductId)
+	routes.Post("/product/:id/photos", middleware.JWTProtected(), handler.Upload)
+	routes.Put("/product/:id/photos/order", middleware.JWTProtected(), handler.Reorder)
+	routes.Post("/product-photos", middleware.JWTProtected(), handler.Create)
+	routes.Put("/product-photos/:id", middleware.JWTProtected(), handler.Update)
+	routes.Put("/product-photos/:id/primary", middleware.JWTProtected(), handler.SetPrimary)
+	routes.Delete("/product-photos/:id", middleware.JWTProtected(), handler.Delete)
+}
+
+func (handler *ProductPictureHandler) GetById(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
 	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductPictureService.GetById(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to get photo",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success get photo",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ProductPictureHandler) GetByProductId(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	productId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid product ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductPictureService.GetByProductId(uint(productId), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to get photos",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success get photos",
+		Error:   nil,
+		Data:    responses,
+	})
 }
 
-func (h *Handler) CreateProductPicture(c *gin.Context) {
-	var request models.ProductPictureRequest
-	if err := c.ShouldBind(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  false,
-			"message": "BAD REQUEST",
-			"errors":  err.Error(),
-			"data":    nil,
+// Upload attaches every file sent as photos to the product, after its other
+// photos. Either all of them are attached or none.
+func (handler *ProductPictureHandler) Upload(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
 		})
-		return
 	}
 
-	// Verify if product exists
-	if !h.productPictureRepo.VerifyProductExists(request.ProductID) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"status":  false,
-			"message": "Product not found",
-			"errors":  "Product with given ID does not exist",
-			"data":    nil,
+	productId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid product ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
 		})
-		return
 	}
 
-	productPicture := &entities.ProductPicture{
-		IDProduk: request.ProductID, // Changed from ProductID to IDProduk
-		Url:      request.URL,       // Changed from URL to Url
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid input",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	photos := form.File["photos"]
+	if len(photos) > models.MaxProductPhotoUpload {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to upload photos",
+			Error:   exceptions.NewString(fmt.Sprintf("at most %d photos can be uploaded at once", models.MaxProductPhotoUpload)),
+			Data:    nil,
+		})
+	}
+
+	var file_name []string
+	for _, fileHeader := range photos {
+		upload, err := handler.MediaService.Upload(fileHeader)
+		if err != nil {
+			for _, v := range file_name {
+				handler.MediaService.Remove(v)
+			}
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Failed to upload photos",
+				Error:   exceptions.NewString(err.Error()),
+				Data:    nil,
+			})
+		}
+		file_name = append(file_name, upload.Name)
+	}
+
+	responses, err := handler.ProductPictureService.CreateMany(uint(productId), file_name, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to upload photos",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success upload photos",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *ProductPictureHandler) Create(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ProductPictureRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid input",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductPictureService.Create(input, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to create photo",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success create photo",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ProductPictureHandler) Update(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ProductPictureRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid input",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductPictureService.Update(uint(id), input, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to update photo",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success update photo",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// Reorder takes every photo id of the product in the new order and returns
+// the photos as they are now listed
+func (handler *ProductPictureHandler) Reorder(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	productId, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid product ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ProductPictureOrderRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid input",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductPictureService.Reorder(uint(productId), input, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to reorder photos",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success reorder photos",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+// SetPrimary makes the photo the product's cover and returns the photos as
+// they are now listed
+func (handler *ProductPictureHandler) SetPrimary(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductPictureService.SetPrimary(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to set cover photo",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success set cover photo",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *ProductPictureHandler) Delete(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Unauthorized",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid ID",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
 	}
 
-	if err := h.productPictureRepo.Create(productPicture); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  false,
-			"message": "INTERNAL SERVER ERROR",
-			"errors":  err.Error(),
-			"data":    nil,
+	response, err := handler.ProductPictureService.Delete(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to delete photo",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
 		})
-		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  true,
-		"message": "Successfully created product picture",
-		"errors":  nil,
-		"data": models.ProductPictureResponse{
-			ID:       productPicture.ID,
-			IDProduk: productPicture.IDProduk,
-			Url:      productPicture.Url,
-		},
+	return c.JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Success delete photo",
+		Error:   nil,
+		Data:    response,
 	})
 }
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductReviewHandler struct {
+	ProductReviewService services.ProductReviewService
+}
+
+func NewProductReviewHandler(productReviewService *services.ProductReviewService) ProductReviewHandler {
+	return ProductReviewHandler{*productReviewService}
+}
+
+func (handler *ProductReviewHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/admin/product", middleware.JWTProtected(), middleware.RolePermissionAdmin)
+	routes.Get("/review", handler.ReviewQueue)
+	routes.Get("/:id/history", handler.ReviewHistory)
+	routes.Put("/:id/approve", handler.Approve)
+	routes.Put("/:id/reject", handler.Reject)
+}
+
+func (handler *ProductReviewHandler) ReviewQueue(c *fiber.Ctx) error {
+	// Default values
+	limit := 10
+	page := 1
+
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = val
+	}
+
+	if val, err := strconv.Atoi(c.Query("page")); err == nil && val > 0 {
+		page = val
+	}
+
+	responses, err := handler.ProductReviewService.GetQueue(limit, page)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *ProductReviewHandler) ReviewHistory(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductReviewService.GetHistory(uint(id))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *ProductReviewHandler) Approve(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductReviewService.Approve(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ProductReviewHandler) Reject(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ProductRejectRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductReviewService.Reject(uint(id), uint(claims.UserId), input.Alasan)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductTrashHandler struct {
+	ProductTrashService services.ProductTrashService
+}
+
+func NewProductTrashHandler(productTrashService *services.ProductTrashService) ProductTrashHandler {
+	return ProductTrashHandler{*productTrashService}
+}
+
+// Route must be registered before ProductHandler, whose /:id would
+// otherwise catch /trash.
+func (handler *ProductTrashHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/product")
+	routes.Get("/trash", middleware.JWTProtected(), handler.GetTrash)
+	routes.Put("/:id/restore", middleware.JWTProtected(), handler.Restore)
+}
+
+func (handler *ProductTrashHandler) GetTrash(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	limit := 10
+	page := 1
+
+	if c.FormValue("limit") != "" {
+		if val, err := strconv.Atoi(c.FormValue("limit")); err == nil {
+			limit = val
+		}
+	}
+
+	if c.FormValue("page") != "" {
+		if val, err := strconv.Atoi(c.FormValue("page")); err == nil {
+			page = val
+		}
+	}
+
+	keyword := c.FormValue("keyword")
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	responses, err := handler.ProductTrashService.GetAll(limit, page, keyword, id_toko, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *ProductTrashHandler) Restore(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ProductTrashService.Restore(uint(id), uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
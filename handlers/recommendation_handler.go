@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RecommendationHandler struct {
+	RecommendationService services.RecommendationService
+}
+
+func NewRecommendationHandler(recommendationService *services.RecommendationService) RecommendationHandler {
+	return RecommendationHandler{*recommendationService}
+}
+
+func (handler *RecommendationHandler) Route(app *fiber.App) {
+	catalog := app.Group("/api/v1/catalog")
+	catalog.Get("/products/:id/related", handler.Related)
+
+	user := app.Group("/api/v1/user")
+	user.Get("/recently-viewed", middleware.JWTProtected(), handler.RecentlyViewed)
+}
+
+func (handler *RecommendationHandler) Related(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	limit := 10
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = min(val, models.RelatedLimit)
+	}
+
+	responses, err := handler.RecommendationService.GetRelated(uint(id), limit)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
+
+func (handler *RecommendationHandler) RecentlyViewed(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	limit := 20
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = min(val, models.RecentlyViewedLimit)
+	}
+
+	responses, err := handler.RecommendationService.GetRecentlyViewed(uint(claims.UserId), limit)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    responses,
+	})
+}
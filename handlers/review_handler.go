@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"fmt"
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler struct {
+	ReviewService services.ReviewService
+	MediaService  services.MediaService
+}
+
+func NewReviewHandler(reviewService *services.ReviewService, mediaService *services.MediaService) ReviewHandler {
+	return ReviewHandler{*reviewService, *mediaService}
+}
+
+func (handler *ReviewHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/review")
+	routes.Post("/", middleware.JWTProtected(), handler.Create)
+	routes.Put("/:id/reply", middleware.JWTProtected(), handler.Reply)
+	routes.Post("/:id/flag", middleware.JWTProtected(), handler.Flag)
+
+	catalog := app.Group("/api/v1/catalog")
+	catalog.Get("/products/:slug/reviews", handler.ProductReviews)
+
+	admin := app.Group("/api/v1/admin/review", middleware.JWTProtected(), middleware.RolePermissionAdmin)
+	admin.Get("/flagged", handler.Flagged)
+	admin.Get("/:id/flags", handler.Flags)
+	admin.Put("/:id/hide", handler.Hide)
+	admin.Put("/:id/show", handler.Show)
+}
+
+func (handler *ReviewHandler) Create(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	detail_trx_id, err := strconv.Atoi(c.FormValue("detail_trx_id"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid detail_trx_id",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	rating, err := strconv.Atoi(c.FormValue("rating"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid rating",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var file_name []string
+	if form, err := c.MultipartForm(); err == nil {
+		photos := form.File["photos"]
+		if len(photos) > models.MaxReviewPhotos {
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Failed to POST data",
+				Error:   exceptions.NewString(fmt.Sprintf("a review can have at most %d photos", models.MaxReviewPhotos)),
+				Data:    nil,
+			})
+		}
+
+		for _, fileHeader := range photos {
+			upload, err := handler.MediaService.Upload(fileHeader)
+			if err != nil {
+				for _, v := range file_name {
+					handler.MediaService.Remove(v)
+				}
+				return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+					Status:  false,
+					Message: "Failed to POST data",
+					Error:   exceptions.NewString(err.Error()),
+					Data:    nil,
+				})
+			}
+			file_name = append(file_name, upload.Name)
+		}
+	}
+
+	input := models.ReviewRequest{
+		DetailTrxID: uint(detail_trx_id),
+		Rating:      rating,
+		Ulasan:      c.FormValue("ulasan"),
+		PhotoURLs:   file_name,
+	}
+
+	response, err := handler.ReviewService.Create(input, uint(claims.UserId))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ReviewHandler) Reply(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ReviewReplyRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ReviewService.Reply(uint(id), uint(claims.UserId), input)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ReviewHandler) Flag(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.ReviewFlagRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	if err := handler.ReviewService.Flag(uint(id), uint(claims.UserId), input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to POST data",
+		Error:   nil,
+		Data:    nil,
+	})
+}
+
+func (handler *ReviewHandler) ProductReviews(c *fiber.Ctx) error {
+	limit := 10
+	page := 1
+
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = val
+	}
+
+	if val, err := strconv.Atoi(c.Query("page")); err == nil && val > 0 {
+		page = val
+	}
+
+	rating := 0
+	if value := c.Query("rating"); value != "" {
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Invalid rating",
+				Error:   exceptions.NewString(err.Error()),
+				Data:    nil,
+			})
+		}
+		rating = val
+	}
+
+	response, err := handler.ReviewService.GetByProductSlug(c.Params("slug"), rating, limit, page)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ReviewHandler) Flagged(c *fiber.Ctx) error {
+	limit := 10
+	page := 1
+
+	if val, err := strconv.Atoi(c.Query("limit")); err == nil && val > 0 {
+		limit = val
+	}
+
+	if val, err := strconv.Atoi(c.Query("page")); err == nil && val > 0 {
+		page = val
+	}
+
+	response, err := handler.ReviewService.GetFlagged(limit, page)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ReviewHandler) Flags(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.ReviewService.GetFlags(uint(id))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+func (handler *ReviewHandler) Hide(c *fiber.Ctx) error {
+	return handler.moderate(c, handler.ReviewService.Hide)
+}
+
+func (handler *ReviewHandler) Show(c *fiber.Ctx) error {
+	return handler.moderate(c, handler.ReviewService.Show)
+}
+
+func (handler *ReviewHandler) moderate(c *fiber.Ctx, action func(id uint) (models.ReviewResponse, error)) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := action(uint(id))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
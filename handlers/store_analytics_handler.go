@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"mini-project-evermos/exceptions"
+	"mini-project-evermos/middleware"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/services"
+	"mini-project-evermos/utils/jwt"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StoreAnalyticsHandler struct {
+	StoreAnalyticsService services.StoreAnalyticsService
+}
+
+func NewStoreAnalyticsHandler(storeAnalyticsService *services.StoreAnalyticsService) StoreAnalyticsHandler {
+	return StoreAnalyticsHandler{*storeAnalyticsService}
+}
+
+func (handler *StoreAnalyticsHandler) Route(app *fiber.App) {
+	routes := app.Group("/api/v1/toko")
+	routes.Get("/my/analytics", middleware.JWTProtected(), handler.GetAnalytics)
+}
+
+// GetAnalytics reports the store's sales and views. Query parameters: from
+// and to (YYYY-MM-DD), bucket (day or week), top and id_toko for a store the
+// user is a member of.
+func (handler *StoreAnalyticsHandler) GetAnalytics(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id_toko, err := actingStoreID(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Invalid id_toko",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	input := models.StoreAnalyticsRequest{
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Bucket: c.Query("bucket"),
+	}
+
+	if c.Query("top") != "" {
+		if val, err := strconv.Atoi(c.Query("top")); err == nil && val > 0 {
+			input.Top = val
+		}
+	}
+
+	response, err := handler.StoreAnalyticsService.GetAnalytics(id_toko, uint(claims.UserId), input)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to GET data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to GET data",
+		Error:   nil,
+		Data:    response,
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"mini-project-evermos/exceptions"
 	"mini-project-evermos/middleware"
@@ -8,6 +9,7 @@ import (
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/services"
 	"mini-project-evermos/utils/jwt"
+	"mini-project-evermos/utils/media"
 	"net/http"
 	"strconv"
 
@@ -16,10 +18,11 @@ import (
 
 type StoreHandler struct {
 	StoreService services.StoreService
+	MediaService services.MediaService
 }
 
-func NewStoreHandler(storeService *services.StoreService) StoreHandler {
-	return StoreHandler{*storeService}
+func NewStoreHandler(storeService *services.StoreService, mediaService *services.MediaService) StoreHandler {
+	return StoreHandler{*storeService, *mediaService}
 }
 
 func (handler *StoreHandler) Route(app *fiber.App) {
@@ -30,6 +33,7 @@ func (handler *StoreHandler) Route(app *fiber.App) {
 	routes.Get("/", middleware.JWTProtected(), handler.GetAllStore)
 	routes.Get("/:id_toko", middleware.JWTProtected(), handler.StoreDetail)
 	routes.Put("/:id_toko", middleware.JWTProtected(), handler.EditStore)
+	routes.Put("/:id_toko/libur", middleware.JWTProtected(), handler.StoreVacation)
 	routes.Delete("/:id_toko", middleware.JWTProtected(), handler.DeleteStore)
 }
 
@@ -174,13 +178,22 @@ func (handler *StoreHandler) EditStore(c *fiber.Ctx) error {
 	}
 
 	name_store := c.FormValue("nama_toko")
-	url_foto := c.FormValue("url_foto")
+	url_foto := media.Name(c.FormValue("url_foto"))
 
 	// Handle both file upload and URL cases
 	formHeader, err := c.FormFile("photo")
 	if err == nil {
-		// If file was uploaded, use the filename
-		url_foto = formHeader.Filename
+		// If file was uploaded, use the processed upload
+		upload, err := handler.MediaService.Upload(formHeader)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+				Status:  false,
+				Message: "Failed to PUT data",
+				Error:   exceptions.NewString(err.Error()),
+				Data:    nil,
+			})
+		}
+		url_foto = upload.Name
 	} else if url_foto == "" {
 		// If no file and no URL provided, keep existing photo
 		store, err := handler.StoreService.GetById(uint(id_toko), uint(user_id))
@@ -195,9 +208,10 @@ func (handler *StoreHandler) EditStore(c *fiber.Ctx) error {
 		NamaToko: &name_store,
 		URL:      url_foto,
 	}
-
-	response, err := handler.StoreService.Edit(input)
-	if err != nil {
+	if err := parseStoreProfile(c, &input); err != nil {
+		if formHeader != nil {
+			handler.MediaService.Remove(url_foto)
+		}
 		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
 			Status:  false,
 			Message: "Failed to PUT data",
@@ -206,17 +220,38 @@ func (handler *StoreHandler) EditStore(c *fiber.Ctx) error {
 		})
 	}
 
-	// Save file only if one was uploaded
-	if formHeader != nil {
-		saveErr := c.SaveFile(formHeader, fmt.Sprintf("uploads/%s", *response.UrlFoto))
-		if saveErr != nil {
+	// A banner upload wins over url_banner
+	bannerHeader, err := c.FormFile("banner")
+	if err == nil {
+		upload, err := handler.MediaService.Upload(bannerHeader)
+		if err != nil {
+			if formHeader != nil {
+				handler.MediaService.Remove(url_foto)
+			}
 			return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
 				Status:  false,
 				Message: "Failed to PUT data",
-				Error:   exceptions.NewString(saveErr.Error()),
+				Error:   exceptions.NewString(err.Error()),
 				Data:    nil,
 			})
 		}
+		input.UrlBanner = &upload.Name
+	}
+
+	response, err := handler.StoreService.Edit(input)
+	if err != nil {
+		if formHeader != nil {
+			handler.MediaService.Remove(url_foto)
+		}
+		if bannerHeader != nil {
+			handler.MediaService.Remove(*input.UrlBanner)
+		}
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
 	}
 
 	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
@@ -241,7 +276,7 @@ func (handler *StoreHandler) StoreCreate(c *fiber.Ctx) error {
 
 	user_id := claims.UserId
 	nama_toko := c.FormValue("nama_toko")
-	url_foto := c.FormValue("url_foto")
+	url_foto := media.Name(c.FormValue("url_foto"))
 
 	// Only use default if url_foto is empty string or wasn't provided at all
 	if url_foto == "" {
@@ -255,6 +290,14 @@ func (handler *StoreHandler) StoreCreate(c *fiber.Ctx) error {
 		NamaToko: &nama_toko,
 		URL:      url_foto,
 	}
+	if err := parseStoreProfile(c, &input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to POST data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
 
 	response, err := handler.StoreService.Create(input)
 	if err != nil {
@@ -313,3 +356,100 @@ func (handler *StoreHandler) DeleteStore(c *fiber.Ctx) error {
 		Data:    response,
 	})
 }
+
+// StoreVacation turns vacation mode on or off. While it is on the store's
+// products cannot be checked out.
+func (handler *StoreHandler) StoreVacation(c *fiber.Ctx) error {
+	claims, err := jwt.ExtractTokenMetadata(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	id_toko, err := c.ParamsInt("id_toko")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	var input models.StoreVacationRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	response, err := handler.StoreService.SetVacation(uint(id_toko), uint(claims.UserId), input)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(responder.ApiResponse{
+			Status:  false,
+			Message: "Failed to PUT data",
+			Error:   exceptions.NewString(err.Error()),
+			Data:    nil,
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.ApiResponse{
+		Status:  true,
+		Message: "Succeed to PUT data",
+		Error:   nil,
+		Data:    response,
+	})
+}
+
+// parseStoreProfile reads the optional profile fields. Fields left out of the
+// form stay as they are; jam_operasional is a JSON array of
+// {"hari", "buka", "tutup"} and replaces the opening hours.
+func parseStoreProfile(c *fiber.Ctx, input *models.StoreProcess) error {
+	if value := optionalFormValue(c, "url_banner"); value != nil {
+		name := media.Name(*value)
+		input.UrlBanner = &name
+	}
+	input.Deskripsi = optionalFormValue(c, "deskripsi")
+	input.IDProvinsi = optionalFormValue(c, "id_provinsi")
+	input.IDKota = optionalFormValue(c, "id_kota")
+	input.Telepon = optionalFormValue(c, "telepon")
+	input.Whatsapp = optionalFormValue(c, "whatsapp")
+	input.Email = optionalFormValue(c, "email")
+	input.Instagram = optionalFormValue(c, "instagram")
+
+	if value := optionalFormValue(c, "jam_operasional"); value != nil {
+		input.JamOperasional = []models.StoreHourRequest{}
+		if *value != "" {
+			if err := json.Unmarshal([]byte(*value), &input.JamOperasional); err != nil {
+				return fmt.Errorf("jam_operasional must be a JSON array of {hari, buka, tutup}: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// optionalFormValue tells a field sent empty apart from one left out, which
+// is nil
+func optionalFormValue(c *fiber.Ctx, key string) *string {
+	if form, err := c.MultipartForm(); err == nil {
+		values, ok := form.Value[key]
+		if !ok || len(values) == 0 {
+			return nil
+		}
+		return &values[0]
+	}
+
+	if !c.Request().PostArgs().Has(key) {
+		return nil
+	}
+	value := c.FormValue(key)
+	return &value
+}
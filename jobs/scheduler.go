@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run by the Scheduler.
+type Job struct {
+	Name string
+	// Next returns the next time the job should run after now.
+	Next func(now time.Time) time.Time
+	Run  func() error
+}
+
+// Every schedules a job at a fixed interval.
+func Every(interval time.Duration) func(now time.Time) time.Time {
+	return func(now time.Time) time.Time {
+		return now.Add(interval)
+	}
+}
+
+// DailyAt schedules a job once a day at the given local hour and minute.
+func DailyAt(hour int, minute int) func(now time.Time) time.Time {
+	return func(now time.Time) time.Time {
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+type Scheduler struct {
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+func (scheduler *Scheduler) Register(job Job) {
+	scheduler.jobs = append(scheduler.jobs, job)
+}
+
+func (scheduler *Scheduler) Start() {
+	for _, job := range scheduler.jobs {
+		scheduler.wg.Add(1)
+		go scheduler.loop(job)
+	}
+}
+
+func (scheduler *Scheduler) Stop() {
+	close(scheduler.stop)
+	scheduler.wg.Wait()
+}
+
+func (scheduler *Scheduler) loop(job Job) {
+	defer scheduler.wg.Done()
+
+	for {
+		wait := time.Until(job.Next(time.Now()))
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-scheduler.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			log.Printf("[jobs] running %s", job.Name)
+			if err := job.Run(); err != nil {
+				log.Printf("[jobs] %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
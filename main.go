@@ -5,15 +5,23 @@ import (
 	"log"
 	"mini-project-evermos/configs"
 	"mini-project-evermos/handlers"
+	"mini-project-evermos/jobs"
 	"mini-project-evermos/models/entities" // Add this import
 	"mini-project-evermos/models/entities/migration"
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/repositories"
 	"mini-project-evermos/services"
+	"mini-project-evermos/utils/mailer"
+	"mini-project-evermos/utils/media"
+	"mini-project-evermos/utils/notifier"
+	"mini-project-evermos/utils/search"
+	"mini-project-evermos/utils/storage"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -73,7 +81,64 @@ func main() {
 	productPictureRepository := repositories.NewProductPictureRepository(database)
 	transactionRepository := repositories.NewTransactionRepository(database)
 	productLogRepository := repositories.NewProductLogRepository(database)
-	fotoProdukRepository := repositories.NewFotoProdukRepository(database)
+	notificationRepository := repositories.NewNotificationRepository(database)
+	searchLogRepository := repositories.NewSearchLogRepository(database)
+	productImportRepository := repositories.NewProductImportRepository(database)
+	reviewRepository := repositories.NewReviewRepository(database)
+	wishlistRepository := repositories.NewWishlistRepository(database)
+	storeFollowerRepository := repositories.NewStoreFollowerRepository(database)
+	recommendationRepository := repositories.NewRecommendationRepository(database)
+	mediaRepository := repositories.NewMediaRepository(database)
+	storeMemberRepository := repositories.NewStoreMemberRepository(database)
+	storeOrderRepository := repositories.NewStoreOrderRepository(database)
+	storeAnalyticsRepository := repositories.NewStoreAnalyticsRepository(database)
+	adminReportRepository := repositories.NewAdminReportRepository(database)
+
+	// Setup Search Index
+	searchIndex, err := search.New(configuration.Get("SEARCH_DRIVER"), database)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	trashRetentionDays, err := strconv.Atoi(configuration.Get("PRODUCT_TRASH_RETENTION_DAYS"))
+	if err != nil || trashRetentionDays < 1 {
+		trashRetentionDays = 30
+	}
+
+	// Setup Blob Storage
+	blobStorage, err := storage.New(storage.Config{
+		Driver:    configuration.Get("STORAGE_DRIVER"),
+		Directory: configuration.Get("STORAGE_LOCAL_DIRECTORY"),
+		PublicURL: configuration.Get("STORAGE_PUBLIC_URL"),
+		S3: storage.S3Config{
+			Endpoint:  configuration.Get("STORAGE_S3_ENDPOINT"),
+			Region:    configuration.Get("STORAGE_S3_REGION"),
+			Bucket:    configuration.Get("STORAGE_S3_BUCKET"),
+			AccessKey: configuration.Get("STORAGE_S3_ACCESS_KEY"),
+			SecretKey: configuration.Get("STORAGE_S3_SECRET_KEY"),
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Private buckets are read through presigned URLs
+	urlExpires, err := strconv.Atoi(configuration.Get("STORAGE_S3_URL_EXPIRES_MINUTES"))
+	if err != nil || urlExpires <= 0 {
+		urlExpires = 60
+	}
+	private := configuration.Get("STORAGE_DRIVER") == "s3" && configuration.Get("STORAGE_S3_PRIVATE") == "true"
+	media.SetURLResolver(storage.URLResolver(blobStorage, private, time.Duration(urlExpires)*time.Minute))
+
+	mediaLimits := media.DefaultLimits
+	if maxUploadMB, err := strconv.Atoi(configuration.Get("MEDIA_MAX_UPLOAD_MB")); err == nil && maxUploadMB > 0 {
+		mediaLimits.MaxBytes = int64(maxUploadMB) << 20
+	}
+	if maxDimension, err := strconv.Atoi(configuration.Get("MEDIA_MAX_DIMENSION")); err == nil && maxDimension > 0 {
+		mediaLimits.MaxDimension = maxDimension
+	}
+	if maxMegapixels, err := strconv.Atoi(configuration.Get("MEDIA_MAX_MEGAPIXELS")); err == nil && maxMegapixels > 0 {
+		mediaLimits.MaxPixels = maxMegapixels * 1_000_000
+	}
 
 	// Setup Service
 	authService := services.NewAuthService(&authRepository, &userRepository)
@@ -81,11 +146,26 @@ func main() {
 	addressService := services.NewAddressService(&addressRepository)
 	regionService := services.NewRegionService()
 	categoryService := services.NewCategoryService(&categoryRepository)
-	storeService := services.NewStoreService(&storeRepository)
-	productService := services.NewProductService(&productRepository, &storeRepository, &productPictureRepository, &categoryRepository) // Updated this line
+	mediaService := services.NewMediaService(&mediaRepository, blobStorage, mediaLimits)
+	storeService := services.NewStoreService(&storeRepository, &storeMemberRepository, &mediaService)
+	notificationService := services.NewNotificationService(&notificationRepository, &storeRepository, notifier.New())
+	stockAlertService := services.NewStockAlertService(&productRepository, &notificationService)
+	suggestService := services.NewSuggestService(&searchLogRepository, &productRepository, &categoryRepository)
+	wishlistService := services.NewWishlistService(&wishlistRepository, &productRepository, &notificationService)
+	storeFollowerService := services.NewStoreFollowerService(&storeFollowerRepository, &storeRepository)
+	productService := services.NewProductService(&productRepository, &storeRepository, &storeMemberRepository, &productPictureRepository, &categoryRepository, &stockAlertService, searchIndex, &suggestService, &wishlistService, &mediaService)
+	productReviewService := services.NewProductReviewService(&productRepository, &notificationService, &suggestService)
+	productImportService := services.NewProductImportService(&productImportRepository, &productRepository, &storeRepository, &storeMemberRepository, &categoryRepository, &productService, &mediaService)
+	productTrashService := services.NewProductTrashService(&productRepository, &storeRepository, &storeMemberRepository, searchIndex, &suggestService, &mediaService, time.Duration(trashRetentionDays)*24*time.Hour)
+	reviewService := services.NewReviewService(&reviewRepository, &productRepository, &storeRepository, &storeMemberRepository, &notificationService, &mediaService)
+	recommendationService := services.NewRecommendationService(&recommendationRepository, &productRepository)
 	transactionService := services.NewTransactionService(&transactionRepository, &productRepository, &addressRepository)
 	productLogService := services.NewProductLogService(&productLogRepository)
-	fotoProdukService := services.NewFotoProdukService(&fotoProdukRepository, &productRepository)
+	productPictureService := services.NewProductPictureService(&productPictureRepository, &productRepository, &storeRepository, &storeMemberRepository, &mediaService, &suggestService)
+	storeMemberService := services.NewStoreMemberService(&storeMemberRepository, &storeRepository, &userRepository, mailer.New(), configuration.Get("STORE_INVITE_URL"))
+	storeOrderService := services.NewStoreOrderService(&storeOrderRepository, &storeRepository, &storeMemberRepository)
+	storeAnalyticsService := services.NewStoreAnalyticsService(&storeAnalyticsRepository, &storeRepository, &storeMemberRepository)
+	adminReportService := services.NewAdminReportService(&adminReportRepository)
 
 	// Setup Handler
 	authHandler := handlers.NewAuthHandler(&authService)
@@ -93,11 +173,74 @@ func main() {
 	addressHandler := handlers.NewAddressHandler(&addressService)
 	regionHandler := handlers.NewRegionHandler(&regionService)
 	categoryHandler := handlers.NewCategoryHandler(&categoryService)
-	storeHandler := handlers.NewStoreHandler(&storeService)
-	productHandler := handlers.NewProductHandler(&productService)
+	storeHandler := handlers.NewStoreHandler(&storeService, &mediaService)
+	productHandler := handlers.NewProductHandler(&productService, &mediaService)
+	productImportHandler := handlers.NewProductImportHandler(&productImportService)
+	productReviewHandler := handlers.NewProductReviewHandler(&productReviewService)
+	productTrashHandler := handlers.NewProductTrashHandler(&productTrashService)
+	reviewHandler := handlers.NewReviewHandler(&reviewService, &mediaService)
+	wishlistHandler := handlers.NewWishlistHandler(&wishlistService)
+	storeFollowerHandler := handlers.NewStoreFollowerHandler(&storeFollowerService)
 	transactionHandler := handlers.NewTransactionHandler(&transactionService)
 	productLogHandler := handlers.NewProductLogHandler(&productLogService)
-	fotoProdukHandler := handlers.NewFotoProdukHandler(&fotoProdukService)
+	productPictureHandler := handlers.NewProductPictureHandler(&productPictureService, &mediaService)
+	notificationHandler := handlers.NewNotificationHandler(&notificationService)
+	catalogHandler := handlers.NewCatalogHandler(&productService, &suggestService, &recommendationService, &storeService, &storeAnalyticsService)
+	recommendationHandler := handlers.NewRecommendationHandler(&recommendationService)
+	mediaHandler := handlers.NewMediaHandler(&mediaService)
+	storeMemberHandler := handlers.NewStoreMemberHandler(&storeMemberService)
+	storeOrderHandler := handlers.NewStoreOrderHandler(&storeOrderService)
+	storeAnalyticsHandler := handlers.NewStoreAnalyticsHandler(&storeAnalyticsService)
+	adminReportHandler := handlers.NewAdminReportHandler(&adminReportService)
+
+	if err := productService.Reindex(); err != nil {
+		log.Printf("Failed to build search index: %v", err)
+	}
+	if err := suggestService.Rebuild(); err != nil {
+		log.Printf("Failed to build suggestions: %v", err)
+	}
+	if err := productImportService.Recover(); err != nil {
+		log.Printf("Failed to recover product imports: %v", err)
+	}
+
+	// Setup Background Jobs
+	digestHour, err := strconv.Atoi(configuration.Get("LOW_STOCK_DIGEST_HOUR"))
+	if err != nil {
+		digestHour = 8
+	}
+
+	rollupInterval, err := strconv.Atoi(configuration.Get("ANALYTICS_ROLLUP_INTERVAL_MINUTES"))
+	if err != nil || rollupInterval <= 0 {
+		rollupInterval = 10
+	}
+
+	scheduler := jobs.NewScheduler()
+	scheduler.Register(jobs.Job{
+		Name: "low-stock-digest",
+		Next: jobs.DailyAt(digestHour, 0),
+		Run:  stockAlertService.SendDailyDigest,
+	})
+	scheduler.Register(jobs.Job{
+		Name: "suggest-rebuild",
+		Next: jobs.Every(time.Hour),
+		Run:  suggestService.Rebuild,
+	})
+	scheduler.Register(jobs.Job{
+		Name: "product-trash-purge",
+		Next: jobs.DailyAt(3, 0),
+		Run:  productTrashService.Purge,
+	})
+	scheduler.Register(jobs.Job{
+		Name: "co-purchase-rebuild",
+		Next: jobs.Every(6 * time.Hour),
+		Run:  recommendationService.RebuildCoPurchases,
+	})
+	scheduler.Register(jobs.Job{
+		Name: "store-analytics-rollup",
+		Next: jobs.Every(time.Duration(rollupInterval) * time.Minute),
+		Run:  storeAnalyticsService.Rollup,
+	})
+	scheduler.Start()
 
 	// Setup Fiber
 	app := fiber.New(configs.NewFiberConfig())
@@ -125,16 +268,30 @@ func main() {
 	})
 
 	// Setup Routing
+	mediaHandler.Route(app)
 	authHandler.Route(app)
 	userHandler.Route(app)
 	addressHandler.Route(app)
 	regionHandler.Route(app)
 	categoryHandler.Route(app)
 	storeHandler.Route(app)
+	productImportHandler.Route(app) // before productHandler, see Route
+	productTrashHandler.Route(app)  // before productHandler, see Route
 	productHandler.Route(app)
+	productReviewHandler.Route(app)
 	transactionHandler.Route(app)
 	productLogHandler.Route(app)
-	fotoProdukHandler.Route(app)
+	productPictureHandler.Route(app)
+	notificationHandler.Route(app)
+	catalogHandler.Route(app)
+	reviewHandler.Route(app)
+	wishlistHandler.Route(app)
+	storeFollowerHandler.Route(app)
+	recommendationHandler.Route(app)
+	storeMemberHandler.Route(app)
+	storeOrderHandler.Route(app)
+	storeAnalyticsHandler.Route(app)
+	adminReportHandler.Route(app)
 
 	//Not Found in Last
 	app.Use(func(c *fiber.Ctx) error {
@@ -154,6 +311,7 @@ func main() {
 		<-chanServer
 
 		log.Printf("Server is shutting down in the %s.", host)
+		scheduler.Stop()
 		err := app.Shutdown()
 		if err != nil {
 			log.Printf("Error in shutting down the server: %v.", err)
@@ -0,0 +1,126 @@
+package models
+
+import "time"
+
+// Admin reports
+const (
+	AdminReportGMV           = "gmv"
+	AdminReportOrders        = "orders"
+	AdminReportSignups       = "signups"
+	AdminReportTopCategories = "top-categories"
+	AdminReportTopStores     = "top-stores"
+	AdminReportRefunds       = "refunds"
+)
+
+const (
+	// DefaultAdminReportLimit and MaxAdminReportLimit bound the top lists
+	DefaultAdminReportLimit = 10
+	MaxAdminReportLimit     = 100
+)
+
+// Request, the range and bucket work as in StoreAnalyticsRequest
+type AdminReportRequest struct {
+	From   string // YYYY-MM-DD, inclusive
+	To     string // YYYY-MM-DD, inclusive
+	Bucket string
+	Limit  int
+}
+
+// AdminDailySales is one day of the platform's sales, read from detail_trx.
+// A store order is one store's part of a transaction.
+type AdminDailySales struct {
+	Date           string
+	GMV            int64 // value of the store orders that were not cancelled
+	Transactions   int
+	Orders         int // store orders that were not cancelled
+	UnitsSold      int
+	Refunded       int
+	RefundedAmount int64
+}
+
+type AdminDailyCount struct {
+	Date  string
+	Count int
+}
+
+// Response
+type AdminReportRange struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Bucket      string    `json:"bucket,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// AdminGMVReport counts every store order that was not cancelled, refunded
+// ones included
+type AdminGMVReport struct {
+	AdminReportRange
+	Total  AdminGMVPoint   `json:"total"`
+	Series []AdminGMVPoint `json:"series"`
+}
+
+type AdminGMVPoint struct {
+	Date         string  `json:"date,omitempty"`
+	GMV          int64   `json:"gmv"`
+	Transactions int     `json:"transactions"`
+	Orders       int     `json:"orders"`
+	UnitsSold    int     `json:"units_sold"`
+	AOV          float64 `json:"aov"` // GMV per transaction
+}
+
+type AdminOrderStatusReport struct {
+	AdminReportRange
+	Total    int                    `json:"total"`
+	Statuses []AdminOrderStatusStat `json:"statuses"`
+}
+
+type AdminOrderStatusStat struct {
+	Status string  `json:"status"`
+	Orders int     `json:"orders"`
+	Amount int64   `json:"amount"`
+	Share  float64 `json:"share"`
+}
+
+// AdminSignupReport counts the users and stores made in the range, deleted
+// ones included
+type AdminSignupReport struct {
+	AdminReportRange
+	Total  AdminSignupPoint   `json:"total"`
+	Series []AdminSignupPoint `json:"series"`
+}
+
+type AdminSignupPoint struct {
+	Date   string `json:"date,omitempty"`
+	Users  int    `json:"users"`
+	Stores int    `json:"stores"`
+}
+
+// AdminTopReport ranks categories or stores by GMV
+type AdminTopReport struct {
+	AdminReportRange
+	Items []AdminTopEntry `json:"items"`
+}
+
+type AdminTopEntry struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	GMV       int64  `json:"gmv"`
+	Orders    int    `json:"orders"`
+	UnitsSold int    `json:"units_sold"`
+}
+
+// AdminRefundReport relates refunded store orders to those that were not
+// cancelled, as only those can be refunded
+type AdminRefundReport struct {
+	AdminReportRange
+	Total  AdminRefundPoint   `json:"total"`
+	Series []AdminRefundPoint `json:"series"`
+}
+
+type AdminRefundPoint struct {
+	Date           string  `json:"date,omitempty"`
+	Orders         int     `json:"orders"`
+	Refunded       int     `json:"refunded"`
+	RefundedAmount int64   `json:"refunded_amount"`
+	RefundRate     float64 `json:"refund_rate"`
+}
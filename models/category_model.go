@@ -2,15 +2,59 @@ package models
 
 import "time"
 
+// Attribute types
+const (
+	AttributeText   = "text"
+	AttributeNumber = "number"
+	AttributeOption = "option"
+)
+
+var AttributeTypes = []string{AttributeText, AttributeNumber, AttributeOption}
+
 // Request
 type CategoryRequest struct {
-	NamaCategory string `json:"nama_category" binding:"required"`
+	NamaCategory string                     `json:"nama_category" binding:"required"`
+	ParentID     *uint                      `json:"parent_id"`  // nil keeps the current parent on edit, 0 makes it a root
+	Attributes   []CategoryAttributeRequest `json:"attributes"` // nil keeps the current attributes on edit
+}
+
+type CategoryAttributeRequest struct {
+	Kode    string   `json:"kode"`
+	Nama    string   `json:"nama"`
+	Tipe    string   `json:"tipe"`
+	Pilihan []string `json:"pilihan"`
+	Wajib   bool     `json:"wajib"`
 }
 
 // Response
 type CategoryResponse struct {
-	ID           uint       `json:"id"`
-	NamaCategory string     `json:"nama_category"`
-	CreatedAt    *time.Time `json:"created_at"`
-	UpdatedAt    *time.Time `json:"updated_at"`
+	ID           uint                        `json:"id"`
+	NamaCategory string                      `json:"nama_category"`
+	Slug         string                      `json:"slug"`
+	ParentID     *uint                       `json:"parent_id"`
+	Depth        int                         `json:"depth"`
+	Breadcrumbs  []CategoryBreadcrumb        `json:"breadcrumbs,omitempty"`
+	Attributes   []CategoryAttributeResponse `json:"attributes,omitempty"`
+	JumlahProduk *int64                      `json:"jumlah_produk,omitempty"` // products in it and its subcategories
+	Children     []CategoryResponse          `json:"children,omitempty"`
+	CreatedAt    *time.Time                  `json:"created_at"`
+	UpdatedAt    *time.Time                  `json:"updated_at"`
+}
+
+// CategoryBreadcrumb is one step on the way from the root to a category
+type CategoryBreadcrumb struct {
+	ID           uint   `json:"id"`
+	NamaCategory string `json:"nama_category"`
+	Slug         string `json:"slug"`
+}
+
+type CategoryAttributeResponse struct {
+	ID         uint     `json:"id"`
+	CategoryID uint     `json:"category_id"` // the category defining it, an ancestor when inherited
+	Kode       string   `json:"kode"`
+	Nama       string   `json:"nama"`
+	Tipe       string   `json:"tipe"`
+	Pilihan    []string `json:"pilihan"`
+	Wajib      bool     `json:"wajib"`
+	Urutan     int      `json:"urutan"`
 }
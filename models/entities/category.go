@@ -10,8 +10,13 @@ type Category struct {
 	gorm.Model
 	ID           uint   `gorm:"primaryKey"`
 	NamaCategory string `gorm:"size:255;not null"`
+	Slug         string `gorm:"size:255;not null;default:''"`
+	IDParent     *uint  `gorm:"index;default:null"`
+	Path         string `gorm:"size:700;not null;default:'';index"` // ancestor ids including itself, e.g. /1/5/12/
+	Depth        int    `gorm:"not null;default:0"`
 	CreatedAt    *time.Time
 	UpdatedAt    *time.Time
+	Attributes   []CategoryAttribute `gorm:"foreignKey:IDCategory;references:ID"`
 }
 
 func (Category) TableName() string {
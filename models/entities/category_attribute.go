@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// CategoryAttribute is one field products of a category, or of any of its
+// subcategories, have to fill in.
+type CategoryAttribute struct {
+	ID         uint   `gorm:"primaryKey"`
+	IDCategory uint   `gorm:"not null;uniqueIndex:idx_atribut_category_kode,priority:1"`
+	Kode       string `gorm:"size:100;not null;uniqueIndex:idx_atribut_category_kode,priority:2"`
+	Nama       string `gorm:"size:255;not null"`
+	Tipe       string `gorm:"size:20;not null"`
+	Pilihan    string `gorm:"type:text"` // JSON list of allowed values for option attributes
+	Wajib      bool   `gorm:"not null;default:false"`
+	Urutan     int    `gorm:"not null;default:0"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
+
+func (CategoryAttribute) TableName() string {
+	return "atribut_category"
+}
@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"mini-project-evermos/database/migrations"
 	"mini-project-evermos/models/entities"
 
 	"gorm.io/gorm"
@@ -8,7 +9,7 @@ import (
 
 // Export AutoMigrate function
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&entities.Address{},
 		&entities.User{},
 		&entities.Store{},
@@ -18,5 +19,54 @@ func AutoMigrate(db *gorm.DB) error {
 		&entities.Trx{},
 		&entities.TrxDetail{},
 		&entities.ProductLog{},
+		&entities.Notification{},
+		&entities.ProductSlugHistory{},
+		&entities.ProductSearchDocument{},
+		&entities.SearchLog{},
+		&entities.ProductImport{},
+		&entities.CategoryAttribute{},
+		&entities.ProductAttribute{},
+		&entities.ProductModeration{},
+		&entities.Review{},
+		&entities.ReviewPhoto{},
+		&entities.ReviewFlag{},
+		&entities.Wishlist{},
+		&entities.WishlistItem{},
+		&entities.StoreFollower{},
+		&entities.StoreHour{},
+		&entities.StoreMember{},
+		&entities.StoreInvitation{},
+		&entities.ProductViewCount{},
+		&entities.StoreDailyStat{},
+		&entities.ProductDailyStat{},
+		&entities.BuyerDailyStat{},
+		&entities.AnalyticsRollup{},
+		&entities.ProductView{},
+		&entities.ProductRelation{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrations.UniqueProductSlug(db); err != nil {
+		return err
+	}
+
+	if err := migrations.CategoryHierarchy(db); err != nil {
+		return err
+	}
+
+	if err := migrations.UnifyProductPhotos(db); err != nil {
+		return err
+	}
+
+	if err := migrations.StoreSlugs(db); err != nil {
+		return err
+	}
+
+	if err := migrations.OneStorePerUser(db); err != nil {
+		return err
+	}
+
+	return migrations.StoreOwners(db)
 }
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Notification struct {
+	gorm.Model
+	ID         uint       `gorm:"primaryKey"`
+	IDToko     *uint      `gorm:"default:null;index"` // recipient store
+	IDUser     *uint      `gorm:"default:null;index"` // recipient user, when not addressed to a store
+	IDProduk   *uint      `gorm:"default:null"`
+	Tipe       string     `gorm:"size:50;not null"`
+	Judul      string     `gorm:"size:255;not null"`
+	Pesan      string     `gorm:"type:text;not null"`
+	DibacaPada *time.Time `gorm:"default:null"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+	Store      Store `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (Notification) TableName() string {
+	return "notifikasi"
+}
@@ -11,17 +11,25 @@ type Product struct {
 	ID             uint    `gorm:"primaryKey"`
 	NamaProduk     string  `gorm:"size:255;not null"`
 	Slug           string  `gorm:"size:255;not null"`
+	SKU            *string `gorm:"column:sku;size:100;default:null;uniqueIndex:idx_produk_toko_sku,priority:2"` // unique per store
 	HargaReseller  string  `gorm:"size:255;not null"`
 	HargaKonsumen  string  `gorm:"size:255;not null"`
 	Stok           int     `gorm:"not null"`
+	StokMinimum    int     `gorm:"not null;default:0"` // low-stock threshold, 0 disables alerts
 	Deskripsi      *string `gorm:"type:text;default:null"`
-	IDToko         uint    `gorm:"not null"`
+	IDToko         uint    `gorm:"not null;uniqueIndex:idx_produk_toko_sku,priority:1"`
 	IDCategory     uint    `gorm:"not null"`
+	Status         string  `gorm:"size:20;not null;default:'published';index"` // rows from before moderation stay visible
+	AlasanTolak    *string `gorm:"type:text;default:null"`                     // reason given with the last rejection
+	DitinjauPada   *time.Time
+	JumlahUlasan   int `gorm:"not null;default:0"` // visible reviews
+	TotalRating    int `gorm:"not null;default:0"` // sum of their stars
 	CreatedAt      *time.Time
 	UpdatedAt      *time.Time
-	Store          Store            `gorm:"foreignKey:IDToko;references:ID"`
-	Category       Category         `gorm:"foreignKey:IDCategory;references:ID"`
-	ProductPicture []ProductPicture `gorm:"foreignKey:IDProduk;references:ID"`
+	Store          Store              `gorm:"foreignKey:IDToko;references:ID"`
+	Category       Category           `gorm:"foreignKey:IDCategory;references:ID"`
+	ProductPicture []ProductPicture   `gorm:"foreignKey:IDProduk;references:ID"`
+	Attributes     []ProductAttribute `gorm:"foreignKey:IDProduk;references:ID"`
 }
 
 func (Product) TableName() string {
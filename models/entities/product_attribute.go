@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// ProductAttribute holds a product's value for one category attribute.
+type ProductAttribute struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDProduk  uint   `gorm:"not null;uniqueIndex:idx_atribut_produk_kode,priority:1"`
+	Kode      string `gorm:"size:100;not null;uniqueIndex:idx_atribut_produk_kode,priority:2"`
+	Nilai     string `gorm:"size:255;not null"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+func (ProductAttribute) TableName() string {
+	return "atribut_produk"
+}
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// ProductImport tracks one bulk product import job.
+type ProductImport struct {
+	ID          uint       `gorm:"primaryKey"`
+	IDToko      uint       `gorm:"not null;index"`
+	NamaFile    string     `gorm:"size:255;not null"`
+	Format      string     `gorm:"size:10;not null"`
+	DryRun      bool       `gorm:"not null;default:false"`
+	Status      string     `gorm:"size:20;not null"`
+	TotalBaris  int        `gorm:"not null;default:0"`
+	Dibuat      int        `gorm:"not null;default:0"`
+	Diperbarui  int        `gorm:"not null;default:0"`
+	Gagal       int        `gorm:"not null;default:0"`
+	Kesalahan   string     `gorm:"type:text"` // JSON encoded row errors
+	Pesan       *string    `gorm:"type:text;default:null"`
+	SelesaiPada *time.Time `gorm:"default:null"`
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+	Store       Store `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (ProductImport) TableName() string {
+	return "impor_produk"
+}
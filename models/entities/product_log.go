@@ -9,7 +9,7 @@ import (
 type ProductLog struct {
 	gorm.Model
 	ID            uint    `gorm:"primaryKey;column:id"`
-	IDProduk      uint    `gorm:"column:id_produk;not null"`
+	IDProduk      uint    `gorm:"column:id_produk;not null;index"`
 	NamaProduk    string  `gorm:"column:nama_produk;size:255;not null"`
 	Slug          string  `gorm:"column:slug;size:255;not null"`
 	HargaReseller string  `gorm:"column:harga_reseller;size:255;not null"`
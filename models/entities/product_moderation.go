@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// ProductModeration records every status change of a product, who made it
+// and why.
+type ProductModeration struct {
+	ID        uint    `gorm:"primaryKey"`
+	IDProduk  uint    `gorm:"not null;index"`
+	Status    string  `gorm:"size:20;not null"`
+	Alasan    *string `gorm:"type:text;default:null"`
+	IDUser    *uint   `gorm:"default:null"` // nil for changes made along with a product create or edit
+	CreatedAt *time.Time
+	Product   Product `gorm:"foreignKey:IDProduk;references:ID"`
+}
+
+func (ProductModeration) TableName() string {
+	return "moderasi_produk"
+}
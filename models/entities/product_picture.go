@@ -9,8 +9,11 @@ import (
 type ProductPicture struct {
 	gorm.Model
 	ID        uint   `gorm:"primaryKey"`
-	IDProduk  uint   `gorm:"not null"`
+	IDProduk  uint   `gorm:"not null;index"`
 	Url       string `gorm:"size:255;not null"`
+	Posisi    int    `gorm:"not null;default:0"`     // display order within the product
+	Utama     bool   `gorm:"not null;default:false"` // the cover photo, one per product
+	AltText   string `gorm:"size:255;not null;default:''"`
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
 }
@@ -0,0 +1,13 @@
+package entities
+
+// ProductRelation scores how often two products were bought together. Rows
+// are rebuilt by the co-purchase job.
+type ProductRelation struct {
+	IDProduk  uint  `gorm:"primaryKey;autoIncrement:false"`
+	IDTerkait uint  `gorm:"primaryKey;autoIncrement:false"`
+	Skor      int64 `gorm:"not null"` // transactions containing both
+}
+
+func (ProductRelation) TableName() string {
+	return "produk_terkait"
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// ProductSearchDocument holds the analyzed (stemmed, stop-word free) text of
+// a product for the MySQL FULLTEXT search index.
+type ProductSearchDocument struct {
+	IDProduk  uint   `gorm:"primaryKey;autoIncrement:false"`
+	Konten    string `gorm:"type:text;not null;index:idx_indeks_pencarian_konten,class:FULLTEXT"`
+	UpdatedAt *time.Time
+}
+
+func (ProductSearchDocument) TableName() string {
+	return "indeks_pencarian_produk"
+}
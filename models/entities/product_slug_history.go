@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// ProductSlugHistory keeps slugs a product used before it was renamed so old
+// links can be redirected to the current slug.
+type ProductSlugHistory struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDProduk  uint   `gorm:"not null;index"`
+	Slug      string `gorm:"size:255;not null;uniqueIndex"`
+	CreatedAt *time.Time
+	Product   Product `gorm:"foreignKey:IDProduk;references:ID"`
+}
+
+func (ProductSlugHistory) TableName() string {
+	return "riwayat_slug_produk"
+}
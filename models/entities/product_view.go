@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// ProductView is the last time a user looked at a product
+type ProductView struct {
+	ID          uint      `gorm:"primaryKey"`
+	IDUser      uint      `gorm:"not null;uniqueIndex:idx_riwayat_lihat_user_produk,priority:1;index:idx_riwayat_lihat_user_waktu,priority:1"`
+	IDProduk    uint      `gorm:"not null;uniqueIndex:idx_riwayat_lihat_user_produk,priority:2"`
+	DilihatPada time.Time `gorm:"not null;index:idx_riwayat_lihat_user_waktu,priority:2"`
+}
+
+func (ProductView) TableName() string {
+	return "riwayat_lihat_produk"
+}
@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review is a buyer's rating of one purchased line item
+type Review struct {
+	gorm.Model
+	ID            uint       `gorm:"primaryKey"`
+	IDDetailTrx   uint       `gorm:"not null;uniqueIndex"` // one review per line item
+	IDProduk      uint       `gorm:"not null;index"`
+	IDToko        uint       `gorm:"not null;index"`
+	IDUser        uint       `gorm:"not null;index"`
+	Rating        int        `gorm:"not null"` // 1 to 5 stars
+	Ulasan        *string    `gorm:"type:text;default:null"`
+	Balasan       *string    `gorm:"type:text;default:null"` // the seller's reply
+	DibalasPada   *time.Time `gorm:"default:null"`
+	Status        string     `gorm:"size:20;not null;default:'visible';index"`
+	JumlahLaporan int        `gorm:"not null;default:0"`
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+	User          User          `gorm:"foreignKey:IDUser;references:ID"`
+	Photos        []ReviewPhoto `gorm:"foreignKey:IDUlasan;references:ID"`
+}
+
+func (Review) TableName() string {
+	return "ulasan"
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// ReviewFlag is a user's report of an inappropriate review
+type ReviewFlag struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDUlasan  uint   `gorm:"not null;uniqueIndex:idx_laporan_ulasan_user,priority:1"`
+	IDUser    uint   `gorm:"not null;uniqueIndex:idx_laporan_ulasan_user,priority:2"`
+	Alasan    string `gorm:"type:text;not null"`
+	CreatedAt *time.Time
+}
+
+func (ReviewFlag) TableName() string {
+	return "laporan_ulasan"
+}
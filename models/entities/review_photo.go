@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+type ReviewPhoto struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDUlasan  uint   `gorm:"not null;index"`
+	Url       string `gorm:"size:255;not null"`
+	CreatedAt *time.Time
+}
+
+func (ReviewPhoto) TableName() string {
+	return "foto_ulasan"
+}
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// SearchLog records catalog searches so popular queries can be suggested.
+type SearchLog struct {
+	ID          uint       `gorm:"primaryKey"`
+	KataKunci   string     `gorm:"size:255;not null;index"`
+	JumlahHasil int64      `gorm:"not null;default:0"`
+	Klien       string     `gorm:"size:64;not null;default:''"` // sha256 hex of the searcher's IP
+	CreatedAt   *time.Time `gorm:"index"`
+}
+
+func (SearchLog) TableName() string {
+	return "log_pencarian"
+}
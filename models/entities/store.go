@@ -8,12 +8,28 @@ import (
 
 type Store struct {
 	gorm.Model
-	ID        uint    `gorm:"primaryKey"`
-	IDUser    uint    `gorm:"not null"`
-	NamaToko  *string `gorm:"size:255;default:null"`
-	UrlFoto   *string `gorm:"size:255;default:null"`
-	CreatedAt *time.Time
-	UpdatedAt *time.Time
+	ID             uint        `gorm:"primaryKey"`
+	IDUser         uint        `gorm:"not null"` // one active store per user, see migrations.OneStorePerUser
+	NamaToko       *string     `gorm:"size:255;default:null"`
+	UrlFoto        *string     `gorm:"size:255;default:null"`
+	JumlahUlasan   int         `gorm:"not null;default:0"` // visible reviews of the store's products
+	TotalRating    int         `gorm:"not null;default:0"` // sum of their stars
+	JumlahPengikut int         `gorm:"not null;default:0"`
+	Slug           string      `gorm:"size:255;not null;default:''"`
+	Deskripsi      string      `gorm:"size:2000;not null;default:''"`
+	UrlBanner      *string     `gorm:"size:255;default:null"`
+	IDProvinsi     string      `gorm:"size:255;not null;default:''"` // where orders ship from, region ids like the user's
+	IDKota         string      `gorm:"size:255;not null;default:''"`
+	Telepon        string      `gorm:"size:50;not null;default:''"`
+	Whatsapp       string      `gorm:"size:50;not null;default:''"`
+	Email          string      `gorm:"size:255;not null;default:''"`
+	Instagram      string      `gorm:"size:100;not null;default:''"`
+	Libur          bool        `gorm:"not null;default:false"` // vacation mode, the store takes no orders
+	LiburSampai    *time.Time  // vacation mode ends on its own at this time, if set
+	PesanLibur     string      `gorm:"size:255;not null;default:''"`
+	JamOperasional []StoreHour `gorm:"foreignKey:IDToko;references:ID"`
+	CreatedAt      *time.Time
+	UpdatedAt      *time.Time
 }
 
 func (Store) TableName() string {
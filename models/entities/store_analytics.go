@@ -0,0 +1,75 @@
+package entities
+
+import "time"
+
+// ProductViewCount counts the product page views of one day, signed in or
+// not. Unlike ProductView it is kept for analytics.
+type ProductViewCount struct {
+	ID        uint       `gorm:"primaryKey"`
+	IDProduk  uint       `gorm:"not null;uniqueIndex:idx_tayangan_produk_tanggal,priority:1"`
+	Tanggal   time.Time  `gorm:"type:date;not null;uniqueIndex:idx_tayangan_produk_tanggal,priority:2;index"`
+	Jumlah    int        `gorm:"not null;default:0"`
+	UpdatedAt *time.Time `gorm:"index"`
+}
+
+func (ProductViewCount) TableName() string {
+	return "tayangan_produk_harian"
+}
+
+// StoreDailyStat is the rollup of a store's sales and views on one day.
+// Cancelled and refunded items are left out.
+type StoreDailyStat struct {
+	ID         uint      `gorm:"primaryKey"`
+	IDToko     uint      `gorm:"not null;uniqueIndex:idx_statistik_toko_tanggal,priority:1"`
+	Tanggal    time.Time `gorm:"type:date;not null;uniqueIndex:idx_statistik_toko_tanggal,priority:2;index"`
+	Pendapatan int64     `gorm:"not null;default:0"`
+	Pesanan    int       `gorm:"not null;default:0"` // transactions with at least one item of the store
+	Unit       int       `gorm:"not null;default:0"`
+	Pembeli    int       `gorm:"not null;default:0"`
+	Dilihat    int       `gorm:"not null;default:0"`
+}
+
+func (StoreDailyStat) TableName() string {
+	return "statistik_toko_harian"
+}
+
+// ProductDailyStat is StoreDailyStat for one product. Sales count for the
+// store that sold the product, views for the store it is in now.
+type ProductDailyStat struct {
+	ID         uint      `gorm:"primaryKey"`
+	IDToko     uint      `gorm:"not null;uniqueIndex:idx_statistik_produk_tanggal,priority:1;index:idx_statistik_produk_toko_tanggal,priority:1"`
+	IDProduk   uint      `gorm:"not null;uniqueIndex:idx_statistik_produk_tanggal,priority:2"`
+	Tanggal    time.Time `gorm:"type:date;not null;uniqueIndex:idx_statistik_produk_tanggal,priority:3;index:idx_statistik_produk_toko_tanggal,priority:2;index"`
+	Pendapatan int64     `gorm:"not null;default:0"`
+	Pesanan    int       `gorm:"not null;default:0"`
+	Unit       int       `gorm:"not null;default:0"`
+	Dilihat    int       `gorm:"not null;default:0"`
+}
+
+func (ProductDailyStat) TableName() string {
+	return "statistik_produk_harian"
+}
+
+// BuyerDailyStat counts the transactions a user made at a store on one day,
+// which is what repeat buyers are found from.
+type BuyerDailyStat struct {
+	ID      uint      `gorm:"primaryKey"`
+	IDToko  uint      `gorm:"not null;uniqueIndex:idx_statistik_pembeli_tanggal,priority:1"`
+	IDUser  uint      `gorm:"not null;uniqueIndex:idx_statistik_pembeli_tanggal,priority:2"`
+	Tanggal time.Time `gorm:"type:date;not null;uniqueIndex:idx_statistik_pembeli_tanggal,priority:3;index"`
+	Pesanan int       `gorm:"not null;default:0"`
+}
+
+func (BuyerDailyStat) TableName() string {
+	return "statistik_pembeli_harian"
+}
+
+// AnalyticsRollup remembers up to when the daily rollups are current
+type AnalyticsRollup struct {
+	ID             uint `gorm:"primaryKey"`
+	DiprosesSampai time.Time
+}
+
+func (AnalyticsRollup) TableName() string {
+	return "rollup_analitik"
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type StoreFollower struct {
+	ID        uint `gorm:"primaryKey"`
+	IDToko    uint `gorm:"not null;uniqueIndex:idx_pengikut_toko_user,priority:1"`
+	IDUser    uint `gorm:"not null;uniqueIndex:idx_pengikut_toko_user,priority:2;index"`
+	CreatedAt *time.Time
+	Store     Store `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (StoreFollower) TableName() string {
+	return "pengikut_toko"
+}
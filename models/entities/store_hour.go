@@ -0,0 +1,15 @@
+package entities
+
+// StoreHour is when a store is open on one day of the week. Days without a
+// row are closed.
+type StoreHour struct {
+	ID     uint   `gorm:"primaryKey"`
+	IDToko uint   `gorm:"not null;uniqueIndex:idx_jam_toko_hari,priority:1"`
+	Hari   int    `gorm:"not null;uniqueIndex:idx_jam_toko_hari,priority:2"` // 0 is Sunday, as time.Weekday
+	Buka   string `gorm:"size:5;not null"`                                   // HH:MM
+	Tutup  string `gorm:"size:5;not null"`
+}
+
+func (StoreHour) TableName() string {
+	return "jam_operasional_toko"
+}
@@ -0,0 +1,41 @@
+package entities
+
+import "time"
+
+// StoreMember gives a user access to a store. The store's owner is a member
+// with role owner; managers and staff are invited.
+type StoreMember struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDToko    uint   `gorm:"not null;uniqueIndex:idx_anggota_toko_user,priority:1"`
+	IDUser    uint   `gorm:"not null;uniqueIndex:idx_anggota_toko_user,priority:2;index"`
+	Peran     string `gorm:"size:20;not null"`             // owner, manager or staff
+	Izin      string `gorm:"size:255;not null;default:''"` // comma separated, staff only
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	Store     Store `gorm:"foreignKey:IDToko;references:ID"`
+	User      User  `gorm:"foreignKey:IDUser;references:ID"`
+}
+
+func (StoreMember) TableName() string {
+	return "anggota_toko"
+}
+
+// StoreInvitation is an invite to join a store, sent by email. Only a hash of
+// the token is kept.
+type StoreInvitation struct {
+	ID              uint   `gorm:"primaryKey"`
+	IDToko          uint   `gorm:"not null;index"`
+	Email           string `gorm:"size:255;not null"`
+	Peran           string `gorm:"size:20;not null"`
+	Izin            string `gorm:"size:255;not null;default:''"`
+	Token           string `gorm:"size:64;not null;uniqueIndex"` // sha256 hex
+	DiundangOleh    uint   `gorm:"not null"`
+	KedaluwarsaPada time.Time
+	DiterimaPada    *time.Time
+	CreatedAt       *time.Time
+	Store           Store `gorm:"foreignKey:IDToko;references:ID"`
+}
+
+func (StoreInvitation) TableName() string {
+	return "undangan_toko"
+}
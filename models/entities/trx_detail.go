@@ -13,6 +13,7 @@ type TrxDetail struct {
 	IDToko      uint       `gorm:"column:id_toko"`
 	Kuantitas   int        `gorm:"column:kuantitas"`
 	HargaTotal  int        `gorm:"column:harga_total"`
+	Status      string     `gorm:"column:status;size:20;not null;default:'pending';index"`
 	Trx         Trx        `gorm:"foreignKey:IDTrx"`
 	ProductLog  ProductLog `gorm:"foreignKey:IDLogProduk"`
 	Store       Store      `gorm:"foreignKey:IDToko"`
@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+type Wishlist struct {
+	ID        uint   `gorm:"primaryKey"`
+	IDUser    uint   `gorm:"not null;uniqueIndex:idx_wishlist_user_nama,priority:1"`
+	Nama      string `gorm:"size:100;not null;uniqueIndex:idx_wishlist_user_nama,priority:2"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	Items     []WishlistItem `gorm:"foreignKey:IDWishlist;references:ID"`
+}
+
+func (Wishlist) TableName() string {
+	return "wishlist"
+}
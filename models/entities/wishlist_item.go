@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type WishlistItem struct {
+	ID         uint `gorm:"primaryKey"`
+	IDWishlist uint `gorm:"not null;uniqueIndex:idx_item_wishlist_produk,priority:1"`
+	IDProduk   uint `gorm:"not null;uniqueIndex:idx_item_wishlist_produk,priority:2;index"`
+	CreatedAt  *time.Time
+	Product    Product `gorm:"foreignKey:IDProduk;references:ID"`
+}
+
+func (WishlistItem) TableName() string {
+	return "item_wishlist"
+}
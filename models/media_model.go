@@ -0,0 +1,11 @@
+package models
+
+// MediaResponse describes a processed image upload. Name is the value stored
+// on the owning record; Sizes maps each size, original included, to its URL.
+type MediaResponse struct {
+	Name     string            `json:"name"`
+	MimeType string            `json:"mime_type"`
+	Width    int               `json:"width"`
+	Height   int               `json:"height"`
+	Sizes    map[string]string `json:"sizes"`
+}
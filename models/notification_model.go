@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Response
+type NotificationResponse struct {
+	ID         uint       `json:"id"`
+	StoreID    *uint      `json:"store_id"`
+	UserID     *uint      `json:"user_id"`
+	ProductID  *uint      `json:"product_id"`
+	Tipe       string     `json:"tipe"`
+	Judul      string     `json:"judul"`
+	Pesan      string     `json:"pesan"`
+	DibacaPada *time.Time `json:"dibaca_pada"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
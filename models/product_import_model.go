@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Import job statuses
+const (
+	ImportQueued    = "queued"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// ProductImportColumns is the column schema shared by import and export,
+// see docs/product_import.md.
+var ProductImportColumns = []string{
+	"sku",
+	"slug",
+	"nama_produk",
+	"category_id",
+	"harga_reseller",
+	"harga_konsumen",
+	"stok",
+	"stok_minimum",
+	"deskripsi",
+	"photo_urls",
+	"atribut",
+}
+
+// ProductImportRequiredColumns must be present in the header row.
+var ProductImportRequiredColumns = []string{
+	"nama_produk",
+	"category_id",
+	"harga_reseller",
+	"harga_konsumen",
+	"stok",
+}
+
+// Response
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type ProductImportResponse struct {
+	ID          uint             `json:"id"`
+	Status      string           `json:"status"`
+	NamaFile    string           `json:"nama_file"`
+	Format      string           `json:"format"`
+	DryRun      bool             `json:"dry_run"`
+	TotalBaris  int              `json:"total_baris"`
+	Dibuat      int              `json:"dibuat"`
+	Diperbarui  int              `json:"diperbarui"`
+	Gagal       int              `json:"gagal"`
+	Kesalahan   []ImportRowError `json:"kesalahan"`
+	Pesan       *string          `json:"pesan"`
+	SelesaiPada *time.Time       `json:"selesai_pada"`
+	CreatedAt   *time.Time       `json:"created_at"`
+	UpdatedAt   *time.Time       `json:"updated_at"`
+}
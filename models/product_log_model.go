@@ -30,3 +30,16 @@ type ProductLogResponse struct {
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+type ProductPricePoint struct {
+	LogID         uint       `json:"log_id"`
+	HargaReseller string     `json:"harga_reseller"`
+	HargaKonsumen string     `json:"harga_konsumen"`
+	BerlakuSejak  *time.Time `json:"berlaku_sejak"` // the price applies from this moment until the next point
+}
+
+type ProductPriceHistoryResponse struct {
+	ProductID  uint                `json:"product_id"`
+	NamaProduk string              `json:"nama_produk"` // name in the latest snapshot
+	Prices     []ProductPricePoint `json:"prices"`
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"encoding/json"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/utils/media"
+	"time"
+)
+
+// ToProductResponse maps a product with its preloaded relations to the
+// response shape shared by the catalog and seller endpoints.
+func ToProductResponse(product entities.Product) ProductResponse {
+	photos := []ProductPictureResponse{}
+	for _, picture := range product.ProductPicture {
+		photos = append(photos, ToProductPictureResponse(picture))
+	}
+
+	attributes := map[string]string{}
+	for _, attribute := range product.Attributes {
+		attributes[attribute.Kode] = attribute.Nilai
+	}
+
+	return ProductResponse{
+		ID:            product.ID,
+		NamaProduk:    product.NamaProduk,
+		Slug:          product.Slug,
+		SKU:           product.SKU,
+		HargaReseller: product.HargaReseller,
+		HargaKonsumen: product.HargaKonsumen,
+		Stok:          product.Stok,
+		StokMinimum:   product.StokMinimum,
+		Deskripsi:     product.Deskripsi,
+		Store:         ToStoreResponse(product.Store),
+		Category:      ToCategoryResponse(product.Category),
+		Photos:        photos,
+		Attributes:    attributes,
+		Status:        product.Status,
+		AlasanTolak:   product.AlasanTolak,
+		DitinjauPada:  product.DitinjauPada,
+		Rating:        AverageRating(product.TotalRating, product.JumlahUlasan),
+		JumlahUlasan:  product.JumlahUlasan,
+		CreatedAt:     product.CreatedAt,
+		UpdatedAt:     product.UpdatedAt,
+	}
+}
+
+// ToCatalogProductResponse strips a product response down to what the
+// public may see
+func ToCatalogProductResponse(product ProductResponse) CatalogProductResponse {
+	return CatalogProductResponse{
+		ID:            product.ID,
+		NamaProduk:    product.NamaProduk,
+		Slug:          product.Slug,
+		HargaReseller: product.HargaReseller,
+		HargaKonsumen: product.HargaKonsumen,
+		Stok:          product.Stok,
+		Deskripsi:     product.Deskripsi,
+		Store:         product.Store,
+		Category:      product.Category,
+		Photos:        product.Photos,
+		Attributes:    product.Attributes,
+		Status:        product.Status,
+		Rating:        product.Rating,
+		JumlahUlasan:  product.JumlahUlasan,
+		CreatedAt:     product.CreatedAt,
+		UpdatedAt:     product.UpdatedAt,
+	}
+}
+
+// ToProductPictureResponse turns the stored file name into URLs
+func ToProductPictureResponse(picture entities.ProductPicture) ProductPictureResponse {
+	return ProductPictureResponse{
+		ID:        picture.ID,
+		IDProduk:  picture.IDProduk,
+		Url:       media.URL(picture.Url),
+		Sizes:     media.URLs(picture.Url),
+		Posisi:    picture.Posisi,
+		Utama:     picture.Utama,
+		AltText:   picture.AltText,
+		CreatedAt: picture.CreatedAt,
+		UpdatedAt: picture.UpdatedAt,
+	}
+}
+
+func ToStoreResponse(store entities.Store) StoreResponse {
+	var url_foto *string
+	var foto_sizes map[string]string
+	if store.UrlFoto != nil {
+		url := media.URL(*store.UrlFoto)
+		url_foto = &url
+		foto_sizes = media.URLs(*store.UrlFoto)
+	}
+
+	var url_banner *string
+	var banner_sizes map[string]string
+	if store.UrlBanner != nil {
+		url := media.URL(*store.UrlBanner)
+		url_banner = &url
+		banner_sizes = media.URLs(*store.UrlBanner)
+	}
+
+	jam_operasional := []StoreHourResponse{}
+	for _, hour := range store.JamOperasional {
+		jam_operasional = append(jam_operasional, StoreHourResponse{
+			Hari:  hour.Hari,
+			Buka:  hour.Buka,
+			Tutup: hour.Tutup,
+		})
+	}
+
+	libur := StoreOnVacation(store, time.Now())
+	var libur_sampai *time.Time
+	pesan_libur := ""
+	if libur {
+		libur_sampai = store.LiburSampai
+		pesan_libur = store.PesanLibur
+	}
+
+	return StoreResponse{
+		ID:          store.ID,
+		NamaToko:    store.NamaToko,
+		Slug:        store.Slug,
+		UrlFoto:     url_foto,
+		FotoSizes:   foto_sizes,
+		UrlBanner:   url_banner,
+		BannerSizes: banner_sizes,
+		Deskripsi:   store.Deskripsi,
+		IDProvinsi:  store.IDProvinsi,
+		IDKota:      store.IDKota,
+		Kontak: StoreContact{
+			Telepon:   store.Telepon,
+			Whatsapp:  store.Whatsapp,
+			Email:     store.Email,
+			Instagram: store.Instagram,
+		},
+		JamOperasional: jam_operasional,
+		Libur:          libur,
+		LiburSampai:    libur_sampai,
+		PesanLibur:     pesan_libur,
+		Rating:         AverageRating(store.TotalRating, store.JumlahUlasan),
+		JumlahUlasan:   store.JumlahUlasan,
+		JumlahPengikut: store.JumlahPengikut,
+		CreatedAt:      store.CreatedAt,
+		UpdatedAt:      store.UpdatedAt,
+	}
+}
+
+func ToCategoryResponse(category entities.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:           category.ID,
+		NamaCategory: category.NamaCategory,
+		Slug:         category.Slug,
+		ParentID:     category.IDParent,
+		Depth:        category.Depth,
+		CreatedAt:    category.CreatedAt,
+		UpdatedAt:    category.UpdatedAt,
+	}
+}
+
+// ToCategoryBreadcrumbs expects the ancestors ordered from the root down.
+func ToCategoryBreadcrumbs(ancestors []entities.Category) []CategoryBreadcrumb {
+	breadcrumbs := []CategoryBreadcrumb{}
+	for _, category := range ancestors {
+		breadcrumbs = append(breadcrumbs, CategoryBreadcrumb{
+			ID:           category.ID,
+			NamaCategory: category.NamaCategory,
+			Slug:         category.Slug,
+		})
+	}
+	return breadcrumbs
+}
+
+func ToCategoryAttributeResponse(attribute entities.CategoryAttribute) CategoryAttributeResponse {
+	pilihan := []string{}
+	if attribute.Pilihan != "" {
+		json.Unmarshal([]byte(attribute.Pilihan), &pilihan)
+	}
+
+	return CategoryAttributeResponse{
+		ID:         attribute.ID,
+		CategoryID: attribute.IDCategory,
+		Kode:       attribute.Kode,
+		Nama:       attribute.Nama,
+		Tipe:       attribute.Tipe,
+		Pilihan:    pilihan,
+		Wajib:      attribute.Wajib,
+		Urutan:     attribute.Urutan,
+	}
+}
+
+func ToReviewResponse(review entities.Review) ReviewResponse {
+	photos := []string{}
+	for _, photo := range review.Photos {
+		photos = append(photos, media.URL(photo.Url))
+	}
+
+	return ReviewResponse{
+		ID:            review.ID,
+		DetailTrxID:   review.IDDetailTrx,
+		ProductID:     review.IDProduk,
+		StoreID:       review.IDToko,
+		NamaUser:      review.User.Nama,
+		Rating:        review.Rating,
+		Ulasan:        review.Ulasan,
+		Photos:        photos,
+		Balasan:       review.Balasan,
+		DibalasPada:   review.DibalasPada,
+		Status:        review.Status,
+		JumlahLaporan: review.JumlahLaporan,
+		CreatedAt:     review.CreatedAt,
+	}
+}
+
+func ToStoreMemberResponse(member entities.StoreMember) StoreMemberResponse {
+	return StoreMemberResponse{
+		ID:        member.ID,
+		IDToko:    member.IDToko,
+		IDUser:    member.IDUser,
+		Nama:      member.User.Nama,
+		Email:     member.User.Email,
+		Peran:     member.Peran,
+		Izin:      StoreMemberPermissions(member.Izin),
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}
+}
+
+func ToStoreInvitationResponse(invitation entities.StoreInvitation) StoreInvitationResponse {
+	return StoreInvitationResponse{
+		ID:              invitation.ID,
+		IDToko:          invitation.IDToko,
+		Email:           invitation.Email,
+		Peran:           invitation.Peran,
+		Izin:            StoreMemberPermissions(invitation.Izin),
+		KedaluwarsaPada: invitation.KedaluwarsaPada,
+		DiterimaPada:    invitation.DiterimaPada,
+		CreatedAt:       invitation.CreatedAt,
+	}
+}
+
+func ToStoreOrderResponse(detail entities.TrxDetail) StoreOrderResponse {
+	return StoreOrderResponse{
+		ID:          detail.ID,
+		IDTrx:       detail.IDTrx,
+		KodeInvoice: detail.Trx.KodeInvoice,
+		MethodBayar: detail.Trx.MethodBayar,
+		Status:      detail.Status,
+		Kuantitas:   detail.Kuantitas,
+		HargaTotal:  detail.HargaTotal,
+		Product: StoreOrderItem{
+			ID:            detail.ProductLog.IDProduk,
+			NamaProduk:    detail.ProductLog.NamaProduk,
+			HargaKonsumen: detail.ProductLog.HargaKonsumen,
+		},
+		Address: AddressResponse{
+			ID:           detail.Trx.Address.ID,
+			JudulAlamat:  detail.Trx.Address.JudulAlamat,
+			NamaPenerima: detail.Trx.Address.NamaPenerima,
+			NoTelp:       detail.Trx.Address.NoTelp,
+			DetailAlamat: detail.Trx.Address.DetailAlamat,
+			CreatedAt:    detail.Trx.Address.CreatedAt,
+			UpdatedAt:    detail.Trx.Address.UpdatedAt,
+		},
+		CreatedAt: detail.CreatedAt,
+		UpdatedAt: detail.UpdatedAt,
+	}
+}
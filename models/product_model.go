@@ -1,21 +1,61 @@
 package models
 
-import "time"
+import (
+	"mini-project-evermos/models/responder"
+	"time"
+)
 
 // Request
 type ProductRequest struct {
-	NamaProduk    string   `json:"nama_produk" form:"nama_produk"`
-	CategoryID    uint     `json:"category_id" form:"category_id"`
-	StoreID       uint     `json:"store_id"`
-	HargaReseller string   `json:"harga_reseller" form:"harga_reseller"`
-	HargaKonsumen string   `json:"harga_konsumen" form:"harga_konsumen"`
-	Stok          int      `json:"stok" form:"stok"`
-	Deskripsi     string   `json:"deskripsi" form:"deskripsi"`
-	PhotoURLs     []string `json:"photo_urls" form:"photo_urls"` // Changed from Photos to PhotoURLs
+	NamaProduk    string            `json:"nama_produk" form:"nama_produk"`
+	SKU           string            `json:"sku" form:"sku"` // empty keeps the current SKU
+	CategoryID    uint              `json:"category_id" form:"category_id"`
+	StoreID       uint              `json:"store_id"`
+	HargaReseller string            `json:"harga_reseller" form:"harga_reseller"`
+	HargaKonsumen string            `json:"harga_konsumen" form:"harga_konsumen"`
+	Stok          *int              `json:"stok" form:"stok"`                 // nil keeps the current stock on update
+	StokMinimum   *int              `json:"stok_minimum" form:"stok_minimum"` // nil keeps the current threshold
+	Deskripsi     string            `json:"deskripsi" form:"deskripsi"`       // empty keeps the current description on update
+	PhotoURLs     []string          `json:"photo_urls" form:"photo_urls"`     // Changed from Photos to PhotoURLs; nil keeps the current photos on update
+	Attributes    map[string]string `json:"atribut" form:"atribut"`           // category attribute values by kode; nil keeps the current values on update
+	Status        string            `json:"status" form:"status"`             // empty keeps the current status
+}
+
+type ProductStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type ProductRejectRequest struct {
+	Alasan string `json:"alasan"`
 }
 
 // Response
 type ProductResponse struct {
+	ID            uint                     `json:"id"`
+	NamaProduk    string                   `json:"nama_produk"`
+	Slug          string                   `json:"slug"`
+	SKU           *string                  `json:"sku"`
+	HargaReseller string                   `json:"harga_reseler"`
+	HargaKonsumen string                   `json:"harga_konsumen"`
+	Stok          int                      `json:"stok"`
+	StokMinimum   int                      `json:"stok_minimum"`
+	Deskripsi     *string                  `json:"deskripsi"`
+	Store         StoreResponse            `json:"toko"`
+	Category      CategoryResponse         `json:"category"`
+	Photos        []ProductPictureResponse `json:"photos"`
+	Attributes    map[string]string        `json:"atribut"`
+	Status        string                   `json:"status"`
+	AlasanTolak   *string                  `json:"alasan_tolak"`
+	DitinjauPada  *time.Time               `json:"ditinjau_pada"`
+	Rating        float64                  `json:"rating"`
+	JumlahUlasan  int                      `json:"jumlah_ulasan"`
+	CreatedAt     *time.Time               `json:"created_at"`
+	UpdatedAt     *time.Time               `json:"updated_at"`
+}
+
+// CatalogProductResponse is a product as shoppers see it, without what only
+// the seller and moderators need: SKU, stock threshold and review outcome
+type CatalogProductResponse struct {
 	ID            uint                     `json:"id"`
 	NamaProduk    string                   `json:"nama_produk"`
 	Slug          string                   `json:"slug"`
@@ -26,6 +66,101 @@ type ProductResponse struct {
 	Store         StoreResponse            `json:"toko"`
 	Category      CategoryResponse         `json:"category"`
 	Photos        []ProductPictureResponse `json:"photos"`
+	Attributes    map[string]string        `json:"atribut"`
+	Status        string                   `json:"status"`
+	Rating        float64                  `json:"rating"`
+	JumlahUlasan  int                      `json:"jumlah_ulasan"`
 	CreatedAt     *time.Time               `json:"created_at"`
 	UpdatedAt     *time.Time               `json:"updated_at"`
 }
+
+// TrashedProductResponse is a deleted product waiting in the seller's trash
+type TrashedProductResponse struct {
+	ProductResponse
+	DeletedAt *time.Time `json:"deleted_at"`
+	// InTransactions products were sold: transactions point at their
+	// snapshots
+	InTransactions bool `json:"in_transactions"`
+	// HasHistory products are kept past the retention because their price
+	// history in log_produk is never deleted
+	HasHistory bool       `json:"has_history"`
+	PurgeAt    *time.Time `json:"purge_at"` // nil when the product is never purged
+}
+
+// ProductFilter narrows product listings
+type ProductFilter struct {
+	StoreID    uint
+	CategoryID uint
+	Status     string
+	// IncludeDescendants widens CategoryID to its subcategories
+	IncludeDescendants bool
+	MinPrice           *int
+	MaxPrice           *int
+	InStock            bool
+	CityID             string
+	// IDs restricts results to search hits, in relevance order; nil means no restriction
+	IDs []uint
+}
+
+// Accepted values for the product listing sort parameter
+const (
+	ProductSortRelevance   = "relevance"
+	ProductSortNewest      = "newest"
+	ProductSortPriceAsc    = "price_asc"
+	ProductSortPriceDesc   = "price_desc"
+	ProductSortBestSelling = "best_selling"
+	ProductSortRating      = "rating"
+)
+
+var ProductSorts = []string{ProductSortRelevance, ProductSortNewest, ProductSortPriceAsc, ProductSortPriceDesc, ProductSortBestSelling, ProductSortRating}
+
+// ProductSortOldest serves the review queue, first come first served
+const ProductSortOldest = "oldest"
+
+// Product statuses. Only published products appear in the catalog.
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusPublished     = "published"
+	ProductStatusRejected      = "rejected"
+	ProductStatusArchived      = "archived"
+)
+
+var ProductStatuses = []string{ProductStatusDraft, ProductStatusPendingReview, ProductStatusPublished, ProductStatusRejected, ProductStatusArchived}
+
+type ProductModerationResponse struct {
+	ID        uint       `json:"id"`
+	ProductID uint       `json:"product_id"`
+	Status    string     `json:"status"`
+	Alasan    *string    `json:"alasan"`
+	UserID    *uint      `json:"user_id"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type CategoryFacet struct {
+	CategoryID   uint   `json:"category_id"`
+	NamaCategory string `json:"nama_category"`
+	Count        int64  `json:"count"`
+}
+
+type PriceFacet struct {
+	Min   int   `json:"min"`
+	Max   *int  `json:"max"`
+	Count int64 `json:"count"`
+}
+
+type ProductFacets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Prices     []PriceFacet    `json:"prices"`
+}
+
+type ProductSearchResponse struct {
+	responder.Pagination
+	Facets ProductFacets `json:"facets"`
+}
+
+// SlugRedirect points an old product slug to the one currently in use
+type SlugRedirect struct {
+	Slug     string `json:"slug"`
+	Location string `json:"location"`
+}
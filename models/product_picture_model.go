@@ -2,17 +2,30 @@ package models
 
 import "time"
 
+// MaxProductPhotoUpload caps the photos attached to a product in one upload
+const MaxProductPhotoUpload = 10
+
 // request
 type ProductPictureRequest struct {
 	ProductID uint   `json:"product_id" form:"product_id"` // Fix: changed from category_id to product_id
 	URL       string `json:"url" form:"url"`               // Fix: changed from photo_url to url
+	AltText   string `json:"alt_text" form:"alt_text"`
+}
+
+// ProductPictureOrderRequest lists every photo id of a product in the new order
+type ProductPictureOrderRequest struct {
+	IDs []uint `json:"ids"`
 }
 
 // response
 type ProductPictureResponse struct {
-	ID        uint       `json:"id"`
-	IDProduk  uint       `json:"product_id"`
-	Url       string     `json:"url"`
-	CreatedAt *time.Time `json:"created_at"` // Changed to pointer type
-	UpdatedAt *time.Time `json:"updated_at"` // Changed to pointer type
+	ID        uint              `json:"id"`
+	IDProduk  uint              `json:"product_id"`
+	Url       string            `json:"url"`
+	Sizes     map[string]string `json:"sizes,omitempty"` // thumbnail files, for uploads processed by the media service
+	Posisi    int               `json:"posisi"`
+	Utama     bool              `json:"utama"`
+	AltText   string            `json:"alt_text"`
+	CreatedAt *time.Time        `json:"created_at"` // Changed to pointer type
+	UpdatedAt *time.Time        `json:"updated_at"` // Changed to pointer type
 }
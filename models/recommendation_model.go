@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RecentlyViewedLimit bounds the view history kept per user
+const RecentlyViewedLimit = 50
+
+// RelatedLimit bounds the co-purchase relations kept per product
+const RelatedLimit = 20
+
+type RecentlyViewedResponse struct {
+	CatalogProductResponse
+	DilihatPada time.Time `json:"dilihat_pada"`
+}
+
+type RelatedProductResponse struct {
+	CatalogProductResponse
+	// Skor counts the transactions that had both products; 0 for products
+	// filled in from the same category
+	Skor int64 `json:"skor"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+	"mini-project-evermos/models/responder"
+	"time"
+)
+
+// Review statuses. Hidden reviews are left out of listings and ratings.
+const (
+	ReviewStatusVisible = "visible"
+	ReviewStatusHidden  = "hidden"
+)
+
+// MaxReviewPhotos caps the photos attached to one review
+const MaxReviewPhotos = 5
+
+// Request
+type ReviewRequest struct {
+	DetailTrxID uint     `json:"detail_trx_id" form:"detail_trx_id"`
+	Rating      int      `json:"rating" form:"rating"`
+	Ulasan      string   `json:"ulasan" form:"ulasan"`
+	PhotoURLs   []string `json:"photo_urls"`
+}
+
+type ReviewReplyRequest struct {
+	Balasan string `json:"balasan"`
+}
+
+type ReviewFlagRequest struct {
+	Alasan string `json:"alasan"`
+}
+
+// Response
+type ReviewResponse struct {
+	ID            uint       `json:"id"`
+	DetailTrxID   uint       `json:"detail_trx_id"`
+	ProductID     uint       `json:"product_id"`
+	StoreID       uint       `json:"store_id"`
+	NamaUser      string     `json:"nama_user"`
+	Rating        int        `json:"rating"`
+	Ulasan        *string    `json:"ulasan"`
+	Photos        []string   `json:"photos"`
+	Balasan       *string    `json:"balasan"`
+	DibalasPada   *time.Time `json:"dibalas_pada"`
+	Status        string     `json:"status"`
+	JumlahLaporan int        `json:"jumlah_laporan"`
+	CreatedAt     *time.Time `json:"created_at"`
+}
+
+// ReviewListResponse is one page of a product's reviews with its rating
+// breakdown by stars
+type ReviewListResponse struct {
+	responder.Pagination
+	Rating       float64       `json:"rating"`
+	JumlahUlasan int           `json:"jumlah_ulasan"`
+	Bintang      map[int]int64 `json:"bintang"`
+}
+
+type ReviewFlagResponse struct {
+	UserID    uint       `json:"user_id"`
+	Alasan    string     `json:"alasan"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// AverageRating turns rating aggregates into an average rounded to one
+// decimal, 0 without reviews.
+func AverageRating(total int, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(total)/float64(count)*10) / 10
+}
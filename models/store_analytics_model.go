@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Analytics buckets
+const (
+	AnalyticsBucketDay  = "day"
+	AnalyticsBucketWeek = "week"
+)
+
+const (
+	// DefaultAnalyticsDays is the range shown when none is asked for, ending today
+	DefaultAnalyticsDays = 30
+	// MaxAnalyticsDays caps the range of one request
+	MaxAnalyticsDays = 366
+	// DefaultAnalyticsTopProducts and MaxAnalyticsTopProducts bound top_products
+	DefaultAnalyticsTopProducts = 5
+	MaxAnalyticsTopProducts     = 50
+)
+
+// Request
+type StoreAnalyticsRequest struct {
+	From   string // YYYY-MM-DD, inclusive
+	To     string // YYYY-MM-DD, inclusive
+	Bucket string
+	Top    int
+}
+
+// Response
+type StoreAnalyticsResponse struct {
+	StoreID     uint                    `json:"store_id"`
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	Bucket      string                  `json:"bucket"`
+	Summary     StoreAnalyticsSummary   `json:"summary"`
+	Series      []StoreAnalyticsPoint   `json:"series"`
+	TopProducts []StoreAnalyticsProduct `json:"top_products"`
+	UpdatedAt   *time.Time              `json:"updated_at"` // the figures include everything up to this moment
+}
+
+// StoreAnalyticsSummary covers the whole range. Cancelled and refunded
+// items are not counted as sales.
+type StoreAnalyticsSummary struct {
+	Revenue         int64   `json:"revenue"`
+	Orders          int     `json:"orders"`
+	UnitsSold       int     `json:"units_sold"`
+	AOV             float64 `json:"aov"` // revenue per order
+	Views           int     `json:"views"`
+	ConversionRate  float64 `json:"conversion_rate"` // orders per product view
+	Buyers          int     `json:"buyers"`
+	RepeatBuyers    int     `json:"repeat_buyers"` // buyers who ordered more than once, before or within the range
+	RepeatBuyerRate float64 `json:"repeat_buyer_rate"`
+}
+
+// StoreAnalyticsPoint is one bucket, starting at Date
+type StoreAnalyticsPoint struct {
+	Date      string  `json:"date"`
+	Revenue   int64   `json:"revenue"`
+	Orders    int     `json:"orders"`
+	UnitsSold int     `json:"units_sold"`
+	AOV       float64 `json:"aov"`
+	Views     int     `json:"views"`
+}
+
+type StoreAnalyticsProduct struct {
+	ProductID      uint    `json:"product_id"`
+	NamaProduk     string  `json:"nama_produk"`
+	Revenue        int64   `json:"revenue"`
+	Orders         int     `json:"orders"`
+	UnitsSold      int     `json:"units_sold"`
+	Views          int     `json:"views"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"mini-project-evermos/models/entities"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Store roles. Every store has one owner; managers can do everything but
+// delete the store or manage other managers, staff only what they were
+// granted.
+const (
+	StoreRoleOwner   = "owner"
+	StoreRoleManager = "manager"
+	StoreRoleStaff   = "staff"
+)
+
+// Store permissions
+const (
+	StorePermissionManageProducts = "manage_products"
+	StorePermissionProcessOrders  = "process_orders"
+	StorePermissionViewFinance    = "view_finance"
+	StorePermissionManageStore    = "manage_store"
+	StorePermissionManageStaff    = "manage_staff"
+)
+
+// StoreStaffPermissions are the permissions that can be granted to staff.
+// Managing the store and its staff is left to owners and managers.
+var StoreStaffPermissions = []string{StorePermissionManageProducts, StorePermissionProcessOrders, StorePermissionViewFinance}
+
+// StoreInvitationValidity is how long an invitation can be accepted
+const StoreInvitationValidity = 7 * 24 * time.Hour
+
+// StoreMemberCan reports whether the member has permission in their store
+func StoreMemberCan(member entities.StoreMember, permission string) bool {
+	switch member.Peran {
+	case StoreRoleOwner, StoreRoleManager:
+		return true
+	case StoreRoleStaff:
+		return slices.Contains(StoreMemberPermissions(member.Izin), permission)
+	default:
+		return false
+	}
+}
+
+// StoreMemberPermissions splits the stored comma separated permissions
+func StoreMemberPermissions(izin string) []string {
+	permissions := []string{}
+	for _, v := range strings.Split(izin, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			permissions = append(permissions, v)
+		}
+	}
+	return permissions
+}
+
+// Request
+type StoreInvitationRequest struct {
+	Email string   `json:"email"`
+	Peran string   `json:"peran"`
+	Izin  []string `json:"izin"`
+}
+
+type StoreMemberRequest struct {
+	Peran string   `json:"peran"`
+	Izin  []string `json:"izin"`
+}
+
+type StoreInvitationAcceptRequest struct {
+	Token string `json:"token"`
+}
+
+// Response
+type StoreMemberResponse struct {
+	ID        uint       `json:"id"`
+	IDToko    uint       `json:"id_toko"`
+	IDUser    uint       `json:"id_user"`
+	Nama      string     `json:"nama"`
+	Email     string     `json:"email"`
+	Peran     string     `json:"peran"`
+	Izin      []string   `json:"izin"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type StoreInvitationResponse struct {
+	ID              uint       `json:"id"`
+	IDToko          uint       `json:"id_toko"`
+	Email           string     `json:"email"`
+	Peran           string     `json:"peran"`
+	Izin            []string   `json:"izin"`
+	KedaluwarsaPada time.Time  `json:"kedaluwarsa_pada"`
+	DiterimaPada    *time.Time `json:"diterima_pada"`
+	CreatedAt       *time.Time `json:"created_at"`
+}
+
+// StoreMembershipResponse is a store the user works in and what they may do there
+type StoreMembershipResponse struct {
+	Peran string        `json:"peran"`
+	Izin  []string      `json:"izin"`
+	Store StoreResponse `json:"toko"`
+}
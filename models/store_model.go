@@ -2,16 +2,54 @@ package models
 
 import (
 	"mime/multipart"
+	"mini-project-evermos/models/entities"
 	"time"
 )
 
 // Response
 type StoreResponse struct {
-	ID        uint       `json:"id"`
-	NamaToko  *string    `json:"nama_toko"`
-	UrlFoto   *string    `json:"url_foto"`
-	CreatedAt *time.Time `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	ID             uint                `json:"id"`
+	NamaToko       *string             `json:"nama_toko"`
+	Slug           string              `json:"slug"`
+	UrlFoto        *string             `json:"url_foto"`
+	FotoSizes      map[string]string   `json:"foto_sizes,omitempty"`
+	UrlBanner      *string             `json:"url_banner"`
+	BannerSizes    map[string]string   `json:"banner_sizes,omitempty"`
+	Deskripsi      string              `json:"deskripsi"`
+	IDProvinsi     string              `json:"id_provinsi"`
+	IDKota         string              `json:"id_kota"`
+	Kontak         StoreContact        `json:"kontak"`
+	JamOperasional []StoreHourResponse `json:"jam_operasional"`
+	Libur          bool                `json:"libur"` // true while vacation mode is on
+	LiburSampai    *time.Time          `json:"libur_sampai"`
+	PesanLibur     string              `json:"pesan_libur"`
+	Rating         float64             `json:"rating"`
+	JumlahUlasan   int                 `json:"jumlah_ulasan"`
+	JumlahPengikut int                 `json:"jumlah_pengikut"`
+	CreatedAt      *time.Time          `json:"created_at"`
+	UpdatedAt      *time.Time          `json:"updated_at"`
+}
+
+// StoreContact lists the channels buyers can reach a store on
+type StoreContact struct {
+	Telepon   string `json:"telepon"`
+	Whatsapp  string `json:"whatsapp"`
+	Email     string `json:"email"`
+	Instagram string `json:"instagram"`
+}
+
+// StoreHourResponse is one opening day, hari 0 is Sunday
+type StoreHourResponse struct {
+	Hari  int    `json:"hari"`
+	Buka  string `json:"buka"`
+	Tutup string `json:"tutup"`
+}
+
+// StorefrontResponse is a store's public page: its profile and the
+// published products matching the query
+type StorefrontResponse struct {
+	Store    StoreResponse         `json:"store"`
+	Products ProductSearchResponse `json:"products"`
 }
 
 type StoreUpdate struct {
@@ -19,13 +57,45 @@ type StoreUpdate struct {
 	UrlFoto  string
 }
 
+// StoreProcess carries a store create or edit. Nil profile fields are left
+// as they are, JamOperasional nil keeps the current hours.
 type StoreProcess struct {
-	ID        uint
-	UserID    uint
-	NamaToko  *string
-	URL       string
-	CreatedAt *time.Time
-	UpdatedAt *time.Time
+	ID             uint
+	UserID         uint
+	NamaToko       *string
+	URL            string
+	UrlBanner      *string
+	Deskripsi      *string
+	IDProvinsi     *string
+	IDKota         *string
+	Telepon        *string
+	Whatsapp       *string
+	Email          *string
+	Instagram      *string
+	JamOperasional []StoreHourRequest
+	CreatedAt      *time.Time
+	UpdatedAt      *time.Time
+}
+
+// StoreHourRequest opens the store on hari (0 is Sunday) from buka to tutup,
+// both HH:MM
+type StoreHourRequest struct {
+	Hari  int    `json:"hari"`
+	Buka  string `json:"buka"`
+	Tutup string `json:"tutup"`
+}
+
+// StoreVacationRequest turns vacation mode on or off. While it is on the
+// store takes no orders; libur_sampai ends it on its own.
+type StoreVacationRequest struct {
+	Libur       bool       `json:"libur"`
+	LiburSampai *time.Time `json:"libur_sampai"`
+	PesanLibur  string     `json:"pesan_libur"`
+}
+
+// StoreOnVacation reports whether the store's vacation mode is on at now
+func StoreOnVacation(store entities.Store, now time.Time) bool {
+	return store.Libur && (store.LiburSampai == nil || now.Before(*store.LiburSampai))
 }
 
 type File struct {
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Order statuses of a transaction line item. Each store moves its own
+// items along, see StoreOrderTransitions.
+const (
+	OrderStatusPending    = "pending"
+	OrderStatusProcessing = "processing"
+	OrderStatusShipped    = "shipped"
+	OrderStatusCompleted  = "completed"
+	OrderStatusCancelled  = "cancelled"
+	OrderStatusRefunded   = "refunded"
+)
+
+var OrderStatuses = []string{OrderStatusPending, OrderStatusProcessing, OrderStatusShipped, OrderStatusCompleted, OrderStatusCancelled, OrderStatusRefunded}
+
+// StoreOrderTransitions lists the statuses a store may move an item to from
+// each status
+var StoreOrderTransitions = map[string][]string{
+	OrderStatusPending:    {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusCompleted, OrderStatusRefunded},
+	OrderStatusCompleted:  {OrderStatusRefunded},
+}
+
+// Request
+type StoreOrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// Response
+type StoreOrderResponse struct {
+	ID          uint            `json:"id"`
+	IDTrx       uint            `json:"id_trx"`
+	KodeInvoice string          `json:"kode_invoice"`
+	MethodBayar string          `json:"method_bayar"`
+	Status      string          `json:"status"`
+	Kuantitas   int             `json:"kuantitas"`
+	HargaTotal  int             `json:"harga_total"`
+	Product     StoreOrderItem  `json:"product"`
+	Address     AddressResponse `json:"alamat_kirim"`
+	CreatedAt   *time.Time      `json:"created_at"`
+	UpdatedAt   *time.Time      `json:"updated_at"`
+}
+
+// StoreOrderItem is the product as it was when it was bought
+type StoreOrderItem struct {
+	ID            uint   `json:"id"`
+	NamaProduk    string `json:"nama_produk"`
+	HargaKonsumen string `json:"harga_konsumen"`
+}
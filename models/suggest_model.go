@@ -0,0 +1,22 @@
+package models
+
+// Response
+type SuggestItem struct {
+	ID    uint    `json:"id,omitempty"`
+	Text  string  `json:"text"`
+	Slug  string  `json:"slug,omitempty"`
+	Score float64 `json:"score"`
+}
+
+type SuggestResponse struct {
+	Query      string        `json:"query"`
+	Products   []SuggestItem `json:"products"`
+	Categories []SuggestItem `json:"categories"`
+	Stores     []SuggestItem `json:"stores"`
+	Queries    []SuggestItem `json:"queries"`
+}
+
+type PopularQuery struct {
+	KataKunci string `json:"kata_kunci"`
+	Total     int64  `json:"total"` // distinct clients that searched it
+}
@@ -11,6 +11,7 @@ type TransactionDetailResponse struct {
 	ID         uint            `json:"id"`
 	Kuantitas  int             `json:"kuantitas"`
 	HargaTotal int             `json:"harga_total"`
+	Status     string          `json:"status"`
 	Store      StoreResponse   `json:"toko"`
 	Product    ProductResponse `json:"product"`
 }
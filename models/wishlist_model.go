@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// DefaultWishlistName is the wishlist products are saved to when none is given
+const DefaultWishlistName = "Favorit"
+
+// Request
+type WishlistRequest struct {
+	Nama string `json:"nama"`
+}
+
+type WishlistItemRequest struct {
+	ProductID uint `json:"product_id"`
+}
+
+// Response
+type WishlistResponse struct {
+	ID         uint                   `json:"id"`
+	Nama       string                 `json:"nama"`
+	JumlahItem int                    `json:"jumlah_item"`
+	Items      []WishlistItemResponse `json:"items,omitempty"`
+	CreatedAt  *time.Time             `json:"created_at"`
+	UpdatedAt  *time.Time             `json:"updated_at"`
+}
+
+type WishlistItemResponse struct {
+	ID        uint                   `json:"id"`
+	Product   CatalogProductResponse `json:"product"`
+	CreatedAt *time.Time             `json:"created_at"`
+}
+
+type FollowedStoreResponse struct {
+	StoreResponse
+	DiikutiSejak *time.Time `json:"diikuti_sejak"`
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type AdminReportRepository interface {
+	FindDailySales(from time.Time, to time.Time) ([]models.AdminDailySales, error)
+	CountOrdersByStatus(from time.Time, to time.Time) ([]models.AdminOrderStatusStat, error)
+	CountNewUsers(from time.Time, to time.Time) ([]models.AdminDailyCount, error)
+	CountNewStores(from time.Time, to time.Time) ([]models.AdminDailyCount, error)
+	FindTopCategories(from time.Time, to time.Time, limit int) ([]models.AdminTopEntry, error)
+	FindTopStores(from time.Time, to time.Time, limit int) ([]models.AdminTopEntry, error)
+}
+
+type adminReportRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewAdminReportRepository(database *gorm.DB) AdminReportRepository {
+	return &adminReportRepositoryImpl{database}
+}
+
+// reportArgs passes the range [from, to) to the queries as local date times,
+// as rollupDay does
+func reportArgs(from time.Time, to time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"start":     from.Format(time.DateTime),
+		"end":       to.Format(time.DateTime),
+		"cancelled": models.OrderStatusCancelled,
+		"refunded":  models.OrderStatusRefunded,
+	}
+}
+
+func (repository *adminReportRepositoryImpl) FindDailySales(from time.Time, to time.Time) ([]models.AdminDailySales, error) {
+	var sales []models.AdminDailySales
+	err := repository.database.Raw(`SELECT DATE_FORMAT(created_at, '%Y-%m-%d') AS date,
+                                    COALESCE(SUM(CASE WHEN status <> @cancelled THEN harga_total END), 0) AS gmv,
+                                    COUNT(DISTINCT CASE WHEN status <> @cancelled THEN id_trx END) AS transactions,
+                                    COUNT(CASE WHEN status <> @cancelled THEN 1 END) AS orders,
+                                    COALESCE(SUM(CASE WHEN status <> @cancelled THEN kuantitas END), 0) AS units_sold,
+                                    COUNT(CASE WHEN status = @refunded THEN 1 END) AS refunded,
+                                    COALESCE(SUM(CASE WHEN status = @refunded THEN harga_total END), 0) AS refunded_amount
+                                    FROM detail_trx
+                                    WHERE deleted_at IS NULL AND created_at >= @start AND created_at < @end
+                                    GROUP BY date
+                                    ORDER BY date`, reportArgs(from, to)).Scan(&sales).Error
+	return sales, err
+}
+
+func (repository *adminReportRepositoryImpl) CountOrdersByStatus(from time.Time, to time.Time) ([]models.AdminOrderStatusStat, error) {
+	var stats []models.AdminOrderStatusStat
+	err := repository.database.Raw(`SELECT status, COUNT(*) AS orders, COALESCE(SUM(harga_total), 0) AS amount
+                                    FROM detail_trx
+                                    WHERE deleted_at IS NULL AND created_at >= @start AND created_at < @end
+                                    GROUP BY status`, reportArgs(from, to)).Scan(&stats).Error
+	return stats, err
+}
+
+// CountNewUsers counts the users who signed up each day, including those
+// deleted since
+func (repository *adminReportRepositoryImpl) CountNewUsers(from time.Time, to time.Time) ([]models.AdminDailyCount, error) {
+	return repository.countCreated(&entities.User{}, from, to)
+}
+
+// CountNewStores counts the stores made each day, including those deleted
+// since
+func (repository *adminReportRepositoryImpl) CountNewStores(from time.Time, to time.Time) ([]models.AdminDailyCount, error) {
+	return repository.countCreated(&entities.Store{}, from, to)
+}
+
+func (repository *adminReportRepositoryImpl) countCreated(model interface{}, from time.Time, to time.Time) ([]models.AdminDailyCount, error) {
+	var counts []models.AdminDailyCount
+	err := repository.database.Unscoped().Model(model).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS date, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from.Format(time.DateTime), to.Format(time.DateTime)).
+		Group("date").
+		Order("date").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// FindTopCategories ranks categories by GMV. Items count for the category the
+// product had when it was bought.
+func (repository *adminReportRepositoryImpl) FindTopCategories(from time.Time, to time.Time, limit int) ([]models.AdminTopEntry, error) {
+	args := reportArgs(from, to)
+	args["limit"] = limit
+
+	var entries []models.AdminTopEntry
+	err := repository.database.Raw(`SELECT l.id_category AS id, COALESCE(c.nama_category, '') AS name,
+                                    SUM(d.harga_total) AS gmv, COUNT(*) AS orders, SUM(d.kuantitas) AS units_sold
+                                    FROM detail_trx d
+                                    JOIN log_produk l ON l.id = d.id_log_produk
+                                    LEFT JOIN category c ON c.id = l.id_category
+                                    WHERE d.deleted_at IS NULL AND d.created_at >= @start AND d.created_at < @end AND d.status <> @cancelled
+                                    GROUP BY l.id_category, c.nama_category
+                                    ORDER BY gmv DESC, units_sold DESC
+                                    LIMIT @limit`, args).Scan(&entries).Error
+	return entries, err
+}
+
+func (repository *adminReportRepositoryImpl) FindTopStores(from time.Time, to time.Time, limit int) ([]models.AdminTopEntry, error) {
+	args := reportArgs(from, to)
+	args["limit"] = limit
+
+	var entries []models.AdminTopEntry
+	err := repository.database.Raw(`SELECT d.id_toko AS id, COALESCE(t.nama_toko, '') AS name,
+                                    SUM(d.harga_total) AS gmv, COUNT(*) AS orders, SUM(d.kuantitas) AS units_sold
+                                    FROM detail_trx d
+                                    LEFT JOIN toko t ON t.id = d.id_toko
+                                    WHERE d.deleted_at IS NULL AND d.created_at >= @start AND d.created_at < @end AND d.status <> @cancelled
+                                    GROUP BY d.id_toko, t.nama_toko
+                                    ORDER BY gmv DESC, units_sold DESC
+                                    LIMIT @limit`, args).Scan(&entries).Error
+	return entries, err
+}
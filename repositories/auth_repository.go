@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"mini-project-evermos/models/entities"
 
 	"gorm.io/gorm"
@@ -19,18 +20,46 @@ func NewAuthRepository(database *gorm.DB) AuthRepository {
 	return &authRepositoryImpl{database}
 }
 
+// Register creates the user together with their store, so every user can
+// start selling right away
 func (repository *authRepositoryImpl) Register(user entities.User) (entities.User, error) {
-	// First, try to find any existing records (including soft-deleted ones)
-	var existingUser entities.User
-	result := repository.database.Unscoped().Where("notelp = ? OR email = ?", user.Notelp, user.Email).First(&existingUser)
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		// Deleted accounts keep their email and phone number too, they still
+		// own orders and reviews
+		var existingUser entities.User
+		result := tx.Unscoped().Where("notelp = ? OR email = ?", user.Notelp, user.Email).Limit(1).Find(&existingUser)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if existingUser.Email == user.Email {
+				return errors.New("email is already registered")
+			}
+			return errors.New("phone number is already registered")
+		}
 
-	if result.Error == nil {
-		// If found, hard delete the existing record
-		repository.database.Unscoped().Delete(&existingUser)
-	}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		nama_toko := "Toko " + user.Nama
+		slug, err := uniqueStoreSlug(tx, &nama_toko, 0)
+		if err != nil {
+			return err
+		}
 
-	// Now create the new user
-	err := repository.database.Create(&user).Error
+		store := entities.Store{
+			IDUser:     user.ID,
+			NamaToko:   &nama_toko,
+			Slug:       slug,
+			IDProvinsi: user.IDProvinsi,
+			IDKota:     user.IDKota,
+		}
+		if err := tx.Create(&store).Error; err != nil {
+			return err
+		}
+		return addStoreOwner(tx, store.ID, user.ID)
+	})
 	if err != nil {
 		return entities.User{}, err
 	}
@@ -1,8 +1,13 @@
 package repositories
 
 import (
+	"fmt"
+	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
+	"strconv"
+	"strings"
 
+	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
@@ -10,8 +15,14 @@ import (
 type CategoryRepository interface {
 	FindAll() ([]entities.Category, error)
 	FindById(id uint) (entities.Category, error)
-	Insert(category entities.Category) (entities.Category, error)
-	Update(id uint, category entities.Category) (entities.Category, error)
+	FindAllAttributes() ([]entities.CategoryAttribute, error)
+	CountProducts() (map[uint]int64, error)
+	FindAncestors(category entities.Category) ([]entities.Category, error)
+	FindAttributes(category entities.Category) ([]entities.CategoryAttribute, error)
+	FindDescendantAttributes(category entities.Category) ([]entities.CategoryAttribute, error)
+	CountChildren(id uint) (int64, error)
+	Insert(category entities.Category, attributes []entities.CategoryAttribute) (entities.Category, error)
+	Update(id uint, category entities.Category, attributes []entities.CategoryAttribute) (entities.Category, error)
 	Destroy(id uint) (bool, error)
 }
 
@@ -23,10 +34,11 @@ func NewCategoryRepository(database *gorm.DB) CategoryRepository {
 	return &categoryRepositoryImpl{database}
 }
 
+// FindAll lists categories depth first, each parent before its children.
 func (repository *categoryRepositoryImpl) FindAll() ([]entities.Category, error) {
 	var categories []entities.Category
 
-	err := repository.database.Find(&categories).Error
+	err := repository.database.Order("path asc").Find(&categories).Error
 
 	if err != nil {
 		return categories, err
@@ -47,17 +59,192 @@ func (repository *categoryRepositoryImpl) FindById(id uint) (entities.Category,
 	return category, nil
 }
 
-func (repository *categoryRepositoryImpl) Insert(category entities.Category) (entities.Category, error) {
-	err := repository.database.Create(&category).Error
-	return category, err
+func (repository *categoryRepositoryImpl) FindAllAttributes() ([]entities.CategoryAttribute, error) {
+	var attributes []entities.CategoryAttribute
+
+	err := repository.database.Order("urutan asc, id asc").Find(&attributes).Error
+
+	if err != nil {
+		return attributes, err
+	}
+
+	return attributes, nil
+}
+
+// CountProducts returns how many published products sit directly in each
+// category.
+func (repository *categoryRepositoryImpl) CountProducts() (map[uint]int64, error) {
+	var rows []struct {
+		IDCategory uint
+		Total      int64
+	}
+	err := repository.database.Model(&entities.Product{}).
+		Select("id_category, COUNT(*) AS total").
+		Where("status = ?", models.ProductStatusPublished).
+		Group("id_category").
+		Scan(&rows).Error
+
+	counts := map[uint]int64{}
+	if err != nil {
+		return counts, err
+	}
+
+	for _, row := range rows {
+		counts[row.IDCategory] = row.Total
+	}
+
+	return counts, nil
+}
+
+// FindAncestors returns the categories on the path from the root down to and
+// including category.
+func (repository *categoryRepositoryImpl) FindAncestors(category entities.Category) ([]entities.Category, error) {
+	var ancestors []entities.Category
+
+	ids := pathIds(category.Path)
+	if len(ids) == 0 && category.ID == 0 {
+		return []entities.Category{}, nil
+	}
+	if len(ids) == 0 {
+		return []entities.Category{category}, nil
+	}
+
+	err := repository.database.Where("id IN ?", ids).Order("depth asc").Find(&ancestors).Error
+	if err != nil {
+		return ancestors, err
+	}
+
+	return ancestors, nil
+}
+
+// FindAttributes returns the attributes products of category must fill in:
+// its own and those inherited from its ancestors.
+func (repository *categoryRepositoryImpl) FindAttributes(category entities.Category) ([]entities.CategoryAttribute, error) {
+	var attributes []entities.CategoryAttribute
+
+	ids := pathIds(category.Path)
+	if len(ids) == 0 {
+		ids = []uint{category.ID}
+	}
+
+	err := repository.database.
+		Joins("JOIN category ON category.id = atribut_category.id_category").
+		Where("atribut_category.id_category IN ?", ids).
+		Order("category.depth asc, atribut_category.urutan asc, atribut_category.id asc").
+		Find(&attributes).Error
+
+	if err != nil {
+		return attributes, err
+	}
+
+	return attributes, nil
 }
 
-func (repository *categoryRepositoryImpl) Update(id uint, category entities.Category) (entities.Category, error) {
-	category.ID = id
-	err := repository.database.Save(&category).Error
+// FindDescendantAttributes returns the attributes defined below category.
+func (repository *categoryRepositoryImpl) FindDescendantAttributes(category entities.Category) ([]entities.CategoryAttribute, error) {
+	var attributes []entities.CategoryAttribute
+
+	err := repository.database.
+		Joins("JOIN category ON category.id = atribut_category.id_category").
+		Where("category.path LIKE ? AND category.id <> ? AND category.deleted_at IS NULL", category.Path+"%", category.ID).
+		Find(&attributes).Error
+
+	if err != nil {
+		return attributes, err
+	}
+
+	return attributes, nil
+}
+
+func (repository *categoryRepositoryImpl) CountChildren(id uint) (int64, error) {
+	var count int64
+	err := repository.database.Model(&entities.Category{}).Where("id_parent = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (repository *categoryRepositoryImpl) Insert(category entities.Category, attributes []entities.CategoryAttribute) (entities.Category, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		parent_path, depth, err := repository.parentPath(tx, category.IDParent)
+		if err != nil {
+			return err
+		}
+
+		category.Slug, err = repository.uniqueSlug(tx, category.NamaCategory, 0)
+		if err != nil {
+			return err
+		}
+		category.Depth = depth
+
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+
+		// The path includes the category's own id, known only after insert
+		category.Path = fmt.Sprintf("%s%d/", parent_path, category.ID)
+		if err := tx.Model(&category).Update("path", category.Path).Error; err != nil {
+			return err
+		}
+
+		return replaceAttributes(tx, category.ID, attributes)
+	})
+
 	return category, err
 }
 
+// Update renames and re-parents a category. Moving it carries its whole
+// subtree along. attributes nil leaves the attribute schema as it is.
+func (repository *categoryRepositoryImpl) Update(id uint, category entities.Category, attributes []entities.CategoryAttribute) (entities.Category, error) {
+	var current entities.Category
+
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).First(&current).Error; err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"nama_category": category.NamaCategory,
+			"id_parent":     category.IDParent,
+		}
+
+		if slug.Make(category.NamaCategory) != slug.Make(current.NamaCategory) {
+			new_slug, err := repository.uniqueSlug(tx, category.NamaCategory, id)
+			if err != nil {
+				return err
+			}
+			changes["slug"] = new_slug
+		}
+
+		if !sameParent(current.IDParent, category.IDParent) {
+			parent_path, depth, err := repository.parentPath(tx, category.IDParent)
+			if err != nil {
+				return err
+			}
+			new_path := fmt.Sprintf("%s%d/", parent_path, id)
+			err = tx.Exec(`UPDATE category
+                           SET path = CONCAT(?, SUBSTRING(path, ?)), depth = depth + ?
+                           WHERE path LIKE ?`,
+				new_path, len(current.Path)+1, depth-current.Depth, current.Path+"%").Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&entities.Category{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+
+		if attributes != nil {
+			return replaceAttributes(tx, id, attributes)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.Category{}, err
+	}
+
+	return repository.FindById(id)
+}
+
 func (repository *categoryRepositoryImpl) Destroy(id uint) (bool, error) {
 	var category entities.Category
 	err := repository.database.Where("id = ?", id).Delete(&category).Error
@@ -68,3 +255,83 @@ func (repository *categoryRepositoryImpl) Destroy(id uint) (bool, error) {
 
 	return true, nil
 }
+
+// parentPath returns the path and child depth below parent_id, or the root
+// when it is nil.
+func (repository *categoryRepositoryImpl) parentPath(tx *gorm.DB, parent_id *uint) (string, int, error) {
+	if parent_id == nil {
+		return "/", 0, nil
+	}
+
+	var parent entities.Category
+	if err := tx.Where("id = ?", *parent_id).First(&parent).Error; err != nil {
+		return "", 0, fmt.Errorf("parent category %d not found: %v", *parent_id, err)
+	}
+
+	return parent.Path, parent.Depth + 1, nil
+}
+
+// uniqueSlug builds a slug from name that no other category uses, appending
+// -2, -3, ... on collisions.
+func (repository *categoryRepositoryImpl) uniqueSlug(tx *gorm.DB, name string, category_id uint) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "category"
+	}
+
+	var taken []string
+	err := tx.Unscoped().Model(&entities.Category{}).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, base+"-%", category_id).
+		Pluck("slug", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]bool{}
+	for _, value := range taken {
+		used[value] = true
+	}
+
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return candidate, nil
+}
+
+func replaceAttributes(tx *gorm.DB, category_id uint, attributes []entities.CategoryAttribute) error {
+	if err := tx.Where("id_category = ?", category_id).Delete(&entities.CategoryAttribute{}).Error; err != nil {
+		return err
+	}
+
+	for i := range attributes {
+		attributes[i].ID = 0
+		attributes[i].IDCategory = category_id
+		attributes[i].Urutan = i
+		if err := tx.Create(&attributes[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameParent(a *uint, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// pathIds splits a materialized path such as /1/5/12/ into its ids.
+func pathIds(path string) []uint {
+	ids := []uint{}
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
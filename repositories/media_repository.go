@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"mini-project-evermos/models/entities"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type MediaRepository interface {
+	InUse(name string) (bool, error)
+}
+
+type mediaRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewMediaRepository(database *gorm.DB) MediaRepository {
+	return &mediaRepositoryImpl{database}
+}
+
+// InUse reports whether any product, store or review photo or store banner,
+// trashed or not, still points at name. Uploads are named by content, so one
+// file can be shared by several records.
+func (repository *mediaRepositoryImpl) InUse(name string) (bool, error) {
+	var count int64
+	err := repository.database.Unscoped().Model(&entities.ProductPicture{}).
+		Where("url = ?", name).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = repository.database.Unscoped().Model(&entities.Store{}).
+		Where("url_foto = ? OR url_banner = ?", name, name).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	err = repository.database.Model(&entities.ReviewPhoto{}).
+		Where("url = ?", name).
+		Count(&count).Error
+
+	return count > 0, err
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type NotificationRepository interface {
+	FindByStoreId(store_id uint) ([]entities.Notification, error)
+	FindByRecipient(user_id uint, store_id *uint) ([]entities.Notification, error)
+	FindById(id uint) (entities.Notification, error)
+	Insert(notification entities.Notification) (entities.Notification, error)
+	MarkAsRead(id uint) (bool, error)
+}
+
+type notificationRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewNotificationRepository(database *gorm.DB) NotificationRepository {
+	return &notificationRepositoryImpl{database}
+}
+
+func (repository *notificationRepositoryImpl) FindByStoreId(store_id uint) ([]entities.Notification, error) {
+	var notifications []entities.Notification
+	err := repository.database.Where("id_toko = ?", store_id).Order("id desc").Find(&notifications).Error
+
+	if err != nil {
+		return notifications, err
+	}
+
+	return notifications, nil
+}
+
+// FindByRecipient returns the user's own notifications together with those
+// of their store, if they have one.
+func (repository *notificationRepositoryImpl) FindByRecipient(user_id uint, store_id *uint) ([]entities.Notification, error) {
+	var notifications []entities.Notification
+	query := repository.database.Where("id_user = ?", user_id)
+	if store_id != nil {
+		query = repository.database.Where("id_user = ? OR id_toko = ?", user_id, *store_id)
+	}
+	err := query.Order("id desc").Find(&notifications).Error
+
+	if err != nil {
+		return notifications, err
+	}
+
+	return notifications, nil
+}
+
+func (repository *notificationRepositoryImpl) FindById(id uint) (entities.Notification, error) {
+	var notification entities.Notification
+	err := repository.database.Where("id = ?", id).First(&notification).Error
+
+	if err != nil {
+		return notification, err
+	}
+
+	return notification, nil
+}
+
+func (repository *notificationRepositoryImpl) Insert(notification entities.Notification) (entities.Notification, error) {
+	err := repository.database.Create(&notification).Error
+	if err != nil {
+		return entities.Notification{}, err
+	}
+	return notification, nil
+}
+
+func (repository *notificationRepositoryImpl) MarkAsRead(id uint) (bool, error) {
+	err := repository.database.Model(&entities.Notification{}).
+		Where("id = ? AND dibaca_pada IS NULL", id).
+		Update("dibaca_pada", time.Now()).Error
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
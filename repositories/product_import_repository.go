@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type ProductImportRepository interface {
+	FindById(id uint) (entities.ProductImport, error)
+	Insert(job entities.ProductImport) (entities.ProductImport, error)
+	Update(job entities.ProductImport) error
+	FailUnfinished(message string, failed_at time.Time) (int64, error)
+}
+
+type productImportRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewProductImportRepository(database *gorm.DB) ProductImportRepository {
+	return &productImportRepositoryImpl{database}
+}
+
+func (repository *productImportRepositoryImpl) FindById(id uint) (entities.ProductImport, error) {
+	var job entities.ProductImport
+	err := repository.database.Where("id = ?", id).First(&job).Error
+
+	if err != nil {
+		return job, err
+	}
+
+	return job, nil
+}
+
+func (repository *productImportRepositoryImpl) Insert(job entities.ProductImport) (entities.ProductImport, error) {
+	err := repository.database.Create(&job).Error
+	if err != nil {
+		return entities.ProductImport{}, err
+	}
+	return job, nil
+}
+
+func (repository *productImportRepositoryImpl) Update(job entities.ProductImport) error {
+	return repository.database.Save(&job).Error
+}
+
+// FailUnfinished marks every queued or running job failed with message
+func (repository *productImportRepositoryImpl) FailUnfinished(message string, failed_at time.Time) (int64, error) {
+	result := repository.database.Model(&entities.ProductImport{}).
+		Where("status IN ?", []string{models.ImportQueued, models.ImportRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.ImportFailed,
+			"pesan":        message,
+			"selesai_pada": failed_at,
+		})
+	return result.RowsAffected, result.Error
+}
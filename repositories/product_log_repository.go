@@ -1,18 +1,15 @@
 package repositories
 
 import (
-	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
 
 	"gorm.io/gorm"
 )
 
 type ProductLogRepository interface {
-	Insert(input models.ProductLogProcess) (entities.ProductLog, error)
 	FindAll() ([]entities.ProductLog, error)
 	FindById(id uint) (entities.ProductLog, error)
-	Update(id uint, input models.ProductLogProcess) (entities.ProductLog, error)
-	Delete(id uint) error
+	FindByProduct(product_id uint) ([]entities.ProductLog, error)
 }
 
 type productLogRepositoryImpl struct {
@@ -23,26 +20,6 @@ func NewProductLogRepository(db *gorm.DB) ProductLogRepository {
 	return &productLogRepositoryImpl{db}
 }
 
-func (repository *productLogRepositoryImpl) Insert(input models.ProductLogProcess) (entities.ProductLog, error) {
-	productLog := entities.ProductLog{
-		IDProduk:      input.ProductID,
-		NamaProduk:    input.NamaProduk,
-		Slug:          input.Slug,
-		HargaReseller: input.HargaReseller,
-		HargaKonsumen: input.HargaKonsumen,
-		Deskripsi:     &input.Deskripsi,
-		IDToko:        input.StoreID,
-		IDCategory:    input.CategoryID,
-	}
-
-	err := repository.db.Create(&productLog).Error
-	if err != nil {
-		return entities.ProductLog{}, err
-	}
-
-	return productLog, nil
-}
-
 // Add new method
 func (repository *productLogRepositoryImpl) FindAll() ([]entities.ProductLog, error) {
 	var productLogs []entities.ProductLog
@@ -63,48 +40,72 @@ func (repository *productLogRepositoryImpl) FindById(id uint) (entities.ProductL
 	return productLog, nil
 }
 
-// Add new method
-func (repository *productLogRepositoryImpl) Update(id uint, input models.ProductLogProcess) (entities.ProductLog, error) {
-	// Try to find the record first
-	var productLog entities.ProductLog
-	if err := repository.db.Table("log_produk").Where("id = ?", id).First(&productLog).Error; err != nil {
-		return entities.ProductLog{}, err
+// FindByProduct returns a product's snapshots, oldest first.
+func (repository *productLogRepositoryImpl) FindByProduct(product_id uint) ([]entities.ProductLog, error) {
+	var productLogs []entities.ProductLog
+	err := repository.db.
+		Where("id_produk = ?", product_id).
+		Order("id asc").
+		Find(&productLogs).Error
+	if err != nil {
+		return nil, err
 	}
+	return productLogs, nil
+}
 
-	// Prepare update data
-	updates := entities.ProductLog{
-		IDProduk:      input.ProductID,
-		NamaProduk:    input.NamaProduk,
-		Slug:          input.Slug,
-		HargaReseller: input.HargaReseller,
-		HargaKonsumen: input.HargaKonsumen,
-		Deskripsi:     &input.Deskripsi,
-		IDToko:        input.StoreID,
-		IDCategory:    input.CategoryID,
+// Product logs are append-only snapshots. appendProductLog writes one unless
+// it matches the product's latest snapshot, in which case that one is
+// returned so unchanged products share a single row.
+func appendProductLog(database *gorm.DB, snapshot entities.ProductLog) (entities.ProductLog, error) {
+	var latest entities.ProductLog
+	err := database.
+		Where("id_produk = ?", snapshot.IDProduk).
+		Order("id desc").
+		Limit(1).
+		Find(&latest).Error
+	if err != nil {
+		return entities.ProductLog{}, err
 	}
 
-	// Perform update
-	if err := repository.db.Table("log_produk").Where("id = ?", id).Updates(&updates).Error; err != nil {
-		return entities.ProductLog{}, err
+	if latest.ID != 0 && sameSnapshot(latest, snapshot) {
+		return latest, nil
 	}
 
-	// Fetch updated record
-	var updatedLog entities.ProductLog
-	if err := repository.db.Table("log_produk").Where("id = ?", id).First(&updatedLog).Error; err != nil {
+	if err := database.Create(&snapshot).Error; err != nil {
 		return entities.ProductLog{}, err
 	}
 
-	return updatedLog, nil
+	return snapshot, nil
 }
 
-// Add new method
-func (repository *productLogRepositoryImpl) Delete(id uint) error {
-	result := repository.db.Table("log_produk").Where("id = ?", id).Delete(&entities.ProductLog{})
-	if result.Error != nil {
-		return result.Error
+func sameSnapshot(a entities.ProductLog, b entities.ProductLog) bool {
+	deskripsi := func(log entities.ProductLog) string {
+		if log.Deskripsi == nil {
+			return ""
+		}
+		return *log.Deskripsi
 	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+
+	return a.IDProduk == b.IDProduk &&
+		a.NamaProduk == b.NamaProduk &&
+		a.Slug == b.Slug &&
+		a.HargaReseller == b.HargaReseller &&
+		a.HargaKonsumen == b.HargaKonsumen &&
+		deskripsi(a) == deskripsi(b) &&
+		a.IDToko == b.IDToko &&
+		a.IDCategory == b.IDCategory
+}
+
+// productSnapshot is the log entry describing product as it is now.
+func productSnapshot(product entities.Product) entities.ProductLog {
+	return entities.ProductLog{
+		IDProduk:      product.ID,
+		NamaProduk:    product.NamaProduk,
+		Slug:          product.Slug,
+		HargaReseller: product.HargaReseller,
+		HargaKonsumen: product.HargaKonsumen,
+		Deskripsi:     product.Deskripsi,
+		IDToko:        product.IDToko,
+		IDCategory:    product.IDCategory,
 	}
-	return nil
 }
@@ -1,17 +1,23 @@
 package repositories
 
 import (
+	"errors"
 	"mini-project-evermos/models/entities"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Contract
 type ProductPictureRepository interface {
+	FindById(id uint) (entities.ProductPicture, error)
 	FindByProductId(product_id uint) ([]entities.ProductPicture, error)
-	Create(productPicture *entities.ProductPicture) error
-	GetByID(id uint) (*entities.ProductPicture, error)
-	VerifyProductExists(productID uint) bool
+	Create(picture entities.ProductPicture) (entities.ProductPicture, error)
+	CreateMany(product_id uint, pictures []entities.ProductPicture) ([]entities.ProductPicture, error)
+	Update(picture entities.ProductPicture) (entities.ProductPicture, error)
+	Delete(id uint) error
+	Reorder(product_id uint, ids []uint) error
+	SetPrimary(id uint) error
 }
 
 type productPictureRepositoryImpl struct {
@@ -22,34 +28,230 @@ func NewProductPictureRepository(database *gorm.DB) ProductPictureRepository {
 	return &productPictureRepositoryImpl{database}
 }
 
+// picturesInOrder sorts preloaded photos cover first, then by position
+func picturesInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("foto_produk.utama desc, foto_produk.posisi asc, foto_produk.id asc")
+}
+
+func (repository *productPictureRepositoryImpl) FindById(id uint) (entities.ProductPicture, error) {
+	var picture entities.ProductPicture
+	err := repository.database.First(&picture, id).Error
+	return picture, err
+}
+
 func (repository *productPictureRepositoryImpl) FindByProductId(product_id uint) ([]entities.ProductPicture, error) {
-	var product []entities.ProductPicture
+	var pictures []entities.ProductPicture
+	err := picturesInOrder(repository.database).Where("id_produk = ?", product_id).Find(&pictures).Error
+	return pictures, err
+}
+
+// Create appends the photo after the product's others. The first photo of a
+// product becomes its cover.
+func (repository *productPictureRepositoryImpl) Create(picture entities.ProductPicture) (entities.ProductPicture, error) {
+	pictures, err := repository.CreateMany(picture.IDProduk, []entities.ProductPicture{picture})
+	if err != nil {
+		return entities.ProductPicture{}, err
+	}
+	return pictures[0], nil
+}
+
+// CreateMany appends the photos, in order, after the product's others in one
+// transaction, so either all of them are added or none
+func (repository *productPictureRepositoryImpl) CreateMany(product_id uint, pictures []entities.ProductPicture) ([]entities.ProductPicture, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, product_id); err != nil {
+			return err
+		}
 
-	err := repository.database.Where("id_produk = ?", product_id).Find(&product).Error
+		var count int64
+		if err := tx.Model(&entities.ProductPicture{}).Where("id_produk = ?", product_id).Count(&count).Error; err != nil {
+			return err
+		}
 
+		var last int
+		if err := tx.Model(&entities.ProductPicture{}).Where("id_produk = ?", product_id).
+			Select("COALESCE(MAX(posisi), -1)").Scan(&last).Error; err != nil {
+			return err
+		}
+
+		for i := range pictures {
+			pictures[i].IDProduk = product_id
+			pictures[i].Posisi = last + 1 + i
+			pictures[i].Utama = count == 0 && i == 0
+		}
+		return tx.Create(&pictures).Error
+	})
+
+	return pictures, err
+}
+
+func (repository *productPictureRepositoryImpl) Update(picture entities.ProductPicture) (entities.ProductPicture, error) {
+	err := repository.database.Model(&picture).
+		Updates(map[string]interface{}{"url": picture.Url, "alt_text": picture.AltText}).Error
 	if err != nil {
-		return product, err
+		return entities.ProductPicture{}, err
 	}
 
-	return product, nil
+	return repository.FindById(picture.ID)
 }
 
-func (repository *productPictureRepositoryImpl) Create(productPicture *entities.ProductPicture) error {
-	result := repository.database.Create(productPicture)
-	return result.Error
+// Delete trashes the photo, its file goes with the trash purge, and closes
+// the gap in the positions. When it was the cover the next photo takes over.
+func (repository *productPictureRepositoryImpl) Delete(id uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var picture entities.ProductPicture
+		if err := tx.First(&picture, id).Error; err != nil {
+			return err
+		}
+		if err := lockProduct(tx, picture.IDProduk); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&picture).Error; err != nil {
+			return err
+		}
+
+		var remaining []entities.ProductPicture
+		if err := picturesInOrder(tx).Where("id_produk = ?", picture.IDProduk).Find(&remaining).Error; err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+
+		if picture.Utama {
+			remaining[0].Utama = true
+		}
+		return renumberPictures(tx, remaining)
+	})
 }
 
-func (repository *productPictureRepositoryImpl) GetByID(id uint) (*entities.ProductPicture, error) {
-	var productPicture entities.ProductPicture
-	result := repository.database.First(&productPicture, id)
-	if result.Error != nil {
-		return nil, result.Error
+// Reorder sets the positions to the order of ids, which must list every
+// photo of the product once.
+func (repository *productPictureRepositoryImpl) Reorder(product_id uint, ids []uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, product_id); err != nil {
+			return err
+		}
+
+		var pictures []entities.ProductPicture
+		if err := tx.Where("id_produk = ?", product_id).Find(&pictures).Error; err != nil {
+			return err
+		}
+
+		by_id := map[uint]entities.ProductPicture{}
+		for _, picture := range pictures {
+			by_id[picture.ID] = picture
+		}
+		if len(ids) != len(pictures) {
+			return errors.New("ids must list every photo of the product")
+		}
+
+		ordered := []entities.ProductPicture{}
+		for _, id := range ids {
+			picture, ok := by_id[id]
+			if !ok {
+				return errors.New("ids must list every photo of the product")
+			}
+			delete(by_id, id)
+			ordered = append(ordered, picture)
+		}
+
+		return renumberPictures(tx, ordered)
+	})
+}
+
+// SetPrimary makes the photo the cover of its product
+func (repository *productPictureRepositoryImpl) SetPrimary(id uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var picture entities.ProductPicture
+		if err := tx.First(&picture, id).Error; err != nil {
+			return err
+		}
+		if err := lockProduct(tx, picture.IDProduk); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entities.ProductPicture{}).
+			Where("id_produk = ? AND id <> ?", picture.IDProduk, id).
+			Update("utama", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&picture).Update("utama", true).Error
+	})
+}
+
+// lockProduct serializes photo changes of one product so positions and the
+// cover flag stay consistent
+func lockProduct(tx *gorm.DB, product_id uint) error {
+	var product entities.Product
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&product, product_id).Error
+}
+
+func renumberPictures(tx *gorm.DB, pictures []entities.ProductPicture) error {
+	for i, picture := range pictures {
+		err := tx.Model(&entities.ProductPicture{}).Where("id = ?", picture.ID).
+			Updates(map[string]interface{}{"posisi": i, "utama": picture.Utama}).Error
+		if err != nil {
+			return err
+		}
 	}
-	return &productPicture, nil
+	return nil
 }
 
-func (repository *productPictureRepositoryImpl) VerifyProductExists(productID uint) bool {
-	var count int64
-	repository.database.Model(&entities.Product{}).Where("id = ?", productID).Count(&count)
-	return count > 0
+// replaceProductPictures swaps the photos of a product for urls, in order.
+// Photos whose url stays keep their alt text and, if still there, the cover
+// stays the cover; the others are trashed.
+func replaceProductPictures(tx *gorm.DB, product_id uint, urls []string) error {
+	var current []entities.ProductPicture
+	if err := tx.Where("id_produk = ?", product_id).Find(&current).Error; err != nil {
+		return err
+	}
+
+	alt_texts := map[string]string{}
+	cover := ""
+	for _, picture := range current {
+		alt_texts[picture.Url] = picture.AltText
+		if picture.Utama {
+			cover = picture.Url
+		}
+	}
+
+	if len(current) > 0 {
+		if err := tx.Where("id_produk = ?", product_id).Delete(&entities.ProductPicture{}).Error; err != nil {
+			return err
+		}
+	}
+
+	kept := []string{}
+	has_cover := false
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		kept = append(kept, url)
+		if url == cover {
+			has_cover = true
+		}
+	}
+
+	for i, url := range kept {
+		picture := entities.ProductPicture{
+			IDProduk: product_id,
+			Url:      url,
+			Posisi:   i,
+			Utama:    url == cover || (!has_cover && i == 0),
+			AltText:  alt_texts[url],
+		}
+		if picture.Utama {
+			// A url listed twice only makes the first one the cover
+			has_cover, cover = true, ""
+		}
+		if err := tx.Create(&picture).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -6,18 +6,38 @@ import (
 	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
 	"mini-project-evermos/models/responder"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gosimple/slug"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
-	FindAllPagination(pagination responder.Pagination) (responder.Pagination, error)
+	FindAllPagination(pagination responder.Pagination, filter models.ProductFilter) (responder.Pagination, error)
 	FindById(id uint) (entities.Product, error)
+	FindBySlug(slug string) (entities.Product, error)
+	FindBySKU(store_id uint, sku string) (entities.Product, error)
+	FindSlugHistory(slug string) (entities.ProductSlugHistory, error)
 	Insert(product models.ProductRequest) (entities.Product, error)
 	Update(product models.ProductRequest, id uint) (bool, error)
 	Destroy(id uint) (bool, error)
+	FindTrashPagination(pagination responder.Pagination, store_id uint) (responder.Pagination, error)
+	FindTrashedById(id uint) (entities.Product, error)
+	Restore(id uint) error
+	FindPurgeable(deleted_before time.Time) ([]entities.Product, error)
+	Purge(id uint) ([]string, error)
+	PurgePictures(deleted_before time.Time) ([]string, error)
+	FindLowStock() ([]entities.Product, error)
+	UpdateStatus(id uint, status string, alasan *string, user_id *uint) error
+	FindModerationHistory(product_id uint) ([]entities.ProductModeration, error)
+	FindAll() ([]entities.Product, error)
+	FindByStoreInBatches(store_id uint, size int, fn func(products []entities.Product) error) error
+	FindSoldCounts() (map[uint]int64, error)
+	Facets(pagination responder.Pagination, filter models.ProductFilter) (models.ProductFacets, error)
 }
 
 type productRepositoryImpl struct {
@@ -28,26 +48,55 @@ func NewProductRepository(database *gorm.DB) ProductRepository {
 	return &productRepositoryImpl{database}
 }
 
-func (repository *productRepositoryImpl) FindAllPagination(request responder.Pagination) (responder.Pagination, error) {
+// Prices are stored as strings, so numeric filtering and sorting cast them.
+const productPrice = "CAST(produk.harga_konsumen AS UNSIGNED)"
+
+const productSold = `(SELECT COALESCE(SUM(detail_trx.kuantitas), 0)
+	FROM detail_trx
+	JOIN log_produk ON log_produk.id = detail_trx.id_log_produk
+	WHERE log_produk.id_produk = produk.id AND detail_trx.deleted_at IS NULL)`
+
+// Unrated products have a NULL average and sort after rated ones.
+const productRating = "produk.total_rating / NULLIF(produk.jumlah_ulasan, 0)"
+
+// Upper bounds of the price facet buckets; the last bucket is open ended.
+var priceBuckets = []int{50000, 100000, 250000, 500000}
+
+func (repository *productRepositoryImpl) FindAllPagination(request responder.Pagination, filter models.ProductFilter) (responder.Pagination, error) {
 	var products []entities.Product
 	var totalRows int64
 
 	// Count total rows
-	query := repository.database.Model(&entities.Product{})
-	if request.Keyword != "" {
-		query = query.Where("nama_produk LIKE ?", "%"+request.Keyword+"%")
-	}
+	query := repository.filter(repository.database.Model(&entities.Product{}), request, filter)
 	query.Count(&totalRows)
 
 	// Update preload to ensure complete data loading
-	query = repository.database.Model(&entities.Product{}).
+	query = repository.filter(repository.database.Model(&entities.Product{}), request, filter).
 		Preload("Store").
 		Preload("Category").
-		Preload("ProductPicture").
-		Order("id desc")
+		Preload("ProductPicture", picturesInOrder)
 
-	if request.Keyword != "" {
-		query = query.Where("nama_produk LIKE ?", "%"+request.Keyword+"%")
+	switch request.Sort {
+	case models.ProductSortRelevance:
+		if len(filter.IDs) > 0 {
+			query = query.Clauses(clause.OrderBy{
+				Expression: clause.Expr{SQL: "FIELD(produk.id, ?)", Vars: []interface{}{filter.IDs}, WithoutParentheses: true},
+			})
+		} else {
+			query = query.Order("produk.id desc")
+		}
+	case models.ProductSortPriceAsc:
+		query = query.Order(productPrice + " asc").Order("produk.id desc")
+	case models.ProductSortPriceDesc:
+		query = query.Order(productPrice + " desc").Order("produk.id desc")
+	case models.ProductSortBestSelling:
+		query = query.Order(productSold + " desc").Order("produk.id desc")
+	case models.ProductSortRating:
+		query = query.Order(productRating + " desc").Order("produk.jumlah_ulasan desc").Order("produk.id desc")
+	case models.ProductSortOldest:
+		query = query.Order("produk.id asc")
+	default:
+		query = query.Order("produk.id desc")
 	}
 
 	err := query.
@@ -59,45 +108,9 @@ func (repository *productRepositoryImpl) FindAllPagination(request responder.Pag
 		return responder.Pagination{}, err
 	}
 
-	var responses []models.ProductResponse
+	responses := []models.ProductResponse{}
 	for _, product := range products {
-		response := models.ProductResponse{
-			ID:            product.ID,
-			NamaProduk:    product.NamaProduk,
-			Slug:          product.Slug,
-			HargaReseller: product.HargaReseller,
-			HargaKonsumen: product.HargaKonsumen,
-			Stok:          product.Stok,
-			Deskripsi:     product.Deskripsi,
-			CreatedAt:     product.CreatedAt,
-			UpdatedAt:     product.UpdatedAt,
-			Store: models.StoreResponse{
-				ID:        product.Store.ID,
-				NamaToko:  product.Store.NamaToko,
-				UrlFoto:   product.Store.UrlFoto,
-				CreatedAt: product.Store.CreatedAt,
-				UpdatedAt: product.Store.UpdatedAt,
-			},
-			Category: models.CategoryResponse{
-				ID:           product.Category.ID,
-				NamaCategory: product.Category.NamaCategory,
-				CreatedAt:    product.Category.CreatedAt,
-				UpdatedAt:    product.Category.UpdatedAt,
-			},
-		}
-
-		var pictures []models.ProductPictureResponse
-		for _, pic := range product.ProductPicture {
-			pictures = append(pictures, models.ProductPictureResponse{
-				ID:        pic.ID,
-				IDProduk:  pic.IDProduk,
-				Url:       pic.Url,
-				CreatedAt: pic.CreatedAt,
-				UpdatedAt: pic.UpdatedAt,
-			})
-		}
-		response.Photos = pictures
-		responses = append(responses, response)
+		responses = append(responses, models.ToProductResponse(product))
 	}
 
 	request.Rows = responses
@@ -107,6 +120,122 @@ func (repository *productRepositoryImpl) FindAllPagination(request responder.Pag
 	return request, nil
 }
 
+// Facets counts matching products per category and per price bucket. Each
+// facet ignores its own filter so clients can show the alternatives.
+func (repository *productRepositoryImpl) Facets(request responder.Pagination, filter models.ProductFilter) (models.ProductFacets, error) {
+	facets := models.ProductFacets{
+		Categories: []models.CategoryFacet{},
+		Prices:     []models.PriceFacet{},
+	}
+
+	categoryFilter := filter
+	categoryFilter.CategoryID = 0
+
+	err := repository.filter(repository.database.Model(&entities.Product{}), request, categoryFilter).
+		Select("produk.id_category AS category_id, category.nama_category AS nama_category, COUNT(*) AS count").
+		Joins("JOIN category ON category.id = produk.id_category").
+		Group("produk.id_category, category.nama_category").
+		Order("count desc").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+
+	priceFilter := filter
+	priceFilter.MinPrice = nil
+	priceFilter.MaxPrice = nil
+
+	columns := []string{}
+	lower := 0
+	for i, upper := range priceBuckets {
+		columns = append(columns, fmt.Sprintf("COALESCE(SUM(CASE WHEN %s >= %d AND %s < %d THEN 1 ELSE 0 END), 0) AS b%d", productPrice, lower, productPrice, upper, i))
+		lower = upper
+	}
+	columns = append(columns, fmt.Sprintf("COALESCE(SUM(CASE WHEN %s >= %d THEN 1 ELSE 0 END), 0) AS b%d", productPrice, lower, len(priceBuckets)))
+
+	counts := map[string]interface{}{}
+	err = repository.filter(repository.database.Model(&entities.Product{}), request, priceFilter).
+		Select(strings.Join(columns, ", ")).
+		Take(&counts).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+
+	lower = 0
+	for i := 0; i <= len(priceBuckets); i++ {
+		bucket := models.PriceFacet{Min: lower, Count: toInt64(counts[fmt.Sprintf("b%d", i)])}
+		if i < len(priceBuckets) {
+			upper := priceBuckets[i]
+			bucket.Max = &upper
+			lower = upper
+		}
+		facets.Prices = append(facets.Prices, bucket)
+	}
+
+	return facets, nil
+}
+
+func (repository *productRepositoryImpl) filter(query *gorm.DB, request responder.Pagination, filter models.ProductFilter) *gorm.DB {
+	if request.Keyword != "" {
+		query = query.Where("produk.nama_produk LIKE ?", "%"+request.Keyword+"%")
+	}
+	if filter.IDs != nil {
+		if len(filter.IDs) == 0 {
+			query = query.Where("1 = 0")
+		} else {
+			query = query.Where("produk.id IN ?", filter.IDs)
+		}
+	}
+	if filter.StoreID != 0 {
+		query = query.Where("produk.id_toko = ?", filter.StoreID)
+	}
+	if filter.Status != "" {
+		query = query.Where("produk.status = ?", filter.Status)
+	}
+	if filter.CategoryID != 0 && filter.IncludeDescendants {
+		query = query.Where(`produk.id_category IN (SELECT sub.id FROM category sub
+			JOIN category root ON sub.path LIKE CONCAT(root.path, '%')
+			WHERE root.id = ? AND sub.deleted_at IS NULL)`, filter.CategoryID)
+	} else if filter.CategoryID != 0 {
+		query = query.Where("produk.id_category = ?", filter.CategoryID)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where(productPrice+" >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(productPrice+" <= ?", *filter.MaxPrice)
+	}
+	if filter.InStock {
+		query = query.Where("produk.stok > 0")
+	}
+	if filter.CityID != "" {
+		// Stores ship from their own city, or from the owner's when unset
+		query = query.Where("produk.id_toko IN (SELECT toko.id FROM toko JOIN `user` ON `user`.id = toko.id_user WHERE toko.id_kota = ? OR (toko.id_kota = '' AND `user`.id_kota = ?))", filter.CityID, filter.CityID)
+	}
+	return query
+}
+
+// toInt64 normalises the driver-specific types returned for aggregate columns.
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case []byte:
+		n, _ := strconv.ParseInt(string(v), 10, 64)
+		return n
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
 func (repository *productRepositoryImpl) FindById(id uint) (entities.Product, error) {
 	var product entities.Product
 
@@ -121,8 +250,9 @@ func (repository *productRepositoryImpl) FindById(id uint) (entities.Product, er
 			return db.Table("category") // explicitly use category table
 		}).
 		Preload("ProductPicture", func(db *gorm.DB) *gorm.DB {
-			return db.Table("foto_produk") // explicitly use foto_produk table
+			return picturesInOrder(db.Table("foto_produk")) // explicitly use foto_produk table
 		}).
+		Preload("Attributes").
 		Where("id = ?", id).
 		First(&product)
 
@@ -135,6 +265,88 @@ func (repository *productRepositoryImpl) FindById(id uint) (entities.Product, er
 	return product, nil
 }
 
+func (repository *productRepositoryImpl) FindBySlug(slug string) (entities.Product, error) {
+	var product entities.Product
+
+	err := repository.database.
+		Preload("Store").
+		Preload("Category").
+		Preload("ProductPicture", picturesInOrder).
+		Preload("Attributes").
+		Where("slug = ?", slug).
+		First(&product).Error
+
+	if err != nil {
+		return entities.Product{}, err
+	}
+
+	return product, nil
+}
+
+func (repository *productRepositoryImpl) FindBySKU(store_id uint, sku string) (entities.Product, error) {
+	var product entities.Product
+
+	err := repository.database.
+		Preload("ProductPicture", picturesInOrder).
+		Preload("Attributes").
+		Where("id_toko = ? AND sku = ?", store_id, sku).
+		First(&product).Error
+
+	if err != nil {
+		return entities.Product{}, err
+	}
+
+	return product, nil
+}
+
+func (repository *productRepositoryImpl) FindSlugHistory(slug string) (entities.ProductSlugHistory, error) {
+	var history entities.ProductSlugHistory
+	err := repository.database.Where("slug = ?", slug).First(&history).Error
+
+	if err != nil {
+		return history, err
+	}
+
+	return history, nil
+}
+
+// uniqueSlug builds a slug from name that is not used by any other product,
+// current or historical, by appending -2, -3, ... on collisions.
+func (repository *productRepositoryImpl) uniqueSlug(database *gorm.DB, name string, product_id uint) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "produk"
+	}
+
+	var taken []string
+	err := database.Unscoped().Model(&entities.Product{}).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, base+"-%", product_id).
+		Pluck("slug", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	var history []string
+	err = database.Model(&entities.ProductSlugHistory{}).
+		Where("(slug = ? OR slug LIKE ?) AND id_produk <> ?", base, base+"-%", product_id).
+		Pluck("slug", &history).Error
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]bool{}
+	for _, value := range append(taken, history...) {
+		used[value] = true
+	}
+
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return candidate, nil
+}
+
 func (repository *productRepositoryImpl) Insert(input models.ProductRequest) (entities.Product, error) {
 	now := time.Now()
 	product := entities.Product{
@@ -143,27 +355,51 @@ func (repository *productRepositoryImpl) Insert(input models.ProductRequest) (en
 		IDCategory:    input.CategoryID,
 		HargaReseller: input.HargaReseller,
 		HargaKonsumen: input.HargaKonsumen,
-		Stok:          input.Stok,
 		Deskripsi:     &input.Deskripsi,
-		Slug:          slug.Make(input.NamaProduk),
+		Status:        input.Status,
 		CreatedAt:     &now,
 		UpdatedAt:     &now,
 	}
 
-	err := repository.database.Create(&product).Error
-	if err != nil {
-		return entities.Product{}, err
+	if input.Stok != nil {
+		product.Stok = *input.Stok
+	}
+	if input.StokMinimum != nil {
+		product.StokMinimum = *input.StokMinimum
+	}
+	if input.SKU != "" {
+		product.SKU = &input.SKU
 	}
 
-	for _, url := range input.PhotoURLs {
-		picture := entities.ProductPicture{
-			IDProduk: product.ID,
-			Url:      url,
-		}
-		err = repository.database.Create(&picture).Error
+	// One statement failing must not leave a half created product behind
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		unique_slug, err := repository.uniqueSlug(tx, input.NamaProduk, 0)
 		if err != nil {
-			return entities.Product{}, err
+			return err
 		}
+		product.Slug = unique_slug
+
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+
+		if err := replaceProductPictures(tx, product.ID, input.PhotoURLs); err != nil {
+			return err
+		}
+
+		if err := replaceProductAttributes(tx, product.ID, input.Attributes); err != nil {
+			return err
+		}
+
+		if err := recordStatus(tx, product.ID, product.Status, nil, nil); err != nil {
+			return err
+		}
+
+		_, err = appendProductLog(tx, productSnapshot(product))
+		return err
+	})
+	if err != nil {
+		return entities.Product{}, err
 	}
 
 	return product, nil
@@ -171,42 +407,125 @@ func (repository *productRepositoryImpl) Insert(input models.ProductRequest) (en
 
 func (repository *productRepositoryImpl) Update(product models.ProductRequest, id uint) (bool, error) {
 	tx := repository.database.Begin()
+
+	var current entities.Product
+	if err := tx.Where("id = ?", id).First(&current).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	new_slug := current.Slug
+	if product.NamaProduk != "" && slug.Make(product.NamaProduk) != slug.Make(current.NamaProduk) {
+		generated, err := repository.uniqueSlug(tx, product.NamaProduk, id)
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		new_slug = generated
+	}
+
+	if new_slug != current.Slug {
+		// Keep the old slug so links to it can be redirected, and release the
+		// new one from history in case the product is renamed back.
+		if err := tx.Where("id_produk = ? AND slug = ?", id, new_slug).Delete(&entities.ProductSlugHistory{}).Error; err != nil {
+			tx.Rollback()
+			return false, err
+		}
+		if err := tx.Create(&entities.ProductSlugHistory{IDProduk: id, Slug: current.Slug}).Error; err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
 	update_product := &entities.Product{
 		NamaProduk:    product.NamaProduk,
-		Slug:          slug.Make(product.NamaProduk),
+		Slug:          new_slug,
 		HargaReseller: product.HargaReseller,
 		HargaKonsumen: product.HargaKonsumen,
-		Stok:          product.Stok,
-		Deskripsi:     &product.Deskripsi,
 		IDCategory:    product.CategoryID,
 		IDToko:        product.StoreID,
 	}
+	if product.Deskripsi != "" {
+		update_product.Deskripsi = &product.Deskripsi
+	}
+	if product.SKU != "" {
+		update_product.SKU = &product.SKU
+	}
+	if product.Status != "" && product.Status != current.Status {
+		update_product.Status = product.Status
+		if err := recordStatus(tx, id, product.Status, nil, nil); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
 
 	if err := tx.Where("id = ?", id).Updates(update_product).Error; err != nil {
 		tx.Rollback()
 		return false, err
 	}
 
-	if err := tx.Where("id_produk = ?", id).Delete(&entities.ProductPicture{}).Error; err != nil {
-		tx.Rollback()
-		return false, err
+	// Updates skips zero values, so write stock columns explicitly to allow 0
+	stock := map[string]interface{}{}
+	if product.Stok != nil {
+		stock["stok"] = *product.Stok
+	}
+	if product.StokMinimum != nil {
+		stock["stok_minimum"] = *product.StokMinimum
+	}
+	if len(stock) > 0 {
+		if err := tx.Model(&entities.Product{}).Where("id = ?", id).Updates(stock).Error; err != nil {
+			tx.Rollback()
+			return false, err
+		}
 	}
 
-	for _, photo := range product.PhotoURLs {
-		productPicture := entities.ProductPicture{
-			IDProduk: id,
-			Url:      photo,
+	if product.PhotoURLs != nil {
+		if err := replaceProductPictures(tx, id, product.PhotoURLs); err != nil {
+			tx.Rollback()
+			return false, err
 		}
-		if err := tx.Create(&productPicture).Error; err != nil {
+	}
+
+	if product.Attributes != nil {
+		if err := replaceProductAttributes(tx, id, product.Attributes); err != nil {
 			tx.Rollback()
 			return false, err
 		}
 	}
 
+	var updated entities.Product
+	if err := tx.Where("id = ?", id).First(&updated).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if _, err := appendProductLog(tx, productSnapshot(updated)); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
 	tx.Commit()
 	return true, nil
 }
 
+func replaceProductAttributes(database *gorm.DB, product_id uint, values map[string]string) error {
+	if err := database.Where("id_produk = ?", product_id).Delete(&entities.ProductAttribute{}).Error; err != nil {
+		return err
+	}
+
+	for kode, nilai := range values {
+		attribute := entities.ProductAttribute{
+			IDProduk: product_id,
+			Kode:     kode,
+			Nilai:    nilai,
+		}
+		if err := database.Create(&attribute).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (repository *productRepositoryImpl) Destroy(id uint) (bool, error) {
 	err := repository.database.Where("id = ?", id).Delete(&entities.Product{}).Error
 	if err != nil {
@@ -214,3 +533,293 @@ func (repository *productRepositoryImpl) Destroy(id uint) (bool, error) {
 	}
 	return true, nil
 }
+
+// productLogged matches products with snapshots in log_produk. The log is
+// append-only and keeps the price history, so these products are never
+// purged, whether or not a transaction points at a snapshot.
+const productLogged = `EXISTS (SELECT 1 FROM log_produk WHERE log_produk.id_produk = produk.id)`
+
+func (repository *productRepositoryImpl) FindTrashPagination(request responder.Pagination, store_id uint) (responder.Pagination, error) {
+	var products []entities.Product
+	var totalRows int64
+
+	query := repository.database.Unscoped().Model(&entities.Product{}).
+		Where("produk.deleted_at IS NOT NULL AND produk.id_toko = ?", store_id)
+	if request.Keyword != "" {
+		query = query.Where("produk.nama_produk LIKE ?", "%"+request.Keyword+"%")
+	}
+	query.Count(&totalRows)
+
+	var logged, sold []uint
+	err := query.Session(&gorm.Session{}).
+		Preload("Store").
+		Preload("Category").
+		Preload("ProductPicture", picturesInOrder).
+		Preload("Attributes").
+		Order("produk.deleted_at desc").
+		Limit(request.Limit).
+		Offset(request.GetOffset()).
+		Find(&products).Error
+	if err == nil && len(products) > 0 {
+		ids := []uint{}
+		for _, product := range products {
+			ids = append(ids, product.ID)
+		}
+		err = repository.database.Table("log_produk").
+			Where("id_produk IN ?", ids).
+			Distinct().
+			Pluck("id_produk", &logged).Error
+	}
+	if err == nil && len(logged) > 0 {
+		err = repository.database.Table("log_produk").
+			Joins("JOIN detail_trx ON detail_trx.id_log_produk = log_produk.id").
+			Where("log_produk.id_produk IN ?", logged).
+			Distinct().
+			Pluck("log_produk.id_produk", &sold).Error
+	}
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	responses := []models.TrashedProductResponse{}
+	for _, product := range products {
+		deleted_at := product.DeletedAt.Time
+		responses = append(responses, models.TrashedProductResponse{
+			ProductResponse: models.ToProductResponse(product),
+			DeletedAt:       &deleted_at,
+			InTransactions:  slices.Contains(sold, product.ID),
+			HasHistory:      slices.Contains(logged, product.ID),
+		})
+	}
+
+	request.Rows = responses
+	request.TotalRows = totalRows
+	request.TotalPages = int(math.Ceil(float64(totalRows) / float64(request.Limit)))
+
+	return request, nil
+}
+
+func (repository *productRepositoryImpl) FindTrashedById(id uint) (entities.Product, error) {
+	var product entities.Product
+
+	err := repository.database.Unscoped().
+		Preload("Store").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&product).Error
+
+	if err != nil {
+		return entities.Product{}, err
+	}
+
+	return product, nil
+}
+
+func (repository *productRepositoryImpl) Restore(id uint) error {
+	return repository.database.Unscoped().Model(&entities.Product{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+// FindPurgeable returns products deleted before deleted_before that have
+// no price history.
+func (repository *productRepositoryImpl) FindPurgeable(deleted_before time.Time) ([]entities.Product, error) {
+	var products []entities.Product
+	err := repository.database.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deleted_before).
+		Where("NOT " + productLogged).
+		Order("id asc").
+		Find(&products).Error
+
+	if err != nil {
+		return products, err
+	}
+
+	return products, nil
+}
+
+// Purge hard-deletes a trashed product with the rows that belong to it and
+// returns the picture urls it had. Products with snapshots in log_produk are
+// refused, see productLogged.
+func (repository *productRepositoryImpl) Purge(id uint) ([]string, error) {
+	var urls []string
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		var product entities.Product
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			First(&product).Error
+		if err != nil {
+			return err
+		}
+
+		var logged int64
+		if err := tx.Model(&entities.ProductLog{}).Where("id_produk = ?", id).Count(&logged).Error; err != nil {
+			return err
+		}
+		if logged > 0 {
+			return fmt.Errorf("product %d has %d snapshots in its price history", id, logged)
+		}
+
+		if err := tx.Unscoped().Model(&entities.ProductPicture{}).Where("id_produk = ?", id).Pluck("url", &urls).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&entities.ProductPicture{},
+			&entities.ProductAttribute{},
+			&entities.ProductSlugHistory{},
+			&entities.ProductModeration{},
+			&entities.ProductSearchDocument{},
+			&entities.WishlistItem{},
+			&entities.ProductView{},
+		} {
+			if err := tx.Unscoped().Where("id_produk = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id_produk = ? OR id_terkait = ?", id, id).Delete(&entities.ProductRelation{}).Error; err != nil {
+			return err
+		}
+
+		// Notifications outlive the product they were about
+		if err := tx.Model(&entities.Notification{}).Where("id_produk = ?", id).Update("id_produk", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&entities.Product{}, id).Error
+	})
+
+	return urls, err
+}
+
+// PurgePictures hard-deletes pictures replaced before deleted_before and
+// returns their urls.
+func (repository *productRepositoryImpl) PurgePictures(deleted_before time.Time) ([]string, error) {
+	var urls []string
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		query := tx.Unscoped().Model(&entities.ProductPicture{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deleted_before)
+
+		if err := query.Session(&gorm.Session{}).Pluck("url", &urls).Error; err != nil {
+			return err
+		}
+
+		return query.Session(&gorm.Session{}).Delete(&entities.ProductPicture{}).Error
+	})
+
+	return urls, err
+}
+
+func (repository *productRepositoryImpl) FindLowStock() ([]entities.Product, error) {
+	var products []entities.Product
+	err := repository.database.
+		Where("stok_minimum > 0 AND stok <= stok_minimum").
+		Order("id_toko asc, stok asc").
+		Find(&products).Error
+
+	if err != nil {
+		return products, err
+	}
+
+	return products, nil
+}
+
+func (repository *productRepositoryImpl) FindAll() ([]entities.Product, error) {
+	var products []entities.Product
+	err := repository.database.
+		Preload("Store").
+		Preload("Category").
+		Find(&products).Error
+
+	if err != nil {
+		return products, err
+	}
+
+	return products, nil
+}
+
+// FindSoldCounts returns the units sold per product id.
+func (repository *productRepositoryImpl) FindSoldCounts() (map[uint]int64, error) {
+	var rows []struct {
+		IDProduk uint
+		Total    int64
+	}
+	err := repository.database.Table("detail_trx").
+		Select("log_produk.id_produk, COALESCE(SUM(detail_trx.kuantitas), 0) AS total").
+		Joins("JOIN log_produk ON log_produk.id = detail_trx.id_log_produk").
+		Where("detail_trx.deleted_at IS NULL").
+		Group("log_produk.id_produk").
+		Scan(&rows).Error
+
+	counts := map[uint]int64{}
+	if err != nil {
+		return counts, err
+	}
+
+	for _, row := range rows {
+		counts[row.IDProduk] = row.Total
+	}
+
+	return counts, nil
+}
+
+// FindByStoreInBatches walks a store's products in id order, handing fn one
+// batch at a time so exports do not hold the whole catalog in memory.
+func (repository *productRepositoryImpl) FindByStoreInBatches(store_id uint, size int, fn func(products []entities.Product) error) error {
+	var products []entities.Product
+	result := repository.database.
+		Preload("Category").
+		Preload("ProductPicture", picturesInOrder).
+		Preload("Attributes").
+		Where("id_toko = ?", store_id).
+		FindInBatches(&products, size, func(tx *gorm.DB, batch int) error {
+			return fn(products)
+		})
+
+	return result.Error
+}
+
+// UpdateStatus moves a product to status. Review outcomes are stamped with
+// the review time; alasan is kept as the rejection reason, nil clears it.
+func (repository *productRepositoryImpl) UpdateStatus(id uint, status string, alasan *string, user_id *uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		changes := map[string]interface{}{
+			"status":       status,
+			"alasan_tolak": alasan,
+		}
+		if status == models.ProductStatusPublished || status == models.ProductStatusRejected {
+			changes["ditinjau_pada"] = time.Now()
+		}
+
+		if err := tx.Model(&entities.Product{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+
+		return recordStatus(tx, id, status, alasan, user_id)
+	})
+}
+
+func (repository *productRepositoryImpl) FindModerationHistory(product_id uint) ([]entities.ProductModeration, error) {
+	var history []entities.ProductModeration
+	err := repository.database.
+		Where("id_produk = ?", product_id).
+		Order("id asc").
+		Find(&history).Error
+
+	if err != nil {
+		return history, err
+	}
+
+	return history, nil
+}
+
+func recordStatus(database *gorm.DB, product_id uint, status string, alasan *string, user_id *uint) error {
+	now := time.Now()
+	return database.Create(&entities.ProductModeration{
+		IDProduk:  product_id,
+		Status:    status,
+		Alasan:    alasan,
+		IDUser:    user_id,
+		CreatedAt: &now,
+	}).Error
+}
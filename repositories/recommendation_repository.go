@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type RecommendationRepository interface {
+	RecordView(user_id uint, product_id uint, at time.Time) error
+	FindRecentlyViewed(user_id uint, limit int) ([]entities.ProductView, error)
+	FindRelated(product_id uint, limit int) ([]entities.ProductRelation, error)
+	FindCoPurchases() ([]entities.ProductRelation, error)
+	ReplaceRelations(relations []entities.ProductRelation) error
+}
+
+type recommendationRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewRecommendationRepository(database *gorm.DB) RecommendationRepository {
+	return &recommendationRepositoryImpl{database}
+}
+
+// RecordView moves a product to the top of the user's history and drops the
+// views beyond models.RecentlyViewedLimit.
+func (repository *recommendationRepositoryImpl) RecordView(user_id uint, product_id uint, at time.Time) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id_user"}, {Name: "id_produk"}},
+			DoUpdates: clause.AssignmentColumns([]string{"dilihat_pada"}),
+		}).Create(&entities.ProductView{IDUser: user_id, IDProduk: product_id, DilihatPada: at}).Error
+		if err != nil {
+			return err
+		}
+
+		var keep []uint
+		err = tx.Model(&entities.ProductView{}).
+			Where("id_user = ?", user_id).
+			Order("dilihat_pada desc").
+			Limit(models.RecentlyViewedLimit).
+			Pluck("id", &keep).Error
+		if err != nil || len(keep) < models.RecentlyViewedLimit {
+			return err
+		}
+
+		return tx.Where("id_user = ? AND id NOT IN ?", user_id, keep).Delete(&entities.ProductView{}).Error
+	})
+}
+
+// FindRecentlyViewed returns the user's views of products still on sale,
+// latest first.
+func (repository *recommendationRepositoryImpl) FindRecentlyViewed(user_id uint, limit int) ([]entities.ProductView, error) {
+	var views []entities.ProductView
+	err := repository.database.
+		Where("id_user = ?", user_id).
+		Where("id_produk IN (SELECT id FROM produk WHERE deleted_at IS NULL AND status = ?)", models.ProductStatusPublished).
+		Order("dilihat_pada desc").
+		Limit(limit).
+		Find(&views).Error
+
+	if err != nil {
+		return views, err
+	}
+
+	return views, nil
+}
+
+// FindRelated returns the products most often bought with product_id that
+// are still on sale, best first.
+func (repository *recommendationRepositoryImpl) FindRelated(product_id uint, limit int) ([]entities.ProductRelation, error) {
+	var relations []entities.ProductRelation
+	err := repository.database.
+		Where("id_produk = ?", product_id).
+		Where("id_terkait IN (SELECT id FROM produk WHERE deleted_at IS NULL AND status = ?)", models.ProductStatusPublished).
+		Order("skor desc").
+		Order("id_terkait asc").
+		Limit(limit).
+		Find(&relations).Error
+
+	if err != nil {
+		return relations, err
+	}
+
+	return relations, nil
+}
+
+// FindCoPurchases counts, for every pair of products, the transactions that
+// contained both. Each pair appears in both directions; every product keeps
+// its models.RelatedLimit best partners.
+func (repository *recommendationRepositoryImpl) FindCoPurchases() ([]entities.ProductRelation, error) {
+	var pairs []entities.ProductRelation
+	err := repository.database.Raw(`SELECT a.id_produk AS id_produk, b.id_produk AS id_terkait, COUNT(DISTINCT da.id_trx) AS skor
+		FROM detail_trx da
+		JOIN log_produk a ON a.id = da.id_log_produk
+		JOIN detail_trx db ON db.id_trx = da.id_trx AND db.id <> da.id AND db.deleted_at IS NULL
+		JOIN log_produk b ON b.id = db.id_log_produk
+		WHERE da.deleted_at IS NULL AND a.id_produk <> b.id_produk
+		GROUP BY a.id_produk, b.id_produk`).
+		Scan(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].IDProduk != pairs[j].IDProduk {
+			return pairs[i].IDProduk < pairs[j].IDProduk
+		}
+		if pairs[i].Skor != pairs[j].Skor {
+			return pairs[i].Skor > pairs[j].Skor
+		}
+		return pairs[i].IDTerkait < pairs[j].IDTerkait
+	})
+
+	relations := []entities.ProductRelation{}
+	kept := 0
+	for i, pair := range pairs {
+		if i == 0 || pair.IDProduk != pairs[i-1].IDProduk {
+			kept = 0
+		}
+		if kept < models.RelatedLimit {
+			relations = append(relations, pair)
+			kept++
+		}
+	}
+
+	return relations, nil
+}
+
+// ReplaceRelations swaps the stored relations for a freshly computed set.
+func (repository *recommendationRepositoryImpl) ReplaceRelations(relations []entities.ProductRelation) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entities.ProductRelation{}).Error; err != nil {
+			return err
+		}
+
+		if len(relations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(relations, 500).Error
+	})
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"math"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/models/responder"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type ReviewRepository interface {
+	FindDetail(detail_trx_id uint) (entities.TrxDetail, error)
+	FindById(id uint) (entities.Review, error)
+	FindByDetail(detail_trx_id uint) (entities.Review, error)
+	FindByProductPagination(pagination responder.Pagination, product_id uint, rating int) (responder.Pagination, error)
+	CountByRating(product_id uint) (map[int]int64, error)
+	FindFlaggedPagination(pagination responder.Pagination) (responder.Pagination, error)
+	FindFlags(review_id uint) ([]entities.ReviewFlag, error)
+	Insert(review entities.Review, photo_urls []string) (entities.Review, error)
+	Reply(id uint, balasan string) error
+	Flag(flag entities.ReviewFlag) error
+	UpdateStatus(id uint, status string) error
+}
+
+type reviewRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewReviewRepository(database *gorm.DB) ReviewRepository {
+	return &reviewRepositoryImpl{database}
+}
+
+// FindDetail loads a line item with its transaction and the product snapshot
+// that was bought.
+func (repository *reviewRepositoryImpl) FindDetail(detail_trx_id uint) (entities.TrxDetail, error) {
+	var detail entities.TrxDetail
+	err := repository.database.
+		Preload("Trx").
+		Preload("ProductLog").
+		Where("id = ?", detail_trx_id).
+		First(&detail).Error
+
+	if err != nil {
+		return entities.TrxDetail{}, err
+	}
+
+	return detail, nil
+}
+
+func (repository *reviewRepositoryImpl) FindById(id uint) (entities.Review, error) {
+	var review entities.Review
+	err := repository.database.
+		Preload("User").
+		Preload("Photos").
+		Where("id = ?", id).
+		First(&review).Error
+
+	if err != nil {
+		return entities.Review{}, err
+	}
+
+	return review, nil
+}
+
+func (repository *reviewRepositoryImpl) FindByDetail(detail_trx_id uint) (entities.Review, error) {
+	var review entities.Review
+	err := repository.database.Unscoped().Where("id_detail_trx = ?", detail_trx_id).First(&review).Error
+
+	if err != nil {
+		return entities.Review{}, err
+	}
+
+	return review, nil
+}
+
+// FindByProductPagination lists a product's visible reviews, newest first.
+// A rating between 1 and 5 narrows them to that many stars.
+func (repository *reviewRepositoryImpl) FindByProductPagination(request responder.Pagination, product_id uint, rating int) (responder.Pagination, error) {
+	var reviews []entities.Review
+	var totalRows int64
+
+	query := repository.database.Model(&entities.Review{}).
+		Where("id_produk = ? AND status = ?", product_id, models.ReviewStatusVisible)
+	if rating > 0 {
+		query = query.Where("rating = ?", rating)
+	}
+	query.Count(&totalRows)
+
+	err := query.Session(&gorm.Session{}).
+		Preload("User").
+		Preload("Photos").
+		Order("id desc").
+		Limit(request.Limit).
+		Offset(request.GetOffset()).
+		Find(&reviews).Error
+
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	responses := []models.ReviewResponse{}
+	for _, review := range reviews {
+		responses = append(responses, models.ToReviewResponse(review))
+	}
+
+	request.Rows = responses
+	request.TotalRows = totalRows
+	request.TotalPages = int(math.Ceil(float64(totalRows) / float64(request.Limit)))
+
+	return request, nil
+}
+
+// CountByRating counts a product's visible reviews per star, 1 to 5.
+func (repository *reviewRepositoryImpl) CountByRating(product_id uint) (map[int]int64, error) {
+	var rows []struct {
+		Rating int
+		Total  int64
+	}
+	err := repository.database.Model(&entities.Review{}).
+		Select("rating, COUNT(*) AS total").
+		Where("id_produk = ? AND status = ?", product_id, models.ReviewStatusVisible).
+		Group("rating").
+		Scan(&rows).Error
+
+	counts := map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	if err != nil {
+		return counts, err
+	}
+
+	for _, row := range rows {
+		counts[row.Rating] = row.Total
+	}
+
+	return counts, nil
+}
+
+// FindFlaggedPagination lists reported reviews, most reported first.
+func (repository *reviewRepositoryImpl) FindFlaggedPagination(request responder.Pagination) (responder.Pagination, error) {
+	var reviews []entities.Review
+	var totalRows int64
+
+	query := repository.database.Model(&entities.Review{}).Where("jumlah_laporan > 0")
+	query.Count(&totalRows)
+
+	err := query.Session(&gorm.Session{}).
+		Preload("User").
+		Preload("Photos").
+		Order("jumlah_laporan desc").
+		Order("id asc").
+		Limit(request.Limit).
+		Offset(request.GetOffset()).
+		Find(&reviews).Error
+
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	responses := []models.ReviewResponse{}
+	for _, review := range reviews {
+		responses = append(responses, models.ToReviewResponse(review))
+	}
+
+	request.Rows = responses
+	request.TotalRows = totalRows
+	request.TotalPages = int(math.Ceil(float64(totalRows) / float64(request.Limit)))
+
+	return request, nil
+}
+
+func (repository *reviewRepositoryImpl) FindFlags(review_id uint) ([]entities.ReviewFlag, error) {
+	var flags []entities.ReviewFlag
+	err := repository.database.Where("id_ulasan = ?", review_id).Order("id asc").Find(&flags).Error
+
+	if err != nil {
+		return flags, err
+	}
+
+	return flags, nil
+}
+
+// Insert saves a review with its photos and adds it to the rating
+// aggregates of its product and store.
+func (repository *reviewRepositoryImpl) Insert(review entities.Review, photo_urls []string) (entities.Review, error) {
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+
+		for _, url := range photo_urls {
+			if err := tx.Create(&entities.ReviewPhoto{IDUlasan: review.ID, Url: url}).Error; err != nil {
+				return err
+			}
+		}
+
+		return addRating(tx, review, 1)
+	})
+
+	if err != nil {
+		return entities.Review{}, err
+	}
+
+	return review, nil
+}
+
+func (repository *reviewRepositoryImpl) Reply(id uint, balasan string) error {
+	return repository.database.Model(&entities.Review{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"balasan":      balasan,
+			"dibalas_pada": time.Now(),
+		}).Error
+}
+
+// Flag records a report, once per user and review.
+func (repository *reviewRepositoryImpl) Flag(flag entities.ReviewFlag) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&flag).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entities.Review{}).
+			Where("id = ?", flag.IDUlasan).
+			UpdateColumn("jumlah_laporan", gorm.Expr("jumlah_laporan + 1")).Error
+	})
+}
+
+// UpdateStatus hides or shows a review, moving it out of or back into the
+// rating aggregates. Showing a review dismisses the reports against it.
+func (repository *reviewRepositoryImpl) UpdateStatus(id uint, status string) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		var review entities.Review
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&review).Error
+		if err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{"status": status}
+		if status == models.ReviewStatusVisible {
+			changes["jumlah_laporan"] = 0
+			if err := tx.Where("id_ulasan = ?", id).Delete(&entities.ReviewFlag{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&entities.Review{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case review.Status == models.ReviewStatusVisible && status == models.ReviewStatusHidden:
+			return addRating(tx, review, -1)
+		case review.Status == models.ReviewStatusHidden && status == models.ReviewStatusVisible:
+			return addRating(tx, review, 1)
+		}
+		return nil
+	})
+}
+
+// addRating adds (sign 1) or removes (sign -1) a review from the rating
+// aggregates kept on its product and store.
+func addRating(database *gorm.DB, review entities.Review, sign int) error {
+	changes := map[string]interface{}{
+		"jumlah_ulasan": gorm.Expr("jumlah_ulasan + ?", sign),
+		"total_rating":  gorm.Expr("total_rating + ?", sign*review.Rating),
+	}
+
+	err := database.Unscoped().Model(&entities.Product{}).Where("id = ?", review.IDProduk).UpdateColumns(changes).Error
+	if err != nil {
+		return err
+	}
+
+	return database.Unscoped().Model(&entities.Store{}).Where("id = ?", review.IDToko).UpdateColumns(changes).Error
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type SearchLogRepository interface {
+	Insert(log entities.SearchLog) (entities.SearchLog, error)
+	FindPopular(since time.Time, min_clients int, limit int) ([]models.PopularQuery, error)
+}
+
+type searchLogRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewSearchLogRepository(database *gorm.DB) SearchLogRepository {
+	return &searchLogRepositoryImpl{database}
+}
+
+func (repository *searchLogRepositoryImpl) Insert(log entities.SearchLog) (entities.SearchLog, error) {
+	err := repository.database.Create(&log).Error
+	if err != nil {
+		return entities.SearchLog{}, err
+	}
+	return log, nil
+}
+
+// FindPopular counts the distinct clients that searched each query since the
+// given time, skipping queries that never returned anything and those fewer
+// than min_clients searched.
+func (repository *searchLogRepositoryImpl) FindPopular(since time.Time, min_clients int, limit int) ([]models.PopularQuery, error) {
+	var popular []models.PopularQuery
+	err := repository.database.Model(&entities.SearchLog{}).
+		Select("kata_kunci, COUNT(DISTINCT klien) AS total").
+		Where("created_at >= ? AND jumlah_hasil > 0", since).
+		Group("kata_kunci").
+		Having("COUNT(DISTINCT klien) >= ?", min_clients).
+		Order("total desc").
+		Limit(limit).
+		Scan(&popular).Error
+
+	if err != nil {
+		return popular, err
+	}
+
+	return popular, nil
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"errors"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type StoreAnalyticsRepository interface {
+	RecordView(product_id uint, at time.Time) error
+	FindStoreStats(store_id uint, from string, to string) ([]entities.StoreDailyStat, error)
+	FindTopProducts(store_id uint, from string, to string, limit int) ([]models.StoreAnalyticsProduct, error)
+	CountBuyers(store_id uint, from string, to string) (int, int, error)
+	LastRollup() (*time.Time, error)
+	Rollup(now time.Time) (int, error)
+}
+
+type storeAnalyticsRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewStoreAnalyticsRepository(database *gorm.DB) StoreAnalyticsRepository {
+	return &storeAnalyticsRepositoryImpl{database}
+}
+
+// analyticsDate is how days are passed to the queries, so they do not depend
+// on the time zone of the connection
+const analyticsDate = "2006-01-02"
+
+// excludedOrderStatuses are the items that do not count as sales
+var excludedOrderStatuses = []string{models.OrderStatusCancelled, models.OrderStatusRefunded}
+
+// RecordView adds a view to the product's count of the day
+func (repository *storeAnalyticsRepositoryImpl) RecordView(product_id uint, at time.Time) error {
+	tanggal := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+
+	return repository.database.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id_produk"}, {Name: "tanggal"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"jumlah":     gorm.Expr("jumlah + 1"),
+			"updated_at": at,
+		}),
+	}).Create(&entities.ProductViewCount{IDProduk: product_id, Tanggal: tanggal, Jumlah: 1, UpdatedAt: &at}).Error
+}
+
+func (repository *storeAnalyticsRepositoryImpl) FindStoreStats(store_id uint, from string, to string) ([]entities.StoreDailyStat, error) {
+	var stats []entities.StoreDailyStat
+	err := repository.database.
+		Where("id_toko = ? AND tanggal BETWEEN ? AND ?", store_id, from, to).
+		Order("tanggal asc").
+		Find(&stats).Error
+	return stats, err
+}
+
+// FindTopProducts ranks the store's products by revenue over the range, then
+// by units and views
+func (repository *storeAnalyticsRepositoryImpl) FindTopProducts(store_id uint, from string, to string, limit int) ([]models.StoreAnalyticsProduct, error) {
+	var products []models.StoreAnalyticsProduct
+	err := repository.database.Raw(`SELECT s.id_produk AS product_id, COALESCE(p.nama_produk, '') AS nama_produk,
+                                    SUM(s.pendapatan) AS revenue, SUM(s.pesanan) AS orders, SUM(s.unit) AS units_sold, SUM(s.dilihat) AS views
+                                    FROM statistik_produk_harian s
+                                    LEFT JOIN produk p ON p.id = s.id_produk
+                                    WHERE s.id_toko = ? AND s.tanggal BETWEEN ? AND ?
+                                    GROUP BY s.id_produk, p.nama_produk
+                                    ORDER BY revenue DESC, units_sold DESC, views DESC
+                                    LIMIT ?`, store_id, from, to, limit).Scan(&products).Error
+	return products, err
+}
+
+// CountBuyers returns how many users bought from the store over the range and
+// how many of them are repeat buyers: they ordered more than once in the
+// range, or had ordered before it
+func (repository *storeAnalyticsRepositoryImpl) CountBuyers(store_id uint, from string, to string) (int, int, error) {
+	var counts struct {
+		Buyers       int
+		RepeatBuyers int
+	}
+	err := repository.database.Raw(`SELECT COUNT(*) AS buyers,
+                                    COALESCE(SUM(b.pesanan > 1 OR EXISTS (
+                                        SELECT 1 FROM statistik_pembeli_harian e
+                                        WHERE e.id_toko = b.id_toko AND e.id_user = b.id_user AND e.tanggal < ?)), 0) AS repeat_buyers
+                                    FROM (SELECT id_toko, id_user, SUM(pesanan) AS pesanan FROM statistik_pembeli_harian
+                                          WHERE id_toko = ? AND tanggal BETWEEN ? AND ?
+                                          GROUP BY id_toko, id_user) b`, from, store_id, from, to).Scan(&counts).Error
+	return counts.Buyers, counts.RepeatBuyers, err
+}
+
+// LastRollup returns up to when the rollups are current, nil before the first
+// rollup
+func (repository *storeAnalyticsRepositoryImpl) LastRollup() (*time.Time, error) {
+	var state entities.AnalyticsRollup
+	err := repository.database.First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state.DiprosesSampai, nil
+}
+
+// Rollup rebuilds the daily rollups of every day with sales or views that
+// changed since the last rollup, all days the first time, and returns how
+// many days it rebuilt. Changes made while it runs are picked up next time.
+func (repository *storeAnalyticsRepositoryImpl) Rollup(now time.Time) (int, error) {
+	var state entities.AnalyticsRollup
+	err := repository.database.First(&state).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	since := state.DiprosesSampai
+
+	// A status change moves an item in or out of the sales of the day it was
+	// ordered, so the days come from when items were made
+	var days []string
+	err = repository.database.Raw(`SELECT DATE_FORMAT(created_at, '%Y-%m-%d') AS tanggal FROM detail_trx
+                                   WHERE created_at IS NOT NULL AND (created_at >= @since OR updated_at >= @since OR deleted_at >= @since)
+                                   UNION
+                                   SELECT DATE_FORMAT(tanggal, '%Y-%m-%d') FROM tayangan_produk_harian WHERE updated_at >= @since
+                                   ORDER BY tanggal`, map[string]interface{}{"since": since}).Scan(&days).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for _, day := range days {
+		err := repository.database.Transaction(func(tx *gorm.DB) error {
+			return rollupDay(tx, day)
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	state.DiprosesSampai = now
+	if state.ID == 0 {
+		return len(days), repository.database.Create(&state).Error
+	}
+	return len(days), repository.database.Save(&state).Error
+}
+
+// rollupDay replaces the rollups of one day, given as YYYY-MM-DD
+func rollupDay(tx *gorm.DB, day string) error {
+	start, err := time.Parse(analyticsDate, day)
+	if err != nil {
+		return err
+	}
+
+	args := map[string]interface{}{
+		"day":      day,
+		"start":    start.Format(time.DateTime),
+		"end":      start.AddDate(0, 0, 1).Format(time.DateTime),
+		"excluded": excludedOrderStatuses,
+	}
+
+	statements := []string{
+		`DELETE FROM statistik_produk_harian WHERE tanggal = @day`,
+		`DELETE FROM statistik_toko_harian WHERE tanggal = @day`,
+		`DELETE FROM statistik_pembeli_harian WHERE tanggal = @day`,
+		`INSERT INTO statistik_produk_harian (id_toko, id_produk, tanggal, pendapatan, pesanan, unit, dilihat)
+         SELECT d.id_toko, l.id_produk, @day, SUM(d.harga_total), COUNT(DISTINCT d.id_trx), SUM(d.kuantitas), 0
+         FROM detail_trx d
+         JOIN log_produk l ON l.id = d.id_log_produk
+         WHERE d.deleted_at IS NULL AND d.created_at >= @start AND d.created_at < @end AND d.status NOT IN @excluded
+         GROUP BY d.id_toko, l.id_produk`,
+		`INSERT INTO statistik_produk_harian (id_toko, id_produk, tanggal, pendapatan, pesanan, unit, dilihat)
+         SELECT p.id_toko, v.id_produk, @day, 0, 0, 0, v.jumlah
+         FROM tayangan_produk_harian v
+         JOIN produk p ON p.id = v.id_produk
+         WHERE v.tanggal = @day
+         ON DUPLICATE KEY UPDATE dilihat = VALUES(dilihat)`,
+		`INSERT INTO statistik_toko_harian (id_toko, tanggal, pendapatan, pesanan, unit, pembeli, dilihat)
+         SELECT d.id_toko, @day, SUM(d.harga_total), COUNT(DISTINCT d.id_trx), SUM(d.kuantitas), COUNT(DISTINCT t.id_user), 0
+         FROM detail_trx d
+         JOIN trx t ON t.id = d.id_trx
+         WHERE d.deleted_at IS NULL AND d.created_at >= @start AND d.created_at < @end AND d.status NOT IN @excluded
+         GROUP BY d.id_toko`,
+		`INSERT INTO statistik_toko_harian (id_toko, tanggal, pendapatan, pesanan, unit, pembeli, dilihat)
+         SELECT id_toko, @day, 0, 0, 0, 0, SUM(dilihat)
+         FROM statistik_produk_harian
+         WHERE tanggal = @day
+         GROUP BY id_toko
+         HAVING SUM(dilihat) > 0
+         ON DUPLICATE KEY UPDATE dilihat = VALUES(dilihat)`,
+		`INSERT INTO statistik_pembeli_harian (id_toko, id_user, tanggal, pesanan)
+         SELECT d.id_toko, t.id_user, @day, COUNT(DISTINCT d.id_trx)
+         FROM detail_trx d
+         JOIN trx t ON t.id = d.id_trx
+         WHERE d.deleted_at IS NULL AND d.created_at >= @start AND d.created_at < @end AND d.status NOT IN @excluded
+         GROUP BY d.id_toko, t.id_user`,
+	}
+
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
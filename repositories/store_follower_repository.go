@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"mini-project-evermos/models/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type StoreFollowerRepository interface {
+	FindByUserId(user_id uint) ([]entities.StoreFollower, error)
+	Follow(store_id uint, user_id uint) error
+	Unfollow(store_id uint, user_id uint) error
+}
+
+type storeFollowerRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewStoreFollowerRepository(database *gorm.DB) StoreFollowerRepository {
+	return &storeFollowerRepositoryImpl{database}
+}
+
+func (repository *storeFollowerRepositoryImpl) FindByUserId(user_id uint) ([]entities.StoreFollower, error) {
+	var follows []entities.StoreFollower
+	err := repository.database.
+		Joins("Store").
+		Where("pengikut_toko.id_user = ?", user_id).
+		Order("pengikut_toko.id desc").
+		Find(&follows).Error
+
+	if err != nil {
+		return follows, err
+	}
+
+	return follows, nil
+}
+
+// Follow is idempotent; the store's follower count only moves when a new
+// follow is recorded.
+func (repository *storeFollowerRepositoryImpl) Follow(store_id uint, user_id uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entities.StoreFollower{IDToko: store_id, IDUser: user_id})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Model(&entities.Store{}).Where("id = ?", store_id).
+			UpdateColumn("jumlah_pengikut", gorm.Expr("jumlah_pengikut + 1")).Error
+	})
+}
+
+func (repository *storeFollowerRepositoryImpl) Unfollow(store_id uint, user_id uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id_toko = ? AND id_user = ?", store_id, user_id).Delete(&entities.StoreFollower{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&entities.Store{}).Where("id = ?", store_id).
+			UpdateColumn("jumlah_pengikut", gorm.Expr("jumlah_pengikut - 1")).Error
+	})
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"errors"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type StoreMemberRepository interface {
+	FindById(id uint) (entities.StoreMember, error)
+	FindByStoreAndUser(store_id uint, user_id uint) (entities.StoreMember, error)
+	FindByStoreId(store_id uint) ([]entities.StoreMember, error)
+	FindByUserId(user_id uint) ([]entities.StoreMember, error)
+	Update(id uint, peran string, izin string) error
+	Delete(id uint) error
+	FindInvitationById(id uint) (entities.StoreInvitation, error)
+	FindInvitationByToken(token string) (entities.StoreInvitation, error)
+	FindPendingInvitations(store_id uint) ([]entities.StoreInvitation, error)
+	InsertInvitation(invitation entities.StoreInvitation) (entities.StoreInvitation, error)
+	DeleteInvitation(id uint) error
+	AcceptInvitation(id uint, user_id uint) (entities.StoreMember, error)
+}
+
+type storeMemberRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewStoreMemberRepository(database *gorm.DB) StoreMemberRepository {
+	return &storeMemberRepositoryImpl{database}
+}
+
+func (repository *storeMemberRepositoryImpl) FindById(id uint) (entities.StoreMember, error) {
+	var member entities.StoreMember
+	err := repository.database.Preload("User").First(&member, id).Error
+	return member, err
+}
+
+func (repository *storeMemberRepositoryImpl) FindByStoreAndUser(store_id uint, user_id uint) (entities.StoreMember, error) {
+	var member entities.StoreMember
+	err := repository.database.Where("id_toko = ? AND id_user = ?", store_id, user_id).First(&member).Error
+	return member, err
+}
+
+// FindByStoreId lists the members of a store in the order they joined, so
+// the owner comes first
+func (repository *storeMemberRepositoryImpl) FindByStoreId(store_id uint) ([]entities.StoreMember, error) {
+	var members []entities.StoreMember
+	err := repository.database.
+		Preload("User").
+		Where("id_toko = ?", store_id).
+		Order("id asc").
+		Find(&members).Error
+	return members, err
+}
+
+// FindByUserId lists the active stores the user is a member of
+func (repository *storeMemberRepositoryImpl) FindByUserId(user_id uint) ([]entities.StoreMember, error) {
+	var members []entities.StoreMember
+	err := repository.database.
+		Preload("Store.JamOperasional", storeHoursInOrder).
+		Where("id_user = ? AND id_toko IN (SELECT id FROM toko WHERE deleted_at IS NULL)", user_id).
+		Order("id asc").
+		Find(&members).Error
+	return members, err
+}
+
+func (repository *storeMemberRepositoryImpl) Update(id uint, peran string, izin string) error {
+	return repository.database.Model(&entities.StoreMember{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"peran": peran, "izin": izin}).Error
+}
+
+func (repository *storeMemberRepositoryImpl) Delete(id uint) error {
+	return repository.database.Delete(&entities.StoreMember{}, id).Error
+}
+
+func (repository *storeMemberRepositoryImpl) FindInvitationById(id uint) (entities.StoreInvitation, error) {
+	var invitation entities.StoreInvitation
+	err := repository.database.First(&invitation, id).Error
+	return invitation, err
+}
+
+func (repository *storeMemberRepositoryImpl) FindInvitationByToken(token string) (entities.StoreInvitation, error) {
+	var invitation entities.StoreInvitation
+	err := repository.database.Where("token = ?", token).First(&invitation).Error
+	return invitation, err
+}
+
+// FindPendingInvitations lists the invitations of a store that were not
+// accepted yet, expired ones included
+func (repository *storeMemberRepositoryImpl) FindPendingInvitations(store_id uint) ([]entities.StoreInvitation, error) {
+	var invitations []entities.StoreInvitation
+	err := repository.database.
+		Where("id_toko = ? AND diterima_pada IS NULL", store_id).
+		Order("id desc").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (repository *storeMemberRepositoryImpl) InsertInvitation(invitation entities.StoreInvitation) (entities.StoreInvitation, error) {
+	err := repository.database.Create(&invitation).Error
+	return invitation, err
+}
+
+func (repository *storeMemberRepositoryImpl) DeleteInvitation(id uint) error {
+	return repository.database.Delete(&entities.StoreInvitation{}, id).Error
+}
+
+// AcceptInvitation makes the user a member with the role and permissions of
+// the invitation. An invitation can be accepted once.
+func (repository *storeMemberRepositoryImpl) AcceptInvitation(id uint, user_id uint) (entities.StoreMember, error) {
+	var member entities.StoreMember
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		var invitation entities.StoreInvitation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invitation, id).Error; err != nil {
+			return err
+		}
+		if invitation.DiterimaPada != nil {
+			return errors.New("invitation was already accepted")
+		}
+
+		var count int64
+		if err := tx.Model(&entities.StoreMember{}).
+			Where("id_toko = ? AND id_user = ?", invitation.IDToko, user_id).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("user is already a member of this store")
+		}
+
+		member = entities.StoreMember{
+			IDToko: invitation.IDToko,
+			IDUser: user_id,
+			Peran:  invitation.Peran,
+			Izin:   invitation.Izin,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&invitation).Update("diterima_pada", time.Now()).Error
+	})
+	if err != nil {
+		return entities.StoreMember{}, err
+	}
+	return member, nil
+}
+
+// addStoreOwner makes the user the owner member of their new store
+func addStoreOwner(tx *gorm.DB, store_id uint, user_id uint) error {
+	return tx.Create(&entities.StoreMember{
+		IDToko: store_id,
+		IDUser: user_id,
+		Peran:  models.StoreRoleOwner,
+	}).Error
+}
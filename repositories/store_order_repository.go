@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/models/responder"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type StoreOrderRepository interface {
+	FindAllPagination(pagination responder.Pagination, store_id uint, status string) (responder.Pagination, error)
+	FindById(id uint) (entities.TrxDetail, error)
+	UpdateStatus(id uint, from string, to string) (bool, error)
+}
+
+type storeOrderRepositoryImpl struct {
+	database *gorm.DB
+}
+
+// NewStoreOrderRepository reads transaction line items from the side of the
+// store that sells them
+func NewStoreOrderRepository(database *gorm.DB) StoreOrderRepository {
+	return &storeOrderRepositoryImpl{database}
+}
+
+// FindAllPagination lists the store's line items, newest first. The keyword
+// matches the invoice code.
+func (repository *storeOrderRepositoryImpl) FindAllPagination(pagination responder.Pagination, store_id uint, status string) (responder.Pagination, error) {
+	var details []entities.TrxDetail
+	var totalRows int64
+
+	query := repository.database.Model(&entities.TrxDetail{}).Where("id_toko = ?", store_id)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if pagination.Keyword != "" {
+		query = query.Where("id_trx IN (SELECT id FROM trx WHERE kode_invoice LIKE ?)", "%"+pagination.Keyword+"%")
+	}
+
+	if err := query.Count(&totalRows).Error; err != nil {
+		return responder.Pagination{}, err
+	}
+
+	err := query.
+		Preload("Trx.Address").
+		Preload("ProductLog").
+		Order("id desc").
+		Limit(pagination.Limit).
+		Offset(pagination.GetOffset()).
+		Find(&details).Error
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	responses := []models.StoreOrderResponse{}
+	for _, detail := range details {
+		responses = append(responses, models.ToStoreOrderResponse(detail))
+	}
+
+	pagination.Rows = responses
+	pagination.TotalRows = totalRows
+	pagination.TotalPages = int((totalRows + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return pagination, nil
+}
+
+func (repository *storeOrderRepositoryImpl) FindById(id uint) (entities.TrxDetail, error) {
+	var detail entities.TrxDetail
+	err := repository.database.
+		Preload("Trx.Address").
+		Preload("ProductLog").
+		First(&detail, id).Error
+	return detail, err
+}
+
+// UpdateStatus moves the item from status from to to. It reports false when
+// the item was no longer in status from.
+func (repository *storeOrderRepositoryImpl) UpdateStatus(id uint, from string, to string) (bool, error) {
+	result := repository.database.Model(&entities.TrxDetail{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
@@ -1,11 +1,14 @@
 package repositories
 
 import (
+	"fmt"
 	"math"
 	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
 	"mini-project-evermos/models/responder"
+	"time"
 
+	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
@@ -14,9 +17,11 @@ type StoreRepository interface {
 	FindAllPagination(pagination responder.Pagination) (responder.Pagination, error)
 	FindById(id uint) (entities.Store, error)
 	FindByUserId(id uint) (entities.Store, error)
+	FindBySlug(slug string) (entities.Store, error)
 	Update(id uint, store entities.Store) (bool, error)
 	Insert(store entities.Store) (entities.Store, error)
 	Delete(id uint) (bool, error)
+	SetVacation(id uint, libur bool, libur_sampai *time.Time, pesan_libur string) error
 }
 
 type storeRepositoryImpl struct {
@@ -38,6 +43,7 @@ func (repository *storeRepositoryImpl) FindAllPagination(pagination responder.Pa
 	query.Count(&totalRows)
 
 	err := query.
+		Preload("JamOperasional", storeHoursInOrder).
 		Limit(pagination.Limit).
 		Offset(pagination.GetOffset()).
 		Find(&stores).Error
@@ -48,13 +54,7 @@ func (repository *storeRepositoryImpl) FindAllPagination(pagination responder.Pa
 
 	var responses []models.StoreResponse
 	for _, store := range stores {
-		responses = append(responses, models.StoreResponse{
-			ID:        store.ID,
-			NamaToko:  store.NamaToko,
-			UrlFoto:   store.UrlFoto,
-			CreatedAt: store.CreatedAt,
-			UpdatedAt: store.UpdatedAt,
-		})
+		responses = append(responses, models.ToStoreResponse(store))
 	}
 
 	pagination.Rows = responses
@@ -64,9 +64,14 @@ func (repository *storeRepositoryImpl) FindAllPagination(pagination responder.Pa
 	return pagination, nil
 }
 
+// storeHoursInOrder sorts preloaded opening hours from Sunday on
+func storeHoursInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("jam_operasional_toko.hari asc")
+}
+
 func (repository *storeRepositoryImpl) FindById(id uint) (entities.Store, error) {
 	var store entities.Store
-	err := repository.database.Where("id = ?", id).First(&store).Error
+	err := repository.database.Preload("JamOperasional", storeHoursInOrder).Where("id = ?", id).First(&store).Error
 
 	if err != nil {
 		return store, err
@@ -77,7 +82,7 @@ func (repository *storeRepositoryImpl) FindById(id uint) (entities.Store, error)
 
 func (repository *storeRepositoryImpl) FindByUserId(id uint) (entities.Store, error) {
 	var store entities.Store
-	err := repository.database.Where("id_user = ?", id).First(&store).Error
+	err := repository.database.Preload("JamOperasional", storeHoursInOrder).Where("id_user = ?", id).First(&store).Error
 
 	if err != nil {
 		return store, err
@@ -86,8 +91,48 @@ func (repository *storeRepositoryImpl) FindByUserId(id uint) (entities.Store, er
 	return store, nil
 }
 
+func (repository *storeRepositoryImpl) FindBySlug(slug string) (entities.Store, error) {
+	var store entities.Store
+	err := repository.database.Preload("JamOperasional", storeHoursInOrder).Where("slug = ?", slug).First(&store).Error
+	return store, err
+}
+
+// Update saves the store's name, photos, profile and opening hours. A new
+// name gets the store a new slug.
 func (repository *storeRepositoryImpl) Update(id uint, store entities.Store) (bool, error) {
-	err := repository.database.Model(&store).Where("id = ?", id).Updates(store).Error
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		var current entities.Store
+		if err := tx.Where("id = ?", id).First(&current).Error; err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"nama_toko":   store.NamaToko,
+			"url_foto":    store.UrlFoto,
+			"url_banner":  store.UrlBanner,
+			"deskripsi":   store.Deskripsi,
+			"id_provinsi": store.IDProvinsi,
+			"id_kota":     store.IDKota,
+			"telepon":     store.Telepon,
+			"whatsapp":    store.Whatsapp,
+			"email":       store.Email,
+			"instagram":   store.Instagram,
+		}
+
+		if storeSlugBase(store.NamaToko) != storeSlugBase(current.NamaToko) || current.Slug == "" {
+			new_slug, err := uniqueStoreSlug(tx, store.NamaToko, id)
+			if err != nil {
+				return err
+			}
+			changes["slug"] = new_slug
+		}
+
+		if err := tx.Model(&entities.Store{}).Where("id = ?", id).Updates(changes).Error; err != nil {
+			return err
+		}
+
+		return replaceStoreHours(tx, id, store.JamOperasional)
+	})
 
 	if err != nil {
 		return false, err
@@ -97,7 +142,18 @@ func (repository *storeRepositoryImpl) Update(id uint, store entities.Store) (bo
 }
 
 func (repository *storeRepositoryImpl) Insert(store entities.Store) (entities.Store, error) {
-	err := repository.database.Create(&store).Error
+	err := repository.database.Transaction(func(tx *gorm.DB) error {
+		new_slug, err := uniqueStoreSlug(tx, store.NamaToko, 0)
+		if err != nil {
+			return err
+		}
+		store.Slug = new_slug
+
+		if err := tx.Create(&store).Error; err != nil {
+			return err
+		}
+		return addStoreOwner(tx, store.ID, store.IDUser)
+	})
 	if err != nil {
 		return entities.Store{}, err
 	}
@@ -111,3 +167,66 @@ func (repository *storeRepositoryImpl) Delete(id uint) (bool, error) {
 	}
 	return true, nil
 }
+
+// SetVacation turns vacation mode on or off
+func (repository *storeRepositoryImpl) SetVacation(id uint, libur bool, libur_sampai *time.Time, pesan_libur string) error {
+	return repository.database.Model(&entities.Store{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"libur":        libur,
+			"libur_sampai": libur_sampai,
+			"pesan_libur":  pesan_libur,
+		}).Error
+}
+
+func replaceStoreHours(tx *gorm.DB, store_id uint, hours []entities.StoreHour) error {
+	if err := tx.Where("id_toko = ?", store_id).Delete(&entities.StoreHour{}).Error; err != nil {
+		return err
+	}
+
+	for _, hour := range hours {
+		hour.ID = 0
+		hour.IDToko = store_id
+		if err := tx.Create(&hour).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func storeSlugBase(nama_toko *string) string {
+	base := ""
+	if nama_toko != nil {
+		base = slug.Make(*nama_toko)
+	}
+	if base == "" {
+		base = "toko"
+	}
+	return base
+}
+
+// uniqueStoreSlug builds a slug from the store name that no other store
+// uses, appending -2, -3, ... on collisions.
+func uniqueStoreSlug(tx *gorm.DB, nama_toko *string, store_id uint) (string, error) {
+	base := storeSlugBase(nama_toko)
+
+	var taken []string
+	err := tx.Unscoped().Model(&entities.Store{}).
+		Where("(slug = ? OR slug LIKE ?) AND id <> ?", base, base+"-%", store_id).
+		Pluck("slug", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	used := map[string]bool{}
+	for _, value := range taken {
+		used[value] = true
+	}
+
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return candidate, nil
+}
@@ -36,7 +36,7 @@ func (repository *transactionRepositoryImpl) FindAllPagination(pagination respon
 		Preload("TrxDetail.ProductLog.Product").
 		Preload("TrxDetail.ProductLog.Product.Store").
 		Preload("TrxDetail.ProductLog.Product.Category").
-		Preload("TrxDetail.ProductLog.Product.ProductPicture").
+		Preload("TrxDetail.ProductLog.Product.ProductPicture", picturesInOrder).
 		Preload("TrxDetail.Store").
 		Limit(pagination.Limit).
 		Offset(pagination.GetOffset()).
@@ -71,26 +71,15 @@ func (repository *transactionRepositoryImpl) FindAllPagination(pagination respon
 			// Convert product pictures
 			var photos []models.ProductPictureResponse
 			for _, pic := range detail.ProductLog.Product.ProductPicture {
-				photos = append(photos, models.ProductPictureResponse{
-					ID:        pic.ID,
-					IDProduk:  pic.IDProduk,
-					Url:       pic.Url,
-					CreatedAt: pic.CreatedAt,
-					UpdatedAt: pic.UpdatedAt,
-				})
+				photos = append(photos, models.ToProductPictureResponse(pic))
 			}
 
 			details = append(details, models.TransactionDetailResponse{
 				ID:         detail.ID,
 				Kuantitas:  detail.Kuantitas,
 				HargaTotal: detail.HargaTotal,
-				Store: models.StoreResponse{
-					ID:        detail.Store.ID,
-					NamaToko:  detail.Store.NamaToko,
-					UrlFoto:   detail.Store.UrlFoto,
-					CreatedAt: detail.Store.CreatedAt,
-					UpdatedAt: detail.Store.UpdatedAt,
-				},
+				Status:     detail.Status,
+				Store:      models.ToStoreResponse(detail.Store),
 				Product: models.ProductResponse{
 					ID:            detail.ProductLog.Product.ID,
 					NamaProduk:    detail.ProductLog.Product.NamaProduk,
@@ -99,13 +88,7 @@ func (repository *transactionRepositoryImpl) FindAllPagination(pagination respon
 					HargaKonsumen: detail.ProductLog.Product.HargaKonsumen,
 					Stok:          detail.ProductLog.Product.Stok,
 					Deskripsi:     detail.ProductLog.Product.Deskripsi,
-					Store: models.StoreResponse{
-						ID:        detail.ProductLog.Product.Store.ID,
-						NamaToko:  detail.ProductLog.Product.Store.NamaToko,
-						UrlFoto:   detail.ProductLog.Product.Store.UrlFoto,
-						CreatedAt: detail.ProductLog.Product.Store.CreatedAt,
-						UpdatedAt: detail.ProductLog.Product.Store.UpdatedAt,
-					},
+					Store:         models.ToStoreResponse(detail.ProductLog.Product.Store),
 					Category: models.CategoryResponse{
 						ID:           detail.ProductLog.Product.Category.ID,
 						NamaCategory: detail.ProductLog.Product.Category.NamaCategory,
@@ -137,7 +120,7 @@ func (repository *transactionRepositoryImpl) FindById(id uint) (entities.Trx, er
 		Preload("TrxDetail.ProductLog.Product").
 		Preload("TrxDetail.ProductLog.Product.Store").
 		Preload("TrxDetail.ProductLog.Product.Category").
-		Preload("TrxDetail.ProductLog.Product.ProductPicture").
+		Preload("TrxDetail.ProductLog.Product.ProductPicture", picturesInOrder).
 		Preload("TrxDetail.Store").
 		Where("id = ?", id).
 		First(&transaction).Error
@@ -172,10 +155,13 @@ func (repository *transactionRepositoryImpl) Insert(transaction models.Transacti
 			IDToko:        v.StoreID,
 			IDCategory:    v.CategoryID,
 		}
-		if err := tx.Create(log_product).Error; err != nil {
+		// Reuse the latest snapshot when the product has not changed since
+		snapshot, err := appendProductLog(tx, *log_product)
+		if err != nil {
 			tx.Rollback()
 			return 0, err
 		}
+		log_product = &snapshot
 
 		if err := tx.Create(&entities.TrxDetail{
 			IDTrx:       transaction_insert.ID,
@@ -183,6 +169,7 @@ func (repository *transactionRepositoryImpl) Insert(transaction models.Transacti
 			IDToko:      v.StoreID,
 			Kuantitas:   v.Kuantitas,
 			HargaTotal:  v.HargaTotal,
+			Status:      models.OrderStatusPending,
 		}).Error; err != nil {
 			tx.Rollback()
 			return 0, err
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"mini-project-evermos/models/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Contract
+type WishlistRepository interface {
+	FindByUserId(user_id uint) ([]entities.Wishlist, error)
+	FindById(id uint) (entities.Wishlist, error)
+	FindByName(user_id uint, nama string) (entities.Wishlist, error)
+	Insert(wishlist entities.Wishlist) (entities.Wishlist, error)
+	Rename(id uint, nama string) error
+	Delete(id uint) error
+	AddItem(wishlist_id uint, product_id uint) error
+	RemoveItem(wishlist_id uint, product_id uint) error
+	FindWatchers(product_id uint, tipe string, since time.Time) ([]uint, error)
+}
+
+type wishlistRepositoryImpl struct {
+	database *gorm.DB
+}
+
+func NewWishlistRepository(database *gorm.DB) WishlistRepository {
+	return &wishlistRepositoryImpl{database}
+}
+
+func (repository *wishlistRepositoryImpl) FindByUserId(user_id uint) ([]entities.Wishlist, error) {
+	var wishlists []entities.Wishlist
+	err := repository.database.
+		Preload("Items", liveWishlistItems).
+		Where("id_user = ?", user_id).
+		Order("id asc").
+		Find(&wishlists).Error
+
+	if err != nil {
+		return wishlists, err
+	}
+
+	return wishlists, nil
+}
+
+// FindById loads a wishlist with its products, newest additions first.
+func (repository *wishlistRepositoryImpl) FindById(id uint) (entities.Wishlist, error) {
+	var wishlist entities.Wishlist
+	err := repository.database.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return liveWishlistItems(db).Order("id desc")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Product.Store").
+		Preload("Items.Product.Category").
+		Preload("Items.Product.ProductPicture", picturesInOrder).
+		Where("id = ?", id).
+		First(&wishlist).Error
+
+	if err != nil {
+		return entities.Wishlist{}, err
+	}
+
+	return wishlist, nil
+}
+
+func (repository *wishlistRepositoryImpl) FindByName(user_id uint, nama string) (entities.Wishlist, error) {
+	var wishlist entities.Wishlist
+	err := repository.database.Where("id_user = ? AND nama = ?", user_id, nama).First(&wishlist).Error
+
+	if err != nil {
+		return entities.Wishlist{}, err
+	}
+
+	return wishlist, nil
+}
+
+func (repository *wishlistRepositoryImpl) Insert(wishlist entities.Wishlist) (entities.Wishlist, error) {
+	err := repository.database.Create(&wishlist).Error
+	if err != nil {
+		return entities.Wishlist{}, err
+	}
+	return wishlist, nil
+}
+
+func (repository *wishlistRepositoryImpl) Rename(id uint, nama string) error {
+	return repository.database.Model(&entities.Wishlist{}).Where("id = ?", id).Update("nama", nama).Error
+}
+
+func (repository *wishlistRepositoryImpl) Delete(id uint) error {
+	return repository.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_wishlist = ?", id).Delete(&entities.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Wishlist{}, id).Error
+	})
+}
+
+// AddItem is idempotent: adding a product twice keeps the first entry.
+func (repository *wishlistRepositoryImpl) AddItem(wishlist_id uint, product_id uint) error {
+	return repository.database.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.WishlistItem{IDWishlist: wishlist_id, IDProduk: product_id}).Error
+}
+
+func (repository *wishlistRepositoryImpl) RemoveItem(wishlist_id uint, product_id uint) error {
+	result := repository.database.
+		Where("id_wishlist = ? AND id_produk = ?", wishlist_id, product_id).
+		Delete(&entities.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// liveWishlistItems leaves out items whose product was deleted since; they
+// come back if the product is restored.
+func liveWishlistItems(db *gorm.DB) *gorm.DB {
+	return db.Where("id_produk IN (SELECT id FROM produk WHERE deleted_at IS NULL)")
+}
+
+// FindWatchers returns the users who saved product_id to any wishlist, leaving
+// out those sent a notification of type tipe about it since the given time.
+func (repository *wishlistRepositoryImpl) FindWatchers(product_id uint, tipe string, since time.Time) ([]uint, error) {
+	var user_ids []uint
+	err := repository.database.Model(&entities.WishlistItem{}).
+		Joins("JOIN wishlist ON wishlist.id = item_wishlist.id_wishlist").
+		Where("item_wishlist.id_produk = ?", product_id).
+		Where(`NOT EXISTS (SELECT 1 FROM notifikasi WHERE notifikasi.id_user = wishlist.id_user
+               AND notifikasi.id_produk = ? AND notifikasi.tipe = ? AND notifikasi.created_at >= ?)`, product_id, tipe, since).
+		Distinct().
+		Pluck("wishlist.id_user", &user_ids).Error
+
+	return user_ids, err
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"mini-project-evermos/models"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/spreadsheet"
+	"strconv"
+	"time"
+)
+
+// Contract
+type AdminReportService interface {
+	GMV(input models.AdminReportRequest) (models.AdminGMVReport, error)
+	OrdersByStatus(input models.AdminReportRequest) (models.AdminOrderStatusReport, error)
+	Signups(input models.AdminReportRequest) (models.AdminSignupReport, error)
+	TopCategories(input models.AdminReportRequest) (models.AdminTopReport, error)
+	TopStores(input models.AdminReportRequest) (models.AdminTopReport, error)
+	Refunds(input models.AdminReportRequest) (models.AdminRefundReport, error)
+	Export(report string, input models.AdminReportRequest, format string) (func(w io.Writer) error, error)
+}
+
+type adminReportServiceImpl struct {
+	repository repositories.AdminReportRepository
+}
+
+// NewAdminReportService reports on the whole platform, read straight from
+// the transactions so the figures are always current.
+func NewAdminReportService(adminReportRepository *repositories.AdminReportRepository) AdminReportService {
+	return &adminReportServiceImpl{
+		repository: *adminReportRepository,
+	}
+}
+
+// reportPeriod is a parsed AdminReportRequest
+type reportPeriod struct {
+	from   time.Time
+	to     time.Time // the day after the range, for the queries
+	bucket string
+	limit  int
+	info   models.AdminReportRange
+}
+
+func newReportPeriod(input models.AdminReportRequest) (reportPeriod, error) {
+	from, to, err := analyticsRange(input.From, input.To)
+	if err != nil {
+		return reportPeriod{}, err
+	}
+
+	bucket, err := analyticsBucketOf(input.Bucket)
+	if err != nil {
+		return reportPeriod{}, err
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = models.DefaultAdminReportLimit
+	}
+	if limit > models.MaxAdminReportLimit {
+		limit = models.MaxAdminReportLimit
+	}
+
+	return reportPeriod{
+		from:   from,
+		to:     to.AddDate(0, 0, 1),
+		bucket: bucket,
+		limit:  limit,
+		info: models.AdminReportRange{
+			From:        from.Format(time.DateOnly),
+			To:          to.Format(time.DateOnly),
+			GeneratedAt: time.Now(),
+		},
+	}, nil
+}
+
+// series returns the period's buckets, see analyticsBuckets
+func (period reportPeriod) series() ([]string, map[string]int) {
+	return analyticsBuckets(period.from, period.to.AddDate(0, 0, -1), period.bucket)
+}
+
+// bucketed is the period's range info for the reports with a series
+func (period reportPeriod) bucketed() models.AdminReportRange {
+	info := period.info
+	info.Bucket = period.bucket
+	return info
+}
+
+func (service *adminReportServiceImpl) GMV(input models.AdminReportRequest) (models.AdminGMVReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminGMVReport{}, err
+	}
+
+	sales, err := service.repository.FindDailySales(period.from, period.to)
+	if err != nil {
+		return models.AdminGMVReport{}, err
+	}
+
+	buckets, index := period.series()
+	series := make([]models.AdminGMVPoint, len(buckets))
+	for i, date := range buckets {
+		series[i].Date = date
+	}
+
+	total := models.AdminGMVPoint{}
+	for _, day := range sales {
+		i, ok := index[day.Date]
+		if !ok {
+			continue
+		}
+		for _, point := range []*models.AdminGMVPoint{&series[i], &total} {
+			point.GMV += day.GMV
+			point.Transactions += day.Transactions
+			point.Orders += day.Orders
+			point.UnitsSold += day.UnitsSold
+		}
+	}
+	for i := range series {
+		series[i].AOV = ratio(float64(series[i].GMV), series[i].Transactions)
+	}
+	total.AOV = ratio(float64(total.GMV), total.Transactions)
+
+	return models.AdminGMVReport{
+		AdminReportRange: period.bucketed(),
+		Total:            total,
+		Series:           series,
+	}, nil
+}
+
+func (service *adminReportServiceImpl) OrdersByStatus(input models.AdminReportRequest) (models.AdminOrderStatusReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminOrderStatusReport{}, err
+	}
+
+	stats, err := service.repository.CountOrdersByStatus(period.from, period.to)
+	if err != nil {
+		return models.AdminOrderStatusReport{}, err
+	}
+
+	// Every status is listed, in lifecycle order, empty ones too
+	counts := map[string]models.AdminOrderStatusStat{}
+	total := 0
+	for _, stat := range stats {
+		counts[stat.Status] = stat
+		total += stat.Orders
+	}
+
+	statuses := []models.AdminOrderStatusStat{}
+	for _, status := range models.OrderStatuses {
+		stat := counts[status]
+		stat.Status = status
+		stat.Share = ratio(float64(stat.Orders), total)
+		statuses = append(statuses, stat)
+	}
+
+	return models.AdminOrderStatusReport{
+		AdminReportRange: period.info,
+		Total:            total,
+		Statuses:         statuses,
+	}, nil
+}
+
+func (service *adminReportServiceImpl) Signups(input models.AdminReportRequest) (models.AdminSignupReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminSignupReport{}, err
+	}
+
+	users, err := service.repository.CountNewUsers(period.from, period.to)
+	if err != nil {
+		return models.AdminSignupReport{}, err
+	}
+
+	stores, err := service.repository.CountNewStores(period.from, period.to)
+	if err != nil {
+		return models.AdminSignupReport{}, err
+	}
+
+	buckets, index := period.series()
+	series := make([]models.AdminSignupPoint, len(buckets))
+	for i, date := range buckets {
+		series[i].Date = date
+	}
+
+	total := models.AdminSignupPoint{}
+	for _, day := range users {
+		if i, ok := index[day.Date]; ok {
+			series[i].Users += day.Count
+			total.Users += day.Count
+		}
+	}
+	for _, day := range stores {
+		if i, ok := index[day.Date]; ok {
+			series[i].Stores += day.Count
+			total.Stores += day.Count
+		}
+	}
+
+	return models.AdminSignupReport{
+		AdminReportRange: period.bucketed(),
+		Total:            total,
+		Series:           series,
+	}, nil
+}
+
+func (service *adminReportServiceImpl) TopCategories(input models.AdminReportRequest) (models.AdminTopReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminTopReport{}, err
+	}
+
+	entries, err := service.repository.FindTopCategories(period.from, period.to, period.limit)
+	if err != nil {
+		return models.AdminTopReport{}, err
+	}
+	if entries == nil {
+		entries = []models.AdminTopEntry{}
+	}
+
+	return models.AdminTopReport{
+		AdminReportRange: period.info,
+		Items:            entries,
+	}, nil
+}
+
+func (service *adminReportServiceImpl) TopStores(input models.AdminReportRequest) (models.AdminTopReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminTopReport{}, err
+	}
+
+	entries, err := service.repository.FindTopStores(period.from, period.to, period.limit)
+	if err != nil {
+		return models.AdminTopReport{}, err
+	}
+	if entries == nil {
+		entries = []models.AdminTopEntry{}
+	}
+
+	return models.AdminTopReport{
+		AdminReportRange: period.info,
+		Items:            entries,
+	}, nil
+}
+
+func (service *adminReportServiceImpl) Refunds(input models.AdminReportRequest) (models.AdminRefundReport, error) {
+	period, err := newReportPeriod(input)
+	if err != nil {
+		return models.AdminRefundReport{}, err
+	}
+
+	sales, err := service.repository.FindDailySales(period.from, period.to)
+	if err != nil {
+		return models.AdminRefundReport{}, err
+	}
+
+	buckets, index := period.series()
+	series := make([]models.AdminRefundPoint, len(buckets))
+	for i, date := range buckets {
+		series[i].Date = date
+	}
+
+	total := models.AdminRefundPoint{}
+	for _, day := range sales {
+		i, ok := index[day.Date]
+		if !ok {
+			continue
+		}
+		for _, point := range []*models.AdminRefundPoint{&series[i], &total} {
+			point.Orders += day.Orders
+			point.Refunded += day.Refunded
+			point.RefundedAmount += day.RefundedAmount
+		}
+	}
+	for i := range series {
+		series[i].RefundRate = ratio(float64(series[i].Refunded), series[i].Orders)
+	}
+	total.RefundRate = ratio(float64(total.Refunded), total.Orders)
+
+	return models.AdminRefundReport{
+		AdminReportRange: period.bucketed(),
+		Total:            total,
+		Series:           series,
+	}, nil
+}
+
+// Export runs a report and returns a function writing it as a spreadsheet,
+// header first. Reports with a series list its buckets, without the total.
+func (service *adminReportServiceImpl) Export(report string, input models.AdminReportRequest, format string) (func(w io.Writer) error, error) {
+	if err := spreadsheet.Validate(format); err != nil {
+		return nil, err
+	}
+
+	rows, err := service.reportRows(report, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		writer, err := spreadsheet.NewWriter(format, w)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+		}
+
+		return writer.Close()
+	}, nil
+}
+
+func (service *adminReportServiceImpl) reportRows(report string, input models.AdminReportRequest) ([][]string, error) {
+	switch report {
+	case models.AdminReportGMV:
+		response, err := service.GMV(input)
+		if err != nil {
+			return nil, err
+		}
+		rows := [][]string{{"date", "gmv", "transactions", "orders", "units_sold", "aov"}}
+		for _, point := range response.Series {
+			rows = append(rows, []string{point.Date, formatInt(point.GMV), strconv.Itoa(point.Transactions), strconv.Itoa(point.Orders), strconv.Itoa(point.UnitsSold), formatRate(point.AOV)})
+		}
+		return rows, nil
+
+	case models.AdminReportOrders:
+		response, err := service.OrdersByStatus(input)
+		if err != nil {
+			return nil, err
+		}
+		rows := [][]string{{"status", "orders", "amount", "share"}}
+		for _, stat := range response.Statuses {
+			rows = append(rows, []string{stat.Status, strconv.Itoa(stat.Orders), formatInt(stat.Amount), formatRate(stat.Share)})
+		}
+		return rows, nil
+
+	case models.AdminReportSignups:
+		response, err := service.Signups(input)
+		if err != nil {
+			return nil, err
+		}
+		rows := [][]string{{"date", "users", "stores"}}
+		for _, point := range response.Series {
+			rows = append(rows, []string{point.Date, strconv.Itoa(point.Users), strconv.Itoa(point.Stores)})
+		}
+		return rows, nil
+
+	case models.AdminReportTopCategories, models.AdminReportTopStores:
+		var response models.AdminTopReport
+		var err error
+		if report == models.AdminReportTopCategories {
+			response, err = service.TopCategories(input)
+		} else {
+			response, err = service.TopStores(input)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows := [][]string{{"id", "name", "gmv", "orders", "units_sold"}}
+		for _, entry := range response.Items {
+			rows = append(rows, []string{strconv.FormatUint(uint64(entry.ID), 10), entry.Name, formatInt(entry.GMV), strconv.Itoa(entry.Orders), strconv.Itoa(entry.UnitsSold)})
+		}
+		return rows, nil
+
+	case models.AdminReportRefunds:
+		response, err := service.Refunds(input)
+		if err != nil {
+			return nil, err
+		}
+		rows := [][]string{{"date", "orders", "refunded", "refunded_amount", "refund_rate"}}
+		for _, point := range response.Series {
+			rows = append(rows, []string{point.Date, strconv.Itoa(point.Orders), strconv.Itoa(point.Refunded), formatInt(point.RefundedAmount), formatRate(point.RefundRate)})
+		}
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("unknown report %q", report)
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+// formatRate writes ratios and averages with four decimals
+func formatRate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
@@ -1,9 +1,18 @@
 package services
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
 	"mini-project-evermos/repositories"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Contract
@@ -13,12 +22,24 @@ type CategoryService interface {
 	Create(payload models.CategoryRequest) (models.CategoryResponse, error)
 	Edit(id uint, payload models.CategoryRequest) (models.CategoryResponse, error)
 	Delete(id uint) (models.CategoryResponse, error)
+	GetPublicAll() ([]models.CategoryResponse, error)
+	GetTree() ([]models.CategoryResponse, error)
+	GetPublicDetail(key string) (models.CategoryResponse, error)
 }
 
 type categoryServiceImpl struct {
 	repository repositories.CategoryRepository
+	mutex      sync.Mutex
+	snapshot   *categorySnapshot
 }
 
+// categoryCacheTTL bounds how stale public product counts can get; admin
+// changes to categories clear the cache right away.
+const categoryCacheTTL = 5 * time.Minute
+
+// attributeKode keeps attribute codes usable as form and JSON keys.
+var attributeKode = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+
 func NewCategoryService(categoryRepository *repositories.CategoryRepository) CategoryService {
 	return &categoryServiceImpl{
 		repository: *categoryRepository,
@@ -36,19 +57,14 @@ func (service *categoryServiceImpl) GetAll() ([]models.CategoryResponse, error)
 	responses := []models.CategoryResponse{}
 
 	for _, category := range categories {
-		response := models.CategoryResponse{
-			ID:           category.ID,
-			NamaCategory: category.NamaCategory,
-			CreatedAt:    category.CreatedAt,
-			UpdatedAt:    category.UpdatedAt,
-		}
-
-		responses = append(responses, response)
+		responses = append(responses, models.ToCategoryResponse(category))
 	}
 
 	return responses, nil
 }
 
+// GetById returns a category with its breadcrumbs and the attributes products
+// in it must fill in, inherited ones included.
 func (service *categoryServiceImpl) GetById(id uint) (models.CategoryResponse, error) {
 	category, err := service.repository.FindById(id)
 
@@ -56,11 +72,21 @@ func (service *categoryServiceImpl) GetById(id uint) (models.CategoryResponse, e
 		return models.CategoryResponse{}, err
 	}
 
-	var response = models.CategoryResponse{
-		ID:           category.ID,
-		NamaCategory: category.NamaCategory,
-		CreatedAt:    category.CreatedAt,
-		UpdatedAt:    category.UpdatedAt,
+	ancestors, err := service.repository.FindAncestors(category)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+
+	attributes, err := service.repository.FindAttributes(category)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+
+	response := models.ToCategoryResponse(category)
+	response.Breadcrumbs = models.ToCategoryBreadcrumbs(ancestors)
+	response.Attributes = []models.CategoryAttributeResponse{}
+	for _, attribute := range attributes {
+		response.Attributes = append(response.Attributes, models.ToCategoryAttributeResponse(attribute))
 	}
 
 	return response, nil
@@ -69,43 +95,93 @@ func (service *categoryServiceImpl) GetById(id uint) (models.CategoryResponse, e
 func (service *categoryServiceImpl) Create(payload models.CategoryRequest) (models.CategoryResponse, error) {
 	category := entities.Category{}
 	category.NamaCategory = payload.NamaCategory
+	if payload.ParentID != nil && *payload.ParentID != 0 {
+		category.IDParent = payload.ParentID
+	}
 
-	result, err := service.repository.Insert(category)
+	attributes, err := toCategoryAttributes(payload.Attributes)
 	if err != nil {
 		return models.CategoryResponse{}, err
 	}
 
-	response := models.CategoryResponse{
-		ID:           result.ID,
-		NamaCategory: result.NamaCategory,
-		CreatedAt:    result.CreatedAt,
-		UpdatedAt:    result.UpdatedAt,
+	if category.IDParent != nil {
+		parent, err := service.repository.FindById(*category.IDParent)
+		if err != nil {
+			return models.CategoryResponse{}, fmt.Errorf("parent category %d not found", *category.IDParent)
+		}
+		if err := service.checkInheritedClash(parent, attributes, nil); err != nil {
+			return models.CategoryResponse{}, err
+		}
 	}
-	return response, nil
+
+	result, err := service.repository.Insert(category, attributes)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+	service.invalidate()
+
+	return service.GetById(result.ID)
 }
 
 func (service *categoryServiceImpl) Edit(id uint, payload models.CategoryRequest) (models.CategoryResponse, error) {
 	//check
-	_, err := service.repository.FindById(id)
+	current, err := service.repository.FindById(id)
 	if err != nil {
 		return models.CategoryResponse{}, err
 	}
 
 	category := entities.Category{}
 	category.NamaCategory = payload.NamaCategory
+	category.IDParent = current.IDParent
+	if payload.ParentID != nil {
+		category.IDParent = nil
+		if *payload.ParentID != 0 {
+			category.IDParent = payload.ParentID
+		}
+	}
 
-	result, err := service.repository.Update(id, category)
+	attributes, err := toCategoryAttributes(payload.Attributes)
 	if err != nil {
 		return models.CategoryResponse{}, err
 	}
 
-	response := models.CategoryResponse{
-		ID:           result.ID,
-		NamaCategory: result.NamaCategory,
-		CreatedAt:    result.CreatedAt,
-		UpdatedAt:    result.UpdatedAt,
+	// The category's own schema, new or kept, plus everything below it must
+	// not redefine a code inherited from the (possibly new) ancestors
+	own := attributes
+	if own == nil {
+		if own, err = service.ownAttributes(current); err != nil {
+			return models.CategoryResponse{}, err
+		}
 	}
-	return response, nil
+
+	descendants, err := service.repository.FindDescendantAttributes(current)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+	if err := checkAttributeCodes(own, descendants); err != nil {
+		return models.CategoryResponse{}, err
+	}
+
+	if category.IDParent != nil {
+		parent, err := service.repository.FindById(*category.IDParent)
+		if err != nil {
+			return models.CategoryResponse{}, fmt.Errorf("parent category %d not found", *category.IDParent)
+		}
+		if strings.HasPrefix(parent.Path, current.Path) {
+			return models.CategoryResponse{}, errors.New("category cannot be moved under itself or one of its subcategories")
+		}
+		if err := service.checkInheritedClash(parent, own, descendants); err != nil {
+			return models.CategoryResponse{}, err
+		}
+	}
+
+	result, err := service.repository.Update(id, category, attributes)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+	service.invalidate()
+
+	return service.GetById(result.ID)
 }
 
 func (service *categoryServiceImpl) Delete(id uint) (models.CategoryResponse, error) {
@@ -115,16 +191,349 @@ func (service *categoryServiceImpl) Delete(id uint) (models.CategoryResponse, er
 		return models.CategoryResponse{}, err
 	}
 
+	children, err := service.repository.CountChildren(id)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+	if children > 0 {
+		return models.CategoryResponse{}, errors.New("category still has subcategories")
+	}
+
 	_, err = service.repository.Destroy(id)
 	if err != nil {
 		return models.CategoryResponse{}, err
 	}
+	service.invalidate()
+
+	return models.ToCategoryResponse(category), nil
+}
+
+// GetPublicAll lists every category with its product count, parents first.
+func (service *categoryServiceImpl) GetPublicAll() ([]models.CategoryResponse, error) {
+	snapshot, err := service.cached()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.CategoryResponse{}
+	for _, category := range snapshot.categories {
+		responses = append(responses, snapshot.response(category))
+	}
+
+	return responses, nil
+}
+
+// GetTree returns the root categories with their subcategories nested.
+func (service *categoryServiceImpl) GetTree() ([]models.CategoryResponse, error) {
+	snapshot, err := service.cached()
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot.tree(nil), nil
+}
+
+// GetPublicDetail looks a category up by id or slug.
+func (service *categoryServiceImpl) GetPublicDetail(key string) (models.CategoryResponse, error) {
+	snapshot, err := service.cached()
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
 
-	response := models.CategoryResponse{
-		ID:           category.ID,
-		NamaCategory: category.NamaCategory,
-		CreatedAt:    category.CreatedAt,
-		UpdatedAt:    category.UpdatedAt,
+	id, ok := snapshot.bySlug[key]
+	if parsed, err := strconv.ParseUint(key, 10, 64); !ok && err == nil {
+		id = uint(parsed)
+	}
+	category, found := snapshot.byId[id]
+	if !found {
+		return models.CategoryResponse{}, fmt.Errorf("category %q not found", key)
 	}
+
+	ancestors := snapshot.ancestors(category)
+
+	response := snapshot.response(category)
+	response.Breadcrumbs = models.ToCategoryBreadcrumbs(ancestors)
+	response.Attributes = []models.CategoryAttributeResponse{}
+	for _, ancestor := range ancestors {
+		for _, attribute := range snapshot.attributes[ancestor.ID] {
+			response.Attributes = append(response.Attributes, models.ToCategoryAttributeResponse(attribute))
+		}
+	}
+	response.Children = []models.CategoryResponse{}
+	for _, child := range snapshot.children[category.ID] {
+		response.Children = append(response.Children, snapshot.response(child))
+	}
+
 	return response, nil
 }
+
+// cached returns the public category snapshot, rebuilding it when expired.
+func (service *categoryServiceImpl) cached() (*categorySnapshot, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.snapshot != nil && time.Since(service.snapshot.built) < categoryCacheTTL {
+		return service.snapshot, nil
+	}
+
+	categories, err := service.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := service.repository.FindAllAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := service.repository.CountProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	service.snapshot = newCategorySnapshot(categories, attributes, counts)
+	return service.snapshot, nil
+}
+
+func (service *categoryServiceImpl) invalidate() {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	service.snapshot = nil
+}
+
+// ownAttributes returns the attributes category defines itself.
+func (service *categoryServiceImpl) ownAttributes(category entities.Category) ([]entities.CategoryAttribute, error) {
+	attributes, err := service.repository.FindAttributes(category)
+	if err != nil {
+		return nil, err
+	}
+
+	own := []entities.CategoryAttribute{}
+	for _, attribute := range attributes {
+		if attribute.IDCategory == category.ID {
+			own = append(own, attribute)
+		}
+	}
+	return own, nil
+}
+
+// checkInheritedClash rejects attribute codes that parent or its ancestors
+// already define.
+func (service *categoryServiceImpl) checkInheritedClash(parent entities.Category, groups ...[]entities.CategoryAttribute) error {
+	inherited, err := service.repository.FindAttributes(parent)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if err := checkAttributeCodes(inherited, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAttributeCodes(inherited []entities.CategoryAttribute, attributes []entities.CategoryAttribute) error {
+	for _, attribute := range attributes {
+		for _, existing := range inherited {
+			if existing.Kode == attribute.Kode {
+				return fmt.Errorf("attribute %q is already defined by category %d", attribute.Kode, existing.IDCategory)
+			}
+		}
+	}
+	return nil
+}
+
+// toCategoryAttributes validates an attribute schema. nil stays nil so the
+// repository keeps the current schema.
+func toCategoryAttributes(requests []models.CategoryAttributeRequest) ([]entities.CategoryAttribute, error) {
+	if requests == nil {
+		return nil, nil
+	}
+
+	attributes := []entities.CategoryAttribute{}
+	seen := map[string]bool{}
+	for _, request := range requests {
+		kode := strings.TrimSpace(request.Kode)
+		if !attributeKode.MatchString(kode) {
+			return nil, fmt.Errorf("invalid attribute kode %q, use lowercase letters, digits and underscores", request.Kode)
+		}
+		if seen[kode] {
+			return nil, fmt.Errorf("attribute %q is defined twice", kode)
+		}
+		seen[kode] = true
+
+		if !slices.Contains(models.AttributeTypes, request.Tipe) {
+			return nil, fmt.Errorf("invalid tipe %q for attribute %q, expected one of %s", request.Tipe, kode, strings.Join(models.AttributeTypes, ", "))
+		}
+
+		nama := strings.TrimSpace(request.Nama)
+		if nama == "" {
+			nama = kode
+		}
+
+		attribute := entities.CategoryAttribute{
+			Kode:  kode,
+			Nama:  nama,
+			Tipe:  request.Tipe,
+			Wajib: request.Wajib,
+		}
+
+		if request.Tipe == models.AttributeOption {
+			if len(request.Pilihan) == 0 {
+				return nil, fmt.Errorf("attribute %q needs at least one pilihan", kode)
+			}
+			pilihan, err := json.Marshal(request.Pilihan)
+			if err != nil {
+				return nil, err
+			}
+			attribute.Pilihan = string(pilihan)
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes, nil
+}
+
+// validateProductAttributes checks product values against a category's
+// attribute schema and returns them trimmed, dropping empty optional ones.
+func validateProductAttributes(schema []entities.CategoryAttribute, values map[string]string) (map[string]string, error) {
+	problems := []string{}
+
+	known := map[string]bool{}
+	for _, attribute := range schema {
+		known[attribute.Kode] = true
+	}
+	for kode := range values {
+		if !known[kode] {
+			problems = append(problems, fmt.Sprintf("unknown attribute %q", kode))
+		}
+	}
+	slices.Sort(problems)
+
+	result := map[string]string{}
+	for _, attribute := range schema {
+		value := strings.TrimSpace(values[attribute.Kode])
+		if value == "" {
+			if attribute.Wajib {
+				problems = append(problems, fmt.Sprintf("attribute %q is required", attribute.Kode))
+			}
+			continue
+		}
+
+		switch attribute.Tipe {
+		case models.AttributeNumber:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				problems = append(problems, fmt.Sprintf("attribute %q must be a number", attribute.Kode))
+				continue
+			}
+		case models.AttributeOption:
+			pilihan := models.ToCategoryAttributeResponse(attribute).Pilihan
+			if !slices.Contains(pilihan, value) {
+				problems = append(problems, fmt.Sprintf("attribute %q must be one of %s", attribute.Kode, strings.Join(pilihan, ", ")))
+				continue
+			}
+		}
+
+		result[attribute.Kode] = value
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return result, nil
+}
+
+// categorySnapshot is the whole category tree held in memory for the public
+// read endpoints.
+type categorySnapshot struct {
+	categories []entities.Category // parents before children
+	byId       map[uint]entities.Category
+	bySlug     map[string]uint
+	children   map[uint][]entities.Category
+	attributes map[uint][]entities.CategoryAttribute
+	totals     map[uint]int64
+	built      time.Time
+}
+
+func newCategorySnapshot(categories []entities.Category, attributes []entities.CategoryAttribute, counts map[uint]int64) *categorySnapshot {
+	snapshot := &categorySnapshot{
+		categories: categories,
+		byId:       map[uint]entities.Category{},
+		bySlug:     map[string]uint{},
+		children:   map[uint][]entities.Category{},
+		attributes: map[uint][]entities.CategoryAttribute{},
+		totals:     map[uint]int64{},
+		built:      time.Now(),
+	}
+
+	for _, category := range categories {
+		snapshot.byId[category.ID] = category
+		snapshot.bySlug[category.Slug] = category.ID
+	}
+
+	for _, category := range categories {
+		// A category whose parent is gone is shown as a root
+		parent_id := uint(0)
+		if category.IDParent != nil {
+			if _, ok := snapshot.byId[*category.IDParent]; ok {
+				parent_id = *category.IDParent
+			}
+		}
+		snapshot.children[parent_id] = append(snapshot.children[parent_id], category)
+
+		// Products count towards their category and every ancestor
+		for _, ancestor := range snapshot.ancestors(category) {
+			snapshot.totals[ancestor.ID] += counts[category.ID]
+		}
+	}
+
+	for _, attribute := range attributes {
+		snapshot.attributes[attribute.IDCategory] = append(snapshot.attributes[attribute.IDCategory], attribute)
+	}
+
+	return snapshot
+}
+
+// ancestors walks up from category, returning the root first.
+func (snapshot *categorySnapshot) ancestors(category entities.Category) []entities.Category {
+	chain := []entities.Category{category}
+	seen := map[uint]bool{category.ID: true}
+	for category.IDParent != nil {
+		parent, ok := snapshot.byId[*category.IDParent]
+		if !ok || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		chain = append(chain, parent)
+		category = parent
+	}
+
+	slices.Reverse(chain)
+	return chain
+}
+
+func (snapshot *categorySnapshot) response(category entities.Category) models.CategoryResponse {
+	total := snapshot.totals[category.ID]
+
+	response := models.ToCategoryResponse(category)
+	response.JumlahProduk = &total
+	return response
+}
+
+func (snapshot *categorySnapshot) tree(parent *entities.Category) []models.CategoryResponse {
+	parent_id := uint(0)
+	if parent != nil {
+		parent_id = parent.ID
+	}
+
+	nodes := []models.CategoryResponse{}
+	for _, child := range snapshot.children[parent_id] {
+		node := snapshot.response(child)
+		node.Children = snapshot.tree(&child)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"mini-project-evermos/models"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/media"
+	"mini-project-evermos/utils/storage"
+	"path/filepath"
+)
+
+// Contract
+type MediaService interface {
+	Upload(file *multipart.FileHeader) (models.MediaResponse, error)
+	Remove(name string)
+	Exists(name string) error
+	Stat(name string) (storage.Object, error)
+	Open(name string, offset int64, length int64) (io.ReadCloser, error)
+}
+
+type mediaServiceImpl struct {
+	repository repositories.MediaRepository
+	storage    storage.BlobStorage
+	limits     media.Limits
+}
+
+// NewMediaService keeps processed image uploads in blobStorage
+func NewMediaService(mediaRepository *repositories.MediaRepository, blobStorage storage.BlobStorage, limits media.Limits) MediaService {
+	return &mediaServiceImpl{
+		repository: *mediaRepository,
+		storage:    blobStorage,
+		limits:     limits,
+	}
+}
+
+// Upload validates an image, strips its metadata and writes the original
+// with every thumbnail size. Files already stored are identical by name, so
+// they are left as they are.
+func (service *mediaServiceImpl) Upload(file *multipart.FileHeader) (models.MediaResponse, error) {
+	if service.limits.MaxBytes > 0 && file.Size > service.limits.MaxBytes {
+		return models.MediaResponse{}, fmt.Errorf("%s: image is larger than %d bytes", file.Filename, service.limits.MaxBytes)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return models.MediaResponse{}, err
+	}
+	defer src.Close()
+
+	// Read one byte past the limit so Process can reject what the header
+	// under-reported
+	reader := io.Reader(src)
+	if service.limits.MaxBytes > 0 {
+		reader = io.LimitReader(src, service.limits.MaxBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return models.MediaResponse{}, err
+	}
+
+	image, err := media.Process(data, service.limits)
+	if err != nil {
+		return models.MediaResponse{}, fmt.Errorf("%s: %w", file.Filename, err)
+	}
+
+	for _, v := range image.Files {
+		if _, err := service.storage.Stat(v.Name); err == nil {
+			continue
+		}
+		if err := service.storage.Put(v.Name, v.Data, image.MimeType); err != nil {
+			return models.MediaResponse{}, err
+		}
+	}
+
+	return models.MediaResponse{
+		Name:     image.Name,
+		MimeType: image.MimeType,
+		Width:    image.Width,
+		Height:   image.Height,
+		Sizes:    media.URLs(image.Name),
+	}, nil
+}
+
+// Remove deletes the files of an upload once no record points at it.
+// Names that are not plain upload names, such as links, are left alone.
+func (service *mediaServiceImpl) Remove(name string) {
+	if name == "" || filepath.Base(name) != name {
+		return
+	}
+
+	in_use, err := service.repository.InUse(name)
+	if err != nil || in_use {
+		return
+	}
+
+	for _, v := range media.FileNames(name) {
+		if err := service.storage.Delete(v); err != nil {
+			log.Printf("Failed to remove upload %s: %v", v, err)
+		}
+	}
+}
+
+// Exists checks that name was made by Upload and its files are still
+// stored, so names sent back by clients can only point at real uploads.
+func (service *mediaServiceImpl) Exists(name string) error {
+	if !media.IsHashed(name) {
+		return fmt.Errorf("%q is not an uploaded photo, upload it first", name)
+	}
+
+	_, err := service.storage.Stat(name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("photo %q not found, upload it first", name)
+	}
+	return err
+}
+
+func (service *mediaServiceImpl) Stat(name string) (storage.Object, error) {
+	return service.storage.Stat(name)
+}
+
+func (service *mediaServiceImpl) Open(name string, offset int64, length int64) (io.ReadCloser, error) {
+	return service.storage.Open(name, offset, length)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/notifier"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type NotificationService interface {
+	GetByUserId(user_id uint) ([]models.NotificationResponse, error)
+	MarkAsRead(id uint, user_id uint) (models.NotificationResponse, error)
+	Send(store_id uint, product_id *uint, tipe string, judul string, pesan string) error
+	SendToUser(user_id uint, product_id *uint, tipe string, judul string, pesan string) error
+}
+
+type notificationServiceImpl struct {
+	repository      repositories.NotificationRepository
+	repositoryStore repositories.StoreRepository
+	notifier        notifier.Notifier
+	slots           chan struct{}
+}
+
+// maxConcurrentDeliveries bounds how many notifications are forwarded to the
+// notifier at once.
+const maxConcurrentDeliveries = 4
+
+func NewNotificationService(
+	notificationRepository *repositories.NotificationRepository,
+	storeRepository *repositories.StoreRepository,
+	notifier notifier.Notifier,
+) NotificationService {
+	return &notificationServiceImpl{
+		repository:      *notificationRepository,
+		repositoryStore: *storeRepository,
+		notifier:        notifier,
+		slots:           make(chan struct{}, maxConcurrentDeliveries),
+	}
+}
+
+// GetByUserId returns the user's inbox: their own notifications and, for
+// sellers, those of their store.
+func (service *notificationServiceImpl) GetByUserId(user_id uint) ([]models.NotificationResponse, error) {
+	var store_id *uint
+	store, err := service.repositoryStore.FindByUserId(user_id)
+	if err == nil {
+		store_id = &store.ID
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	notifications, err := service.repository.FindByRecipient(user_id, store_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.NotificationResponse{}
+	for _, notification := range notifications {
+		responses = append(responses, toNotificationResponse(notification))
+	}
+
+	return responses, nil
+}
+
+func (service *notificationServiceImpl) MarkAsRead(id uint, user_id uint) (models.NotificationResponse, error) {
+	notification, err := service.repository.FindById(id)
+	if err != nil {
+		return models.NotificationResponse{}, err
+	}
+
+	if notification.IDUser == nil || *notification.IDUser != user_id {
+		store, err := service.repositoryStore.FindByUserId(user_id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.NotificationResponse{}, err
+		}
+
+		if notification.IDToko == nil || *notification.IDToko != store.ID {
+			return models.NotificationResponse{}, errors.New("forbidden")
+		}
+	}
+
+	_, err = service.repository.MarkAsRead(id)
+	if err != nil {
+		return models.NotificationResponse{}, err
+	}
+
+	updated, err := service.repository.FindById(id)
+	if err != nil {
+		return models.NotificationResponse{}, err
+	}
+
+	return toNotificationResponse(updated), nil
+}
+
+// Send stores the notification in the in-app inbox and forwards it to the
+// configured notifier in the background. Delivery failures are logged, not
+// returned, so the inbox stays the source of truth.
+func (service *notificationServiceImpl) Send(store_id uint, product_id *uint, tipe string, judul string, pesan string) error {
+	notification := entities.Notification{
+		IDToko:   &store_id,
+		IDProduk: product_id,
+		Tipe:     tipe,
+		Judul:    judul,
+		Pesan:    pesan,
+	}
+
+	_, err := service.repository.Insert(notification)
+	if err != nil {
+		return err
+	}
+
+	go service.deliver(fmt.Sprintf("toko %d", store_id), notifier.Message{
+		StoreID:   store_id,
+		ProductID: product_id,
+		Type:      tipe,
+		Title:     judul,
+		Body:      pesan,
+	})
+
+	return nil
+}
+
+// SendToUser is Send for notifications addressed to a user rather than a
+// store, such as wishlist alerts.
+func (service *notificationServiceImpl) SendToUser(user_id uint, product_id *uint, tipe string, judul string, pesan string) error {
+	notification := entities.Notification{
+		IDUser:   &user_id,
+		IDProduk: product_id,
+		Tipe:     tipe,
+		Judul:    judul,
+		Pesan:    pesan,
+	}
+
+	_, err := service.repository.Insert(notification)
+	if err != nil {
+		return err
+	}
+
+	go service.deliver(fmt.Sprintf("user %d", user_id), notifier.Message{
+		UserID:    user_id,
+		ProductID: product_id,
+		Type:      tipe,
+		Title:     judul,
+		Body:      pesan,
+	})
+
+	return nil
+}
+
+// deliver forwards a stored notification to the notifier outside the request
+// that sent it, so a slow webhook never holds up a save.
+func (service *notificationServiceImpl) deliver(recipient string, message notifier.Message) {
+	service.slots <- struct{}{}
+	defer func() { <-service.slots }()
+
+	if err := service.notifier.Notify(message); err != nil {
+		log.Printf("Failed to deliver notification to %s: %v", recipient, err)
+	}
+}
+
+func toNotificationResponse(notification entities.Notification) models.NotificationResponse {
+	return models.NotificationResponse{
+		ID:         notification.ID,
+		StoreID:    notification.IDToko,
+		UserID:     notification.IDUser,
+		ProductID:  notification.IDProduk,
+		Tipe:       notification.Tipe,
+		Judul:      notification.Judul,
+		Pesan:      notification.Pesan,
+		DibacaPada: notification.DibacaPada,
+		CreatedAt:  notification.CreatedAt,
+		UpdatedAt:  notification.UpdatedAt,
+	}
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/media"
+	"mini-project-evermos/utils/spreadsheet"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductImportService interface {
+	Import(store_id uint, user_id uint, filename string, data []byte, dry_run bool) (models.ProductImportResponse, error)
+	GetById(id uint, user_id uint) (models.ProductImportResponse, error)
+	Export(store_id uint, user_id uint, format string) (func(w io.Writer) error, error)
+	Recover() error
+}
+
+type productImportServiceImpl struct {
+	repository         repositories.ProductImportRepository
+	repositoryProduct  repositories.ProductRepository
+	repositoryCategory repositories.CategoryRepository
+	productService     ProductService
+	mediaService       MediaService
+	slots              chan struct{}
+	access             storeAccess
+}
+
+const (
+	// maxConcurrentImports bounds how many import jobs run at once.
+	maxConcurrentImports = 2
+	// maxImportErrors caps the row errors kept per job.
+	maxImportErrors = 1000
+	// importProgressEvery is how many rows pass between progress saves.
+	importProgressEvery = 100
+	exportBatchSize     = 200
+	photoSeparator      = "|"
+)
+
+func NewProductImportService(
+	productImportRepository *repositories.ProductImportRepository,
+	productRepository *repositories.ProductRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+	categoryRepository *repositories.CategoryRepository,
+	productService *ProductService,
+	mediaService *MediaService,
+) ProductImportService {
+	return &productImportServiceImpl{
+		repository:         *productImportRepository,
+		repositoryProduct:  *productRepository,
+		repositoryCategory: *categoryRepository,
+		productService:     *productService,
+		mediaService:       *mediaService,
+		slots:              make(chan struct{}, maxConcurrentImports),
+		access:             newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+// Import queues a file for import and returns the job right away; progress
+// and row errors are read back through GetById.
+func (service *productImportServiceImpl) Import(store_id uint, user_id uint, filename string, data []byte, dry_run bool) (models.ProductImportResponse, error) {
+	store, err := service.access.store(store_id, user_id, models.StorePermissionManageProducts)
+	if err != nil {
+		return models.ProductImportResponse{}, err
+	}
+
+	format, err := spreadsheet.FormatOf(filename)
+	if err != nil {
+		return models.ProductImportResponse{}, err
+	}
+
+	job, err := service.repository.Insert(entities.ProductImport{
+		IDToko:   store.ID,
+		NamaFile: filename,
+		Format:   format,
+		DryRun:   dry_run,
+		Status:   models.ImportQueued,
+	})
+	if err != nil {
+		return models.ProductImportResponse{}, err
+	}
+
+	go service.run(job, user_id, data)
+
+	return toProductImportResponse(job), nil
+}
+
+func (service *productImportServiceImpl) GetById(id uint, user_id uint) (models.ProductImportResponse, error) {
+	job, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductImportResponse{}, err
+	}
+
+	if err := service.access.check(job.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return models.ProductImportResponse{}, err
+	}
+
+	return toProductImportResponse(job), nil
+}
+
+// Export checks the request up front and returns a function that streams the
+// store's catalog in the import column schema.
+func (service *productImportServiceImpl) Export(store_id uint, user_id uint, format string) (func(w io.Writer) error, error) {
+	if err := spreadsheet.Validate(format); err != nil {
+		return nil, err
+	}
+
+	store, err := service.access.store(store_id, user_id, models.StorePermissionManageProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		writer, err := spreadsheet.NewWriter(format, w)
+		if err != nil {
+			return err
+		}
+
+		if err := writer.WriteRow(models.ProductImportColumns); err != nil {
+			return err
+		}
+
+		err = service.repositoryProduct.FindByStoreInBatches(store.ID, exportBatchSize, func(products []entities.Product) error {
+			for _, product := range products {
+				if err := writer.WriteRow(toImportRow(product)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return writer.Close()
+	}, nil
+}
+
+// Recover fails the jobs a previous run left queued or running. Jobs only
+// live in this process's memory, together with their file, so they can not
+// be picked up again; the store has to upload the file once more.
+func (service *productImportServiceImpl) Recover() error {
+	failed, err := service.repository.FailUnfinished("import was interrupted by a server restart, please upload the file again", time.Now())
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		log.Printf("Product import: failed %d jobs interrupted by a restart", failed)
+	}
+	return nil
+}
+
+func (service *productImportServiceImpl) run(job entities.ProductImport, user_id uint, data []byte) {
+	service.slots <- struct{}{}
+	defer func() { <-service.slots }()
+
+	job.Status = models.ImportRunning
+	service.save(&job, nil)
+
+	row_errors, err := service.process(&job, user_id, data)
+
+	now := time.Now()
+	job.SelesaiPada = &now
+	job.Status = models.ImportCompleted
+	if err != nil {
+		message := err.Error()
+		job.Status = models.ImportFailed
+		job.Pesan = &message
+	}
+	service.save(&job, row_errors)
+}
+
+// save stores the job's progress. A job that cannot be saved is only
+// logged, the import itself carries on.
+func (service *productImportServiceImpl) save(job *entities.ProductImport, row_errors []models.ImportRowError) {
+	if row_errors != nil {
+		encoded, err := json.Marshal(row_errors)
+		if err == nil {
+			job.Kesalahan = string(encoded)
+		}
+	}
+
+	if err := service.repository.Update(*job); err != nil {
+		log.Printf("Failed to save product import %d: %v", job.ID, err)
+	}
+}
+
+// importRow is one parsed line of an import file.
+type importRow struct {
+	number int
+	sku    string
+	slug   string
+	input  models.ProductRequest
+}
+
+func (service *productImportServiceImpl) process(job *entities.ProductImport, user_id uint, data []byte) ([]models.ImportRowError, error) {
+	rows, err := spreadsheet.Read(job.Format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	header, err := parseImportHeader(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := service.repositoryCategory.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	category_ids := map[uint]entities.Category{}
+	for _, category := range categories {
+		category_ids[category.ID] = category
+	}
+	schemas := map[uint][]entities.CategoryAttribute{}
+
+	row_errors := []models.ImportRowError{}
+	seen := map[string]int{}
+
+	for i, values := range rows[1:] {
+		if isBlankRow(values) {
+			continue
+		}
+		job.TotalBaris++
+
+		row, problems := parseImportRow(i+2, header, values)
+
+		// The same product twice in one file would silently overwrite itself
+		for _, pair := range [][2]string{{"sku", row.sku}, {"slug", row.slug}} {
+			column, value := pair[0], pair[1]
+			if value == "" {
+				continue
+			}
+			key := column + ":" + value
+			if first, ok := seen[key]; ok {
+				problems = append(problems, models.ImportRowError{Row: row.number, Column: column, Message: fmt.Sprintf("duplicate of row %d", first)})
+			} else {
+				seen[key] = row.number
+			}
+		}
+
+		if _, ok := category_ids[row.input.CategoryID]; row.input.CategoryID != 0 && !ok {
+			problems = append(problems, models.ImportRowError{Row: row.number, Column: "category_id", Message: fmt.Sprintf("category %d not found", row.input.CategoryID)})
+		}
+
+		var existing *entities.Product
+		if len(problems) == 0 {
+			existing, err = service.findExisting(job.IDToko, row)
+			if err != nil {
+				problems = append(problems, models.ImportRowError{Row: row.number, Message: err.Error()})
+			}
+		}
+
+		// Checked here as well so a dry run rejects what the import would
+		if len(problems) == 0 {
+			category := category_ids[row.input.CategoryID]
+			schema, ok := schemas[category.ID]
+			if !ok {
+				schema, err = service.repositoryCategory.FindAttributes(category)
+				if err != nil {
+					return nil, err
+				}
+				schemas[category.ID] = schema
+			}
+
+			var current []entities.ProductAttribute
+			if existing != nil {
+				current = existing.Attributes
+			}
+			if _, err := productAttributeValues(schema, row.input.Attributes, current); err != nil {
+				problems = append(problems, models.ImportRowError{Row: row.number, Column: "atribut", Message: err.Error()})
+			}
+
+			var pictures []entities.ProductPicture
+			if existing != nil {
+				pictures = existing.ProductPicture
+			}
+			if err := checkProductPhotos(service.mediaService, row.input.PhotoURLs, pictures); err != nil {
+				problems = append(problems, models.ImportRowError{Row: row.number, Column: "photo_urls", Message: err.Error()})
+			}
+		}
+
+		if len(problems) == 0 && !job.DryRun {
+			err = service.apply(row, existing, job.IDToko, user_id)
+			if err != nil {
+				problems = append(problems, models.ImportRowError{Row: row.number, Message: err.Error()})
+			}
+		}
+
+		switch {
+		case len(problems) > 0:
+			job.Gagal++
+			for _, problem := range problems {
+				if len(row_errors) < maxImportErrors {
+					row_errors = append(row_errors, problem)
+				}
+			}
+		case existing != nil:
+			job.Diperbarui++
+		default:
+			job.Dibuat++
+		}
+
+		if job.TotalBaris%importProgressEvery == 0 {
+			service.save(job, row_errors)
+		}
+	}
+
+	return row_errors, nil
+}
+
+// findExisting resolves the product a row updates: by SKU first, then by
+// slug. A row with neither creates a new product.
+func (service *productImportServiceImpl) findExisting(store_id uint, row importRow) (*entities.Product, error) {
+	if row.sku != "" {
+		product, err := service.repositoryProduct.FindBySKU(store_id, row.sku)
+		if err == nil {
+			return &product, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if row.slug == "" {
+		return nil, nil
+	}
+
+	product, err := service.repositoryProduct.FindBySlug(row.slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("product with slug %q not found", row.slug)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product.IDToko != store_id {
+		return nil, fmt.Errorf("product with slug %q belongs to another store", row.slug)
+	}
+	if row.sku != "" && product.SKU != nil && *product.SKU != row.sku {
+		return nil, fmt.Errorf("product with slug %q already has sku %q", row.slug, *product.SKU)
+	}
+
+	return &product, nil
+}
+
+// apply writes a row through the product service so stock alerts and the
+// search index stay in step with manual edits.
+func (service *productImportServiceImpl) apply(row importRow, existing *entities.Product, store_id uint, user_id uint) error {
+	input := row.input
+	input.StoreID = store_id
+
+	if existing == nil {
+		_, err := service.productService.Create(input, user_id)
+		return err
+	}
+
+	_, err := service.productService.Update(input, existing.ID, user_id)
+	return err
+}
+
+func parseImportHeader(values []string) (map[string]int, error) {
+	header := map[string]int{}
+	for i, value := range values {
+		column := strings.ToLower(strings.TrimSpace(value))
+		if column == "" {
+			continue
+		}
+		if !slices.Contains(models.ProductImportColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", value, strings.Join(models.ProductImportColumns, ", "))
+		}
+		if _, ok := header[column]; ok {
+			return nil, fmt.Errorf("column %q appears more than once", column)
+		}
+		header[column] = i
+	}
+
+	for _, column := range models.ProductImportRequiredColumns {
+		if _, ok := header[column]; !ok {
+			return nil, fmt.Errorf("missing required column %q", column)
+		}
+	}
+
+	return header, nil
+}
+
+func parseImportRow(number int, header map[string]int, values []string) (importRow, []models.ImportRowError) {
+	row := importRow{number: number}
+	problems := []models.ImportRowError{}
+
+	cell := func(column string) string {
+		index, ok := header[column]
+		if !ok || index >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[index])
+	}
+	invalid := func(column string, message string) {
+		problems = append(problems, models.ImportRowError{Row: number, Column: column, Message: message})
+	}
+	integer := func(column string, required bool) *int {
+		value := cell(column)
+		if value == "" {
+			if required {
+				invalid(column, "is required")
+			}
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			invalid(column, fmt.Sprintf("%q is not a non-negative whole number", value))
+			return nil
+		}
+		return &n
+	}
+
+	row.sku = cell("sku")
+	if len(row.sku) > 100 {
+		invalid("sku", "must be at most 100 characters")
+	}
+	row.slug = cell("slug")
+
+	row.input.NamaProduk = cell("nama_produk")
+	switch {
+	case row.input.NamaProduk == "":
+		invalid("nama_produk", "is required")
+	case len(row.input.NamaProduk) > 255:
+		invalid("nama_produk", "must be at most 255 characters")
+	}
+
+	if category_id := integer("category_id", true); category_id != nil {
+		row.input.CategoryID = uint(*category_id)
+	}
+
+	// Prices are stored as text but must hold whole rupiah amounts
+	if integer("harga_reseller", true) != nil {
+		row.input.HargaReseller = cell("harga_reseller")
+	}
+	if integer("harga_konsumen", true) != nil {
+		row.input.HargaKonsumen = cell("harga_konsumen")
+	}
+
+	row.input.Stok = integer("stok", true)
+	row.input.StokMinimum = integer("stok_minimum", false)
+	row.input.SKU = row.sku
+	row.input.Deskripsi = cell("deskripsi")
+
+	if photos := cell("photo_urls"); photos != "" {
+		row.input.PhotoURLs = []string{}
+		for _, url := range strings.Split(photos, photoSeparator) {
+			if url = strings.TrimSpace(url); url != "" {
+				row.input.PhotoURLs = append(row.input.PhotoURLs, media.Name(url))
+			}
+		}
+	}
+
+	if atribut := cell("atribut"); atribut != "" {
+		if err := json.Unmarshal([]byte(atribut), &row.input.Attributes); err != nil {
+			invalid("atribut", "must be a JSON object of strings")
+		}
+	}
+
+	return row, problems
+}
+
+func isBlankRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// toImportRow lays a product out in models.ProductImportColumns order.
+func toImportRow(product entities.Product) []string {
+	sku := ""
+	if product.SKU != nil {
+		sku = *product.SKU
+	}
+	deskripsi := ""
+	if product.Deskripsi != nil {
+		deskripsi = *product.Deskripsi
+	}
+	photos := []string{}
+	for _, picture := range product.ProductPicture {
+		photos = append(photos, picture.Url)
+	}
+	atribut := ""
+	if len(product.Attributes) > 0 {
+		values := map[string]string{}
+		for _, attribute := range product.Attributes {
+			values[attribute.Kode] = attribute.Nilai
+		}
+		encoded, _ := json.Marshal(values)
+		atribut = string(encoded)
+	}
+
+	return []string{
+		sku,
+		product.Slug,
+		product.NamaProduk,
+		strconv.FormatUint(uint64(product.IDCategory), 10),
+		product.HargaReseller,
+		product.HargaKonsumen,
+		strconv.Itoa(product.Stok),
+		strconv.Itoa(product.StokMinimum),
+		deskripsi,
+		strings.Join(photos, photoSeparator),
+		atribut,
+	}
+}
+
+func toProductImportResponse(job entities.ProductImport) models.ProductImportResponse {
+	row_errors := []models.ImportRowError{}
+	if job.Kesalahan != "" {
+		if err := json.Unmarshal([]byte(job.Kesalahan), &row_errors); err != nil {
+			log.Printf("Failed to read errors of product import %d: %v", job.ID, err)
+		}
+	}
+
+	return models.ProductImportResponse{
+		ID:          job.ID,
+		Status:      job.Status,
+		NamaFile:    job.NamaFile,
+		Format:      job.Format,
+		DryRun:      job.DryRun,
+		TotalBaris:  job.TotalBaris,
+		Dibuat:      job.Dibuat,
+		Diperbarui:  job.Diperbarui,
+		Gagal:       job.Gagal,
+		Kesalahan:   row_errors,
+		Pesan:       job.Pesan,
+		SelesaiPada: job.SelesaiPada,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
@@ -1,16 +1,16 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"mini-project-evermos/models"
 	"mini-project-evermos/repositories"
 )
 
 type ProductLogService interface {
-	Create(input models.ProductLogProcess) (models.ProductLogResponse, error)
 	GetAll() ([]models.ProductLogResponse, error)
 	GetById(id uint) (models.ProductLogResponse, error)
-	Update(id uint, input models.ProductLogProcess) (models.ProductLogResponse, error)
-	Delete(id uint) (models.ProductLogResponse, error)
+	GetPriceHistory(product_id uint) (models.ProductPriceHistoryResponse, error)
 }
 
 type productLogServiceImpl struct {
@@ -23,28 +23,6 @@ func NewProductLogService(productLogRepository *repositories.ProductLogRepositor
 	}
 }
 
-func (service *productLogServiceImpl) Create(input models.ProductLogProcess) (models.ProductLogResponse, error) {
-	productLog, err := service.repository.Insert(input)
-	if err != nil {
-		return models.ProductLogResponse{}, err
-	}
-
-	response := models.ProductLogResponse{
-		ID:            productLog.ID,
-		ProductID:     productLog.IDProduk,
-		NamaProduk:    productLog.NamaProduk,
-		Slug:          productLog.Slug,
-		HargaReseller: productLog.HargaReseller,
-		HargaKonsumen: productLog.HargaKonsumen,
-		StoreID:       productLog.IDToko,
-		CategoryID:    productLog.IDCategory,
-		CreatedAt:     *productLog.CreatedAt,
-		UpdatedAt:     *productLog.UpdatedAt,
-	}
-
-	return response, nil
-}
-
 func (service *productLogServiceImpl) GetAll() ([]models.ProductLogResponse, error) {
 	productLogs, err := service.repository.FindAll()
 	if err != nil {
@@ -94,53 +72,35 @@ func (service *productLogServiceImpl) GetById(id uint) (models.ProductLogRespons
 	return response, nil
 }
 
-func (service *productLogServiceImpl) Update(id uint, input models.ProductLogProcess) (models.ProductLogResponse, error) {
-	productLog, err := service.repository.Update(id, input)
+// GetPriceHistory lists the prices a product had over time. Snapshots that
+// changed something other than the price are folded into the previous point.
+func (service *productLogServiceImpl) GetPriceHistory(product_id uint) (models.ProductPriceHistoryResponse, error) {
+	productLogs, err := service.repository.FindByProduct(product_id)
 	if err != nil {
-		return models.ProductLogResponse{}, err
+		return models.ProductPriceHistoryResponse{}, err
 	}
-
-	response := models.ProductLogResponse{
-		ProductID:     productLog.IDProduk,
-		NamaProduk:    productLog.NamaProduk,
-		Slug:          productLog.Slug,
-		HargaReseller: productLog.HargaReseller,
-		HargaKonsumen: productLog.HargaKonsumen,
-		Deskripsi:     *productLog.Deskripsi,
-		StoreID:       productLog.IDToko,
-		CategoryID:    productLog.IDCategory,
-		CreatedAt:     *productLog.CreatedAt,
-		UpdatedAt:     *productLog.UpdatedAt,
+	if len(productLogs) == 0 {
+		return models.ProductPriceHistoryResponse{}, errors.New("no price history for product " + fmt.Sprint(product_id))
 	}
 
-	return response, nil
-}
-
-func (service *productLogServiceImpl) Delete(id uint) (models.ProductLogResponse, error) {
-	// Get the product log before deletion
-	productLog, err := service.repository.FindById(id)
-	if err != nil {
-		return models.ProductLogResponse{}, err
+	response := models.ProductPriceHistoryResponse{
+		ProductID: product_id,
+		Prices:    []models.ProductPricePoint{},
 	}
+	for _, log := range productLogs {
+		response.NamaProduk = log.NamaProduk
 
-	// Create response before deleting
-	response := models.ProductLogResponse{
-		ProductID:     productLog.IDProduk,
-		NamaProduk:    productLog.NamaProduk,
-		Slug:          productLog.Slug,
-		HargaReseller: productLog.HargaReseller,
-		HargaKonsumen: productLog.HargaKonsumen,
-		Deskripsi:     *productLog.Deskripsi,
-		StoreID:       productLog.IDToko,
-		CategoryID:    productLog.IDCategory,
-		CreatedAt:     *productLog.CreatedAt,
-		UpdatedAt:     *productLog.UpdatedAt,
-	}
+		last := len(response.Prices) - 1
+		if last >= 0 && response.Prices[last].HargaReseller == log.HargaReseller && response.Prices[last].HargaKonsumen == log.HargaKonsumen {
+			continue
+		}
 
-	// Perform deletion
-	err = service.repository.Delete(id)
-	if err != nil {
-		return models.ProductLogResponse{}, err
+		response.Prices = append(response.Prices, models.ProductPricePoint{
+			LogID:         log.ID,
+			HargaReseller: log.HargaReseller,
+			HargaKonsumen: log.HargaKonsumen,
+			BerlakuSejak:  log.CreatedAt,
+		})
 	}
 
 	return response, nil
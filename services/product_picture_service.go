@@ -0,0 +1,290 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/media"
+)
+
+// Contract
+type ProductPictureService interface {
+	GetById(id uint, user_id uint) (models.ProductPictureResponse, error)
+	GetByProductId(product_id uint, user_id uint) ([]models.ProductPictureResponse, error)
+	Create(input models.ProductPictureRequest, user_id uint) (models.ProductPictureResponse, error)
+	CreateMany(product_id uint, file_names []string, user_id uint) ([]models.ProductPictureResponse, error)
+	Update(id uint, input models.ProductPictureRequest, user_id uint) (models.ProductPictureResponse, error)
+	Delete(id uint, user_id uint) (models.ProductPictureResponse, error)
+	Reorder(product_id uint, input models.ProductPictureOrderRequest, user_id uint) ([]models.ProductPictureResponse, error)
+	SetPrimary(id uint, user_id uint) ([]models.ProductPictureResponse, error)
+}
+
+type productPictureServiceImpl struct {
+	repository        repositories.ProductPictureRepository
+	repositoryProduct repositories.ProductRepository
+	mediaService      MediaService
+	suggestService    SuggestService
+	access            storeAccess
+}
+
+func NewProductPictureService(
+	productPictureRepository *repositories.ProductPictureRepository,
+	productRepository *repositories.ProductRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+	mediaService *MediaService,
+	suggestService *SuggestService,
+) ProductPictureService {
+	return &productPictureServiceImpl{
+		repository:        *productPictureRepository,
+		repositoryProduct: *productRepository,
+		mediaService:      *mediaService,
+		suggestService:    *suggestService,
+		access:            newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+func (service *productPictureServiceImpl) GetById(id uint, user_id uint) (models.ProductPictureResponse, error) {
+	picture, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	if _, err := service.checkProduct(picture.IDProduk, user_id); err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	return models.ToProductPictureResponse(picture), nil
+}
+
+func (service *productPictureServiceImpl) GetByProductId(product_id uint, user_id uint) ([]models.ProductPictureResponse, error) {
+	if _, err := service.checkProduct(product_id, user_id); err != nil {
+		return nil, err
+	}
+
+	return service.productPictures(product_id)
+}
+
+func (service *productPictureServiceImpl) Create(input models.ProductPictureRequest, user_id uint) (models.ProductPictureResponse, error) {
+	product, err := service.checkProduct(input.ProductID, user_id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	input.URL = media.Name(input.URL)
+	if input.URL == "" {
+		return models.ProductPictureResponse{}, errors.New("url is required")
+	}
+	if err := service.mediaService.Exists(input.URL); err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	picture, err := service.repository.Create(entities.ProductPicture{
+		IDProduk: input.ProductID,
+		Url:      input.URL,
+		AltText:  input.AltText,
+	})
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	if err := service.photosChanged(product); err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	return models.ToProductPictureResponse(picture), nil
+}
+
+// CreateMany attaches uploaded files to the product after its other photos.
+// The uploads are removed again when they cannot be attached.
+func (service *productPictureServiceImpl) CreateMany(product_id uint, file_names []string, user_id uint) ([]models.ProductPictureResponse, error) {
+	pictures, err := service.createMany(product_id, file_names, user_id)
+	if err != nil {
+		for _, v := range file_names {
+			service.mediaService.Remove(v)
+		}
+		return nil, err
+	}
+
+	return toProductPictureResponses(pictures), nil
+}
+
+func (service *productPictureServiceImpl) createMany(product_id uint, file_names []string, user_id uint) ([]entities.ProductPicture, error) {
+	if len(file_names) == 0 {
+		return nil, errors.New("no photos uploaded")
+	}
+	if len(file_names) > models.MaxProductPhotoUpload {
+		return nil, fmt.Errorf("at most %d photos can be uploaded at once", models.MaxProductPhotoUpload)
+	}
+
+	product, err := service.checkProduct(product_id, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	pictures := []entities.ProductPicture{}
+	for _, v := range file_names {
+		pictures = append(pictures, entities.ProductPicture{Url: v})
+	}
+
+	pictures, err = service.repository.CreateMany(product_id, pictures)
+	if err != nil {
+		return nil, err
+	}
+
+	return pictures, service.photosChanged(product)
+}
+
+// Update changes the url and alt text. Photos stay with their product, moving
+// one means deleting and creating it.
+func (service *productPictureServiceImpl) Update(id uint, input models.ProductPictureRequest, user_id uint) (models.ProductPictureResponse, error) {
+	picture, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	product, err := service.checkProduct(picture.IDProduk, user_id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	old_url, old_alt_text := picture.Url, picture.AltText
+	if input.URL != "" {
+		picture.Url = media.Name(input.URL)
+	}
+	if picture.Url != old_url {
+		if err := service.mediaService.Exists(picture.Url); err != nil {
+			return models.ProductPictureResponse{}, err
+		}
+	}
+	picture.AltText = input.AltText
+
+	picture, err = service.repository.Update(picture)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	if picture.Url != old_url {
+		service.mediaService.Remove(old_url)
+	}
+
+	if picture.Url != old_url || picture.AltText != old_alt_text {
+		if err := service.photosChanged(product); err != nil {
+			return models.ProductPictureResponse{}, err
+		}
+	}
+
+	return models.ToProductPictureResponse(picture), nil
+}
+
+func (service *productPictureServiceImpl) Delete(id uint, user_id uint) (models.ProductPictureResponse, error) {
+	picture, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	product, err := service.checkProduct(picture.IDProduk, user_id)
+	if err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	if err := service.repository.Delete(id); err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	if err := service.photosChanged(product); err != nil {
+		return models.ProductPictureResponse{}, err
+	}
+
+	return models.ToProductPictureResponse(picture), nil
+}
+
+func (service *productPictureServiceImpl) Reorder(product_id uint, input models.ProductPictureOrderRequest, user_id uint) ([]models.ProductPictureResponse, error) {
+	product, err := service.checkProduct(product_id, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.Reorder(product_id, input.IDs); err != nil {
+		return nil, err
+	}
+
+	if err := service.photosChanged(product); err != nil {
+		return nil, err
+	}
+
+	return service.productPictures(product_id)
+}
+
+func (service *productPictureServiceImpl) SetPrimary(id uint, user_id uint) ([]models.ProductPictureResponse, error) {
+	picture, err := service.repository.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := service.checkProduct(picture.IDProduk, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.repository.SetPrimary(id); err != nil {
+		return nil, err
+	}
+
+	if err := service.photosChanged(product); err != nil {
+		return nil, err
+	}
+
+	return service.productPictures(picture.IDProduk)
+}
+
+func (service *productPictureServiceImpl) productPictures(product_id uint) ([]models.ProductPictureResponse, error) {
+	pictures, err := service.repository.FindByProductId(product_id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProductPictureResponses(pictures), nil
+}
+
+// checkProduct makes sure the product exists and the user may manage the
+// products of its store
+func (service *productPictureServiceImpl) checkProduct(product_id uint, user_id uint) (entities.Product, error) {
+	product, err := service.repositoryProduct.FindById(product_id)
+	if err != nil {
+		return entities.Product{}, err
+	}
+
+	if err := service.access.check(product.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return entities.Product{}, err
+	}
+	return product, nil
+}
+
+// photosChanged sends a published product back to review after its photos
+// changed, the way ProductService.Update does, so approved products can not
+// be altered past the reviewers.
+func (service *productPictureServiceImpl) photosChanged(product entities.Product) error {
+	status := reviewStatus(product, true)
+	if status == "" {
+		return nil
+	}
+
+	if err := service.repositoryProduct.UpdateStatus(product.ID, status, nil, nil); err != nil {
+		return err
+	}
+
+	product.Status = status
+	service.suggestService.ProductSaved(product)
+	return nil
+}
+
+func toProductPictureResponses(pictures []entities.ProductPicture) []models.ProductPictureResponse {
+	responses := []models.ProductPictureResponse{}
+	for _, picture := range pictures {
+		responses = append(responses, models.ToProductPictureResponse(picture))
+	}
+	return responses
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"testing"
+)
+
+// The fakes embed the repository contracts and only implement what the
+// picture service calls; anything else panics.
+
+type fakeProducts struct {
+	repositories.ProductRepository
+	product    entities.Product
+	moderation []string
+}
+
+func (fake *fakeProducts) FindById(id uint) (entities.Product, error) {
+	return fake.product, nil
+}
+
+func (fake *fakeProducts) UpdateStatus(id uint, status string, alasan *string, user_id *uint) error {
+	fake.product.Status = status
+	fake.moderation = append(fake.moderation, status)
+	return nil
+}
+
+type fakePictures struct {
+	repositories.ProductPictureRepository
+}
+
+func (fake fakePictures) FindById(id uint) (entities.ProductPicture, error) {
+	return entities.ProductPicture{ID: id, IDProduk: 1, Url: "foto-lama.jpg"}, nil
+}
+
+func (fake fakePictures) FindByProductId(product_id uint) ([]entities.ProductPicture, error) {
+	return nil, nil
+}
+
+func (fake fakePictures) Create(picture entities.ProductPicture) (entities.ProductPicture, error) {
+	return picture, nil
+}
+
+func (fake fakePictures) CreateMany(product_id uint, pictures []entities.ProductPicture) ([]entities.ProductPicture, error) {
+	return pictures, nil
+}
+
+func (fake fakePictures) Update(picture entities.ProductPicture) (entities.ProductPicture, error) {
+	return picture, nil
+}
+
+func (fake fakePictures) Delete(id uint) error                      { return nil }
+func (fake fakePictures) Reorder(product_id uint, ids []uint) error { return nil }
+func (fake fakePictures) SetPrimary(id uint) error                  { return nil }
+
+type fakeOwners struct {
+	repositories.StoreMemberRepository
+}
+
+func (fake fakeOwners) FindByStoreAndUser(store_id uint, user_id uint) (entities.StoreMember, error) {
+	return entities.StoreMember{IDToko: store_id, IDUser: user_id, Peran: models.StoreRoleOwner}, nil
+}
+
+type fakeSuggestions struct {
+	SuggestService
+	saved []entities.Product
+}
+
+func (fake *fakeSuggestions) ProductSaved(product entities.Product) {
+	fake.saved = append(fake.saved, product)
+}
+
+func TestPhotoChangesSendPublishedProductsToReview(t *testing.T) {
+	uploaded := "0123456789abcdef0123456789abcdef.jpg"
+
+	tests := []struct {
+		name   string
+		change func(service ProductPictureService) error
+	}{
+		{"create", func(service ProductPictureService) error {
+			_, err := service.Create(models.ProductPictureRequest{ProductID: 1, URL: uploaded}, 7)
+			return err
+		}},
+		{"upload", func(service ProductPictureService) error {
+			_, err := service.CreateMany(1, []string{uploaded}, 7)
+			return err
+		}},
+		{"replace", func(service ProductPictureService) error {
+			_, err := service.Update(3, models.ProductPictureRequest{URL: uploaded}, 7)
+			return err
+		}},
+		{"delete", func(service ProductPictureService) error {
+			_, err := service.Delete(3, 7)
+			return err
+		}},
+		{"reorder", func(service ProductPictureService) error {
+			_, err := service.Reorder(1, models.ProductPictureOrderRequest{IDs: []uint{3}}, 7)
+			return err
+		}},
+		{"set primary", func(service ProductPictureService) error {
+			_, err := service.SetPrimary(3, 7)
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		for _, status := range []string{models.ProductStatusPublished, models.ProductStatusDraft} {
+			products := &fakeProducts{product: entities.Product{ID: 1, IDToko: 2, Status: status}}
+			suggestions := &fakeSuggestions{}
+			var productRepository repositories.ProductRepository = products
+			var pictureRepository repositories.ProductPictureRepository = fakePictures{}
+			var storeRepository repositories.StoreRepository
+			var memberRepository repositories.StoreMemberRepository = fakeOwners{}
+			var mediaService MediaService = storedMedia{uploaded: true}
+			var suggestService SuggestService = suggestions
+
+			service := NewProductPictureService(&pictureRepository, &productRepository, &storeRepository, &memberRepository, &mediaService, &suggestService)
+			if err := test.change(service); err != nil {
+				t.Errorf("%s %s: %v", test.name, status, err)
+				continue
+			}
+
+			if status == models.ProductStatusDraft {
+				if products.product.Status != models.ProductStatusDraft || len(products.moderation) != 0 {
+					t.Errorf("%s draft: moved to %s", test.name, products.product.Status)
+				}
+				continue
+			}
+
+			// Out of the catalog and the suggestions until reviewed again
+			if products.product.Status != models.ProductStatusPendingReview {
+				t.Errorf("%s: product is %s, want %s", test.name, products.product.Status, models.ProductStatusPendingReview)
+			}
+			if len(products.moderation) != 1 || products.moderation[0] != models.ProductStatusPendingReview {
+				t.Errorf("%s: recorded moderation %q", test.name, products.moderation)
+			}
+			if len(suggestions.saved) != 1 || suggestions.saved[0].Status == models.ProductStatusPublished {
+				t.Errorf("%s: suggestions were not refreshed", test.name)
+			}
+		}
+	}
+}
+
+func TestReviewStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		changed bool
+		want    string
+	}{
+		{models.ProductStatusPublished, true, models.ProductStatusPendingReview},
+		{models.ProductStatusPublished, false, ""},
+		{models.ProductStatusDraft, true, ""},
+		{models.ProductStatusRejected, true, ""},
+		{models.ProductStatusPendingReview, true, ""},
+	}
+
+	for _, test := range tests {
+		if got := reviewStatus(entities.Product{Status: test.status}, test.changed); got != test.want {
+			t.Errorf("reviewStatus(%s, %v) = %q, want %q", test.status, test.changed, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/repositories"
+	"strings"
+)
+
+// Notification types sent to stores about review outcomes
+const (
+	NotificationProductApproved = "product_approved"
+	NotificationProductRejected = "product_rejected"
+)
+
+type ProductReviewService interface {
+	GetQueue(limit int, page int) (responder.Pagination, error)
+	GetHistory(id uint) ([]models.ProductModerationResponse, error)
+	Approve(id uint, admin_id uint) (models.ProductResponse, error)
+	Reject(id uint, admin_id uint, alasan string) (models.ProductResponse, error)
+}
+
+type productReviewServiceImpl struct {
+	repository          repositories.ProductRepository
+	notificationService NotificationService
+	suggestService      SuggestService
+}
+
+func NewProductReviewService(productRepository *repositories.ProductRepository, notificationService *NotificationService, suggestService *SuggestService) ProductReviewService {
+	return &productReviewServiceImpl{
+		repository:          *productRepository,
+		notificationService: *notificationService,
+		suggestService:      *suggestService,
+	}
+}
+
+// GetQueue lists products waiting for review, oldest first.
+func (service *productReviewServiceImpl) GetQueue(limit int, page int) (responder.Pagination, error) {
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+	request.Sort = models.ProductSortOldest
+
+	return service.repository.FindAllPagination(request, models.ProductFilter{Status: models.ProductStatusPendingReview})
+}
+
+func (service *productReviewServiceImpl) GetHistory(id uint) ([]models.ProductModerationResponse, error) {
+	history, err := service.repository.FindModerationHistory(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.ProductModerationResponse{}
+	for _, entry := range history {
+		responses = append(responses, models.ProductModerationResponse{
+			ID:        entry.ID,
+			ProductID: entry.IDProduk,
+			Status:    entry.Status,
+			Alasan:    entry.Alasan,
+			UserID:    entry.IDUser,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (service *productReviewServiceImpl) Approve(id uint, admin_id uint) (models.ProductResponse, error) {
+	product, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if product.Status != models.ProductStatusPendingReview {
+		return models.ProductResponse{}, fmt.Errorf("only products pending review can be approved, this one is %s", product.Status)
+	}
+
+	if err := service.repository.UpdateStatus(id, models.ProductStatusPublished, nil, &admin_id); err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	judul := fmt.Sprintf("Approved: %s", product.NamaProduk)
+	pesan := fmt.Sprintf("%s passed review and is now visible in the catalog.", product.NamaProduk)
+
+	return service.reviewed(product, NotificationProductApproved, judul, pesan)
+}
+
+// Reject turns down a product waiting for review, or takes a published one
+// out of the catalog. The reason is shown to the seller.
+func (service *productReviewServiceImpl) Reject(id uint, admin_id uint, alasan string) (models.ProductResponse, error) {
+	alasan = strings.TrimSpace(alasan)
+	if alasan == "" {
+		return models.ProductResponse{}, errors.New("alasan is required")
+	}
+
+	product, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if product.Status != models.ProductStatusPendingReview && product.Status != models.ProductStatusPublished {
+		return models.ProductResponse{}, fmt.Errorf("only products pending review or published can be rejected, this one is %s", product.Status)
+	}
+
+	if err := service.repository.UpdateStatus(id, models.ProductStatusRejected, &alasan, &admin_id); err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	judul := fmt.Sprintf("Rejected: %s", product.NamaProduk)
+	pesan := fmt.Sprintf("%s was rejected: %s", product.NamaProduk, alasan)
+
+	return service.reviewed(product, NotificationProductRejected, judul, pesan)
+}
+
+// reviewed refreshes the suggestions and tells the store about the outcome.
+func (service *productReviewServiceImpl) reviewed(product entities.Product, tipe string, judul string, pesan string) (models.ProductResponse, error) {
+	updated, err := service.repository.FindById(product.ID)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	service.suggestService.ProductSaved(updated)
+
+	product_id := product.ID
+	if err := service.notificationService.Send(product.IDToko, &product_id, tipe, judul, pesan); err != nil {
+		log.Printf("Failed to notify store %d about review of product %d: %v", product.IDToko, product.ID, err)
+	}
+
+	return models.ToProductResponse(updated), nil
+}
@@ -3,48 +3,96 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
 	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/repositories"
-	"os"
+	"mini-project-evermos/utils/search"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
 type ProductService interface {
-	GetAll(limit int, page int, keyword string) (responder.Pagination, error)
+	GetAll(limit int, page int, keyword string, status string, store_id uint, user_id uint) (responder.Pagination, error)
 	GetById(id uint, user_id uint) (models.ProductResponse, error)
+	GetCatalog(limit int, page int, keyword string, sort string, filter models.ProductFilter, client string) (models.ProductSearchResponse, error)
+	GetCatalogBySlug(slug string) (models.CatalogProductResponse, *models.SlugRedirect, error)
 	Create(input models.ProductRequest, user_id uint) (models.ProductResponse, error)
 	Update(input models.ProductRequest, id uint, user_id uint) (models.ProductResponse, error)
 	Delete(id uint, user_id uint) (models.ProductResponse, error)
+	ChangeStatus(id uint, user_id uint, status string) (models.ProductResponse, error)
+	Reindex() error
 }
 
 type productServiceImpl struct {
 	repository               repositories.ProductRepository
 	repositoryProductPicture repositories.ProductPictureRepository
-	repositoryStore          repositories.StoreRepository
 	repositoryCategory       repositories.CategoryRepository
+	stockAlertService        StockAlertService
+	searchIndex              search.SearchIndex
+	suggestService           SuggestService
+	wishlistService          WishlistService
+	mediaService             MediaService
+	access                   storeAccess
+}
+
+// sellerTransitions lists the statuses a seller may move a product to from
+// each status. Publishing and rejecting are left to reviewers.
+var sellerTransitions = map[string][]string{
+	models.ProductStatusDraft:         {models.ProductStatusPendingReview, models.ProductStatusArchived},
+	models.ProductStatusPendingReview: {models.ProductStatusDraft, models.ProductStatusArchived},
+	models.ProductStatusRejected:      {models.ProductStatusPendingReview, models.ProductStatusDraft, models.ProductStatusArchived},
+	models.ProductStatusPublished:     {models.ProductStatusArchived},
+	models.ProductStatusArchived:      {models.ProductStatusDraft, models.ProductStatusPendingReview},
 }
 
+// searchCandidates caps how many search hits are handed to the SQL filters.
+const searchCandidates = 1000
+
 func NewProductService(
 	productRepository *repositories.ProductRepository,
 	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
 	productPictureRepository *repositories.ProductPictureRepository,
 	categoryRepository *repositories.CategoryRepository,
+	stockAlertService *StockAlertService,
+	searchIndex search.SearchIndex,
+	suggestService *SuggestService,
+	wishlistService *WishlistService,
+	mediaService *MediaService,
 ) ProductService {
 	return &productServiceImpl{
 		repository:               *productRepository,
 		repositoryProductPicture: *productPictureRepository,
-		repositoryStore:          *storeRepository,
 		repositoryCategory:       *categoryRepository,
+		stockAlertService:        *stockAlertService,
+		searchIndex:              searchIndex,
+		suggestService:           *suggestService,
+		wishlistService:          *wishlistService,
+		mediaService:             *mediaService,
+		access:                   newStoreAccess(storeRepository, storeMemberRepository),
 	}
 }
 
-func (service *productServiceImpl) GetAll(limit int, page int, keyword string) (responder.Pagination, error) {
+func (service *productServiceImpl) GetAll(limit int, page int, keyword string, status string, store_id uint, user_id uint) (responder.Pagination, error) {
+	if status != "" && !slices.Contains(models.ProductStatuses, status) {
+		return responder.Pagination{}, fmt.Errorf("invalid status %q, expected one of %s", status, strings.Join(models.ProductStatuses, ", "))
+	}
+
+	store, err := service.access.store(store_id, user_id, models.StorePermissionManageProducts)
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
 	request := responder.Pagination{}
 	request.Limit = limit
 	request.Page = page
 	request.Keyword = keyword
 
-	response, err := service.repository.FindAllPagination(request)
+	response, err := service.repository.FindAllPagination(request, models.ProductFilter{StoreID: store.ID, Status: status})
 	if err != nil {
 		return responder.Pagination{}, err
 	}
@@ -57,53 +105,116 @@ func (service *productServiceImpl) GetById(id uint, user_id uint) (models.Produc
 		return models.ProductResponse{}, err
 	}
 
-	if product.Store.IDUser != user_id {
-		return models.ProductResponse{}, errors.New("forbidden")
+	if err := service.access.check(product.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return models.ProductResponse{}, err
 	}
 
-	var response = models.ProductResponse{}
-	response.ID = product.ID
-	response.NamaProduk = product.NamaProduk
-	response.Slug = product.Slug
-	response.HargaReseller = product.HargaReseller
-	response.HargaKonsumen = product.HargaKonsumen
-	response.Stok = product.Stok
-	response.Deskripsi = product.Deskripsi
-
-	// Store data with timestamps
-	response.Store.ID = product.Store.ID
-	response.Store.NamaToko = product.Store.NamaToko
-	response.Store.UrlFoto = product.Store.UrlFoto
-	response.Store.CreatedAt = product.Store.CreatedAt
-	response.Store.UpdatedAt = product.Store.UpdatedAt
+	return service.toProductResponse(product)
+}
 
-	// Category data with timestamps
-	response.Category.ID = product.Category.ID
-	response.Category.NamaCategory = product.Category.NamaCategory
-	response.Category.CreatedAt = product.Category.CreatedAt
-	response.Category.UpdatedAt = product.Category.UpdatedAt
+// GetCatalog lists published products. client identifies the searcher for
+// the search log, see SuggestService.LogQuery.
+func (service *productServiceImpl) GetCatalog(limit int, page int, keyword string, sort string, filter models.ProductFilter, client string) (models.ProductSearchResponse, error) {
+	if sort == "" {
+		sort = models.ProductSortNewest
+		if keyword != "" {
+			sort = models.ProductSortRelevance
+		}
+	}
+	if !slices.Contains(models.ProductSorts, sort) {
+		return models.ProductSearchResponse{}, fmt.Errorf("invalid sort %q, expected one of %s", sort, strings.Join(models.ProductSorts, ", "))
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return models.ProductSearchResponse{}, errors.New("min_price must not be greater than max_price")
+	}
 
-	response.CreatedAt = product.CreatedAt
-	response.UpdatedAt = product.UpdatedAt
+	// The search index replaces the LIKE match: it hands back ranked ids
+	// which the SQL filters, facets and sorts then narrow down.
+	if keyword != "" {
+		hits, err := service.searchIndex.Search(keyword, searchCandidates)
+		if err != nil {
+			return models.ProductSearchResponse{}, err
+		}
 
-	picturesFormatter := []models.ProductPictureResponse{}
-	for _, picture := range product.ProductPicture {
-		pictureFormatter := models.ProductPictureResponse{
-			ID:        picture.ID,
-			IDProduk:  picture.IDProduk,
-			Url:       picture.Url,
-			CreatedAt: picture.CreatedAt,
-			UpdatedAt: picture.UpdatedAt,
+		filter.IDs = []uint{}
+		for _, hit := range hits {
+			filter.IDs = append(filter.IDs, hit.ID)
 		}
-		picturesFormatter = append(picturesFormatter, pictureFormatter)
 	}
-	response.Photos = picturesFormatter
 
-	return response, nil
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+	request.Sort = sort
+
+	filter.Status = models.ProductStatusPublished
+
+	pagination, err := service.repository.FindAllPagination(request, filter)
+	if err != nil {
+		return models.ProductSearchResponse{}, err
+	}
+	pagination.Keyword = keyword
+
+	rows, _ := pagination.Rows.([]models.ProductResponse)
+	products := []models.CatalogProductResponse{}
+	for _, row := range rows {
+		products = append(products, models.ToCatalogProductResponse(row))
+	}
+	pagination.Rows = products
+
+	// Count a search once, not once per page turned
+	if keyword != "" && page == 1 {
+		service.suggestService.LogQuery(keyword, pagination.TotalRows, client)
+	}
+
+	facets, err := service.repository.Facets(request, filter)
+	if err != nil {
+		return models.ProductSearchResponse{}, err
+	}
+
+	return models.ProductSearchResponse{Pagination: pagination, Facets: facets}, nil
+}
+
+// GetCatalogBySlug looks a product up by its current slug. When the slug was
+// retired by a rename, the product is returned along with where it moved to.
+func (service *productServiceImpl) GetCatalogBySlug(slug string) (models.CatalogProductResponse, *models.SlugRedirect, error) {
+	product, err := service.repository.FindBySlug(slug)
+	if err == nil && product.Status != models.ProductStatusPublished {
+		err = gorm.ErrRecordNotFound
+	}
+	if err == nil {
+		response, err := service.toProductResponse(product)
+		return models.ToCatalogProductResponse(response), nil, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.CatalogProductResponse{}, nil, err
+	}
+
+	history, err := service.repository.FindSlugHistory(slug)
+	if err != nil {
+		return models.CatalogProductResponse{}, nil, err
+	}
+
+	product, err = service.repository.FindById(history.IDProduk)
+	if err != nil {
+		return models.CatalogProductResponse{}, nil, err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return models.CatalogProductResponse{}, nil, gorm.ErrRecordNotFound
+	}
+
+	redirect := &models.SlugRedirect{
+		Slug:     product.Slug,
+		Location: "/api/v1/catalog/products/" + product.Slug,
+	}
+
+	response, err := service.toProductResponse(product)
+	return models.ToCatalogProductResponse(response), redirect, err
 }
 
+// Create adds a product to input.StoreID, or the user's own store when it is 0
 func (service *productServiceImpl) Create(input models.ProductRequest, user_id uint) (models.ProductResponse, error) {
-	store, err := service.repositoryStore.FindByUserId(user_id)
+	store, err := service.access.store(input.StoreID, user_id, models.StorePermissionManageProducts)
 	if err != nil {
 		return models.ProductResponse{}, err
 	}
@@ -116,16 +227,40 @@ func (service *productServiceImpl) Create(input models.ProductRequest, user_id u
 		return models.ProductResponse{}, errors.New("category with ID " + fmt.Sprint(input.CategoryID) + " not found")
 	}
 
+	input.Attributes, err = service.productAttributes(category, input.Attributes, nil)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	// New products go to review unless saved as a draft
+	switch input.Status {
+	case "":
+		input.Status = models.ProductStatusPendingReview
+	case models.ProductStatusDraft, models.ProductStatusPendingReview:
+	default:
+		return models.ProductResponse{}, fmt.Errorf("new products can only be %s or %s", models.ProductStatusDraft, models.ProductStatusPendingReview)
+	}
+
+	if err := checkProductPhotos(service.mediaService, input.PhotoURLs, nil); err != nil {
+		return models.ProductResponse{}, err
+	}
+
 	input.StoreID = store.ID
 
 	product, err := service.repository.Insert(input)
 	if err != nil {
 		for _, v := range input.PhotoURLs {
-			os.Remove("uploads/" + v)
+			service.mediaService.Remove(v)
 		}
 		return models.ProductResponse{}, err
 	}
 
+	if err := service.stockAlertService.Evaluate(entities.Product{}, product); err != nil {
+		log.Printf("Failed to evaluate stock alert for product %d: %v", product.ID, err)
+	}
+
+	service.indexProduct(product.ID)
+
 	// Get the complete product data to return
 	return service.GetById(product.ID, user_id)
 }
@@ -136,27 +271,58 @@ func (service *productServiceImpl) Update(input models.ProductRequest, id uint,
 		return models.ProductResponse{}, err
 	}
 
-	if product.Store.IDUser != user_id {
+	if err := service.access.check(product.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
 		for _, v := range input.PhotoURLs {
-			os.Remove("uploads/" + v)
+			service.mediaService.Remove(v)
 		}
-		return models.ProductResponse{}, errors.New("forbidden")
+		return models.ProductResponse{}, err
+	}
+
+	if err := checkProductPhotos(service.mediaService, input.PhotoURLs, product.ProductPicture); err != nil {
+		return models.ProductResponse{}, err
 	}
 
-	picture, err := service.repositoryProductPicture.FindByProductId(id)
+	// Attributes follow the category the product ends up in
+	category_id := product.IDCategory
+	if input.CategoryID != 0 {
+		category_id = input.CategoryID
+	}
+	category, err := service.repositoryCategory.FindById(category_id)
+	if err == nil {
+		input.Attributes, err = service.productAttributes(category, input.Attributes, product.Attributes)
+	}
+	if err != nil {
+		for _, v := range input.PhotoURLs {
+			service.mediaService.Remove(v)
+		}
+		return models.ProductResponse{}, err
+	}
+
+	// Status changes go through ChangeStatus; a published product whose
+	// content changed goes back to review
+	input.Status = reviewStatus(product, contentChanged(product, input))
+
 	_, err = service.repository.Update(input, id)
 
 	if err != nil {
 		for _, v := range input.PhotoURLs {
-			os.Remove("uploads/" + v)
+			service.mediaService.Remove(v)
 		}
 		return models.ProductResponse{}, err
 	}
 
-	for _, v := range picture {
-		os.Remove("uploads/" + v.Url)
+	// Replaced photos stay on disk until the trash purge removes them
+
+	updated, err := service.repository.FindById(id)
+	if err == nil {
+		if err := service.stockAlertService.Evaluate(product, updated); err != nil {
+			log.Printf("Failed to evaluate stock alert for product %d: %v", id, err)
+		}
+		service.wishlistService.ProductChanged(product, updated)
 	}
 
+	service.indexProduct(id)
+
 	// Get the updated product data to return
 	return service.GetById(id, user_id)
 }
@@ -174,5 +340,177 @@ func (service *productServiceImpl) Delete(id uint, user_id uint) (models.Product
 		return models.ProductResponse{}, err
 	}
 
+	if err := service.searchIndex.Remove(id); err != nil {
+		log.Printf("Failed to remove product %d from search index: %v", id, err)
+	}
+	service.suggestService.ProductRemoved(id)
+
 	return product, nil
 }
+
+// ChangeStatus applies a seller's status change, see sellerTransitions.
+func (service *productServiceImpl) ChangeStatus(id uint, user_id uint, status string) (models.ProductResponse, error) {
+	product, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if err := service.access.check(product.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if !slices.Contains(sellerTransitions[product.Status], status) {
+		return models.ProductResponse{}, fmt.Errorf("cannot change status from %s to %s", product.Status, status)
+	}
+
+	err = service.repository.UpdateStatus(id, status, product.AlasanTolak, &user_id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	service.indexProduct(id)
+
+	return service.GetById(id, user_id)
+}
+
+// Reindex rebuilds the search index from every product in the database.
+func (service *productServiceImpl) Reindex() error {
+	products, err := service.repository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if err := service.searchIndex.Index(toSearchDocument(product)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// productAttributes validates attribute values against the category's schema.
+// Without values the current ones carry over, minus those the category does
+// not define.
+func (service *productServiceImpl) productAttributes(category entities.Category, values map[string]string, current []entities.ProductAttribute) (map[string]string, error) {
+	schema, err := service.repositoryCategory.FindAttributes(category)
+	if err != nil {
+		return nil, err
+	}
+
+	return productAttributeValues(schema, values, current)
+}
+
+// productAttributeValues is productAttributes with the schema at hand
+func productAttributeValues(schema []entities.CategoryAttribute, values map[string]string, current []entities.ProductAttribute) (map[string]string, error) {
+	if values == nil {
+		known := map[string]bool{}
+		for _, attribute := range schema {
+			known[attribute.Kode] = true
+		}
+
+		values = map[string]string{}
+		for _, attribute := range current {
+			if known[attribute.Kode] {
+				values[attribute.Kode] = attribute.Nilai
+			}
+		}
+	}
+
+	return validateProductAttributes(schema, values)
+}
+
+// checkProductPhotos only lets photo names through that are uploads, so a
+// client can not attach arbitrary strings. Photos the product already has
+// are kept as they are, links from before the media pipeline included.
+func checkProductPhotos(mediaService MediaService, names []string, current []entities.ProductPicture) error {
+	for _, name := range names {
+		if name == "" || slices.ContainsFunc(current, func(picture entities.ProductPicture) bool { return picture.Url == name }) {
+			continue
+		}
+		if err := mediaService.Exists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toProductResponse maps a product and adds its category breadcrumbs.
+func (service *productServiceImpl) toProductResponse(product entities.Product) (models.ProductResponse, error) {
+	response := models.ToProductResponse(product)
+
+	ancestors, err := service.repositoryCategory.FindAncestors(product.Category)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+	response.Category.Breadcrumbs = models.ToCategoryBreadcrumbs(ancestors)
+
+	return response, nil
+}
+
+// indexProduct refreshes one product in the search index and the
+// suggestions. Failures are logged; the next Reindex repairs the index.
+func (service *productServiceImpl) indexProduct(id uint) {
+	product, err := service.repository.FindById(id)
+	if err == nil {
+		err = service.searchIndex.Index(toSearchDocument(product))
+		service.suggestService.ProductSaved(product)
+	}
+	if err != nil {
+		log.Printf("Failed to index product %d: %v", id, err)
+	}
+}
+
+// reviewStatus is the status an edit moves product to: a published product
+// whose approved content changed goes back to review. Empty keeps the
+// current status. Photo edits through ProductPictureService use it as well.
+func reviewStatus(product entities.Product, changed bool) string {
+	if changed && product.Status == models.ProductStatusPublished {
+		return models.ProductStatusPendingReview
+	}
+	return ""
+}
+
+// contentChanged reports whether an edit touches what reviewers approved:
+// the name, the description or the photos.
+func contentChanged(product entities.Product, input models.ProductRequest) bool {
+	if input.NamaProduk != "" && input.NamaProduk != product.NamaProduk {
+		return true
+	}
+
+	deskripsi := ""
+	if product.Deskripsi != nil {
+		deskripsi = *product.Deskripsi
+	}
+	if input.Deskripsi != "" && input.Deskripsi != deskripsi {
+		return true
+	}
+
+	if input.PhotoURLs == nil {
+		return false
+	}
+	if len(input.PhotoURLs) != len(product.ProductPicture) {
+		return true
+	}
+	for i, picture := range product.ProductPicture {
+		if picture.Url != input.PhotoURLs[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func toSearchDocument(product entities.Product) search.Document {
+	document := search.Document{
+		ID:       product.ID,
+		Name:     product.NamaProduk,
+		Category: product.Category.NamaCategory,
+	}
+	if product.Deskripsi != nil {
+		document.Description = *product.Deskripsi
+	}
+	if product.Store.NamaToko != nil {
+		document.Store = *product.Store.NamaToko
+	}
+	return document
+}
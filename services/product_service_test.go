@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/utils/storage"
+	"testing"
+)
+
+// storedMedia is a MediaService that only knows which uploads exist
+type storedMedia map[string]bool
+
+func (stored storedMedia) Upload(file *multipart.FileHeader) (models.MediaResponse, error) {
+	return models.MediaResponse{}, errors.New("not supported")
+}
+
+func (stored storedMedia) Remove(name string) {}
+
+func (stored storedMedia) Exists(name string) error {
+	if !stored[name] {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (stored storedMedia) Stat(name string) (storage.Object, error) {
+	return storage.Object{}, stored.Exists(name)
+}
+
+func (stored storedMedia) Open(name string, offset int64, length int64) (io.ReadCloser, error) {
+	return nil, stored.Exists(name)
+}
+
+func TestCheckProductPhotos(t *testing.T) {
+	uploaded := "0123456789abcdef0123456789abcdef.jpg"
+	stored := storedMedia{uploaded: true}
+	current := []entities.ProductPicture{{Url: "foto-lama.jpg"}}
+
+	tests := []struct {
+		name  string
+		names []string
+		ok    bool
+	}{
+		{"no photos", nil, true},
+		{"upload", []string{uploaded}, true},
+		{"empty name", []string{""}, true},
+		{"current photo", []string{"foto-lama.jpg", uploaded}, true},
+		{"unknown name", []string{uploaded, "https://example.com/foto.jpg"}, false},
+		{"missing upload", []string{"fedcba9876543210fedcba9876543210.jpg"}, false},
+	}
+
+	for _, test := range tests {
+		err := checkProductPhotos(stored, test.names, current)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/search"
+	"time"
+)
+
+// Contract
+type ProductTrashService interface {
+	GetAll(limit int, page int, keyword string, store_id uint, user_id uint) (responder.Pagination, error)
+	Restore(id uint, user_id uint) (models.ProductResponse, error)
+	Purge() error
+}
+
+type productTrashServiceImpl struct {
+	repository     repositories.ProductRepository
+	searchIndex    search.SearchIndex
+	suggestService SuggestService
+	mediaService   MediaService
+	retention      time.Duration
+	access         storeAccess
+}
+
+// NewProductTrashService keeps deleted products, and photos replaced by an
+// edit, for retention before Purge removes them for good.
+func NewProductTrashService(
+	productRepository *repositories.ProductRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+	searchIndex search.SearchIndex,
+	suggestService *SuggestService,
+	mediaService *MediaService,
+	retention time.Duration,
+) ProductTrashService {
+	return &productTrashServiceImpl{
+		repository:     *productRepository,
+		searchIndex:    searchIndex,
+		suggestService: *suggestService,
+		mediaService:   *mediaService,
+		retention:      retention,
+		access:         newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+func (service *productTrashServiceImpl) GetAll(limit int, page int, keyword string, store_id uint, user_id uint) (responder.Pagination, error) {
+	store, err := service.access.store(store_id, user_id, models.StorePermissionManageProducts)
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+	request.Keyword = keyword
+
+	response, err := service.repository.FindTrashPagination(request, store.ID)
+	if err != nil {
+		return responder.Pagination{}, err
+	}
+
+	rows, _ := response.Rows.([]models.TrashedProductResponse)
+	for i := range rows {
+		if !rows[i].HasHistory {
+			purge_at := rows[i].DeletedAt.Add(service.retention)
+			rows[i].PurgeAt = &purge_at
+		}
+	}
+
+	return response, nil
+}
+
+func (service *productTrashServiceImpl) Restore(id uint, user_id uint) (models.ProductResponse, error) {
+	product, err := service.repository.FindTrashedById(id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if err := service.access.check(product.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if err := service.repository.Restore(id); err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	restored, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	if err := service.searchIndex.Index(toSearchDocument(restored)); err != nil {
+		log.Printf("Failed to index product %d: %v", id, err)
+	}
+	service.suggestService.ProductSaved(restored)
+
+	return models.ToProductResponse(restored), nil
+}
+
+// Purge hard-deletes products and replaced photos that have been in the
+// trash longer than the retention, along with their files. Products with a
+// price history are left in the trash: log_produk is append-only.
+func (service *productTrashServiceImpl) Purge() error {
+	deleted_before := time.Now().Add(-service.retention)
+
+	products, err := service.repository.FindPurgeable(deleted_before)
+	if err != nil {
+		return err
+	}
+
+	urls := []string{}
+	for _, product := range products {
+		purged, err := service.repository.Purge(product.ID)
+		if err != nil {
+			log.Printf("Failed to purge product %d: %v", product.ID, err)
+			continue
+		}
+		urls = append(urls, purged...)
+	}
+
+	pictures, err := service.repository.PurgePictures(deleted_before)
+	if err != nil {
+		return err
+	}
+	urls = append(urls, pictures...)
+
+	for _, url := range urls {
+		service.mediaService.Remove(url)
+	}
+
+	if len(products) > 0 || len(pictures) > 0 {
+		log.Printf("Purged %d products and %d replaced photos from the trash", len(products), len(pictures))
+	}
+
+	return nil
+}
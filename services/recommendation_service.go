@@ -0,0 +1,165 @@
+package services
+
+import (
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/repositories"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type RecommendationService interface {
+	TrackView(user_id uint, product_id uint)
+	GetRecentlyViewed(user_id uint, limit int) ([]models.RecentlyViewedResponse, error)
+	GetRelated(product_id uint, limit int) ([]models.RelatedProductResponse, error)
+	RebuildCoPurchases() error
+}
+
+type recommendationServiceImpl struct {
+	repository        repositories.RecommendationRepository
+	repositoryProduct repositories.ProductRepository
+}
+
+func NewRecommendationService(recommendationRepository *repositories.RecommendationRepository, productRepository *repositories.ProductRepository) RecommendationService {
+	return &recommendationServiceImpl{
+		repository:        *recommendationRepository,
+		repositoryProduct: *productRepository,
+	}
+}
+
+// TrackView records that a user opened a product page. Failures are logged;
+// they must not break the page.
+func (service *recommendationServiceImpl) TrackView(user_id uint, product_id uint) {
+	if err := service.repository.RecordView(user_id, product_id, time.Now()); err != nil {
+		log.Printf("Failed to record view of product %d by user %d: %v", product_id, user_id, err)
+	}
+}
+
+func (service *recommendationServiceImpl) GetRecentlyViewed(user_id uint, limit int) ([]models.RecentlyViewedResponse, error) {
+	views, err := service.repository.FindRecentlyViewed(user_id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, view := range views {
+		ids = append(ids, view.IDProduk)
+	}
+
+	products, err := service.findProducts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.RecentlyViewedResponse{}
+	for _, view := range views {
+		if product, ok := products[view.IDProduk]; ok {
+			responses = append(responses, models.RecentlyViewedResponse{
+				CatalogProductResponse: product,
+				DilihatPada:            view.DilihatPada,
+			})
+		}
+	}
+
+	return responses, nil
+}
+
+// GetRelated returns the products most often bought together with a
+// published product. When there is not enough purchase history, the best
+// sellers of its category fill the list.
+func (service *recommendationServiceImpl) GetRelated(product_id uint, limit int) ([]models.RelatedProductResponse, error) {
+	product, err := service.repositoryProduct.FindById(product_id)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	relations, err := service.repository.FindRelated(product_id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, relation := range relations {
+		ids = append(ids, relation.IDTerkait)
+	}
+
+	products, err := service.findProducts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.RelatedProductResponse{}
+	for _, relation := range relations {
+		if related, ok := products[relation.IDTerkait]; ok {
+			responses = append(responses, models.RelatedProductResponse{
+				CatalogProductResponse: related,
+				Skor:                   relation.Skor,
+			})
+		}
+	}
+
+	if len(responses) < limit {
+		request := responder.Pagination{Limit: limit + len(ids) + 1, Page: 1, Sort: models.ProductSortBestSelling}
+		filter := models.ProductFilter{CategoryID: product.IDCategory, Status: models.ProductStatusPublished}
+
+		pagination, err := service.repositoryProduct.FindAllPagination(request, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, _ := pagination.Rows.([]models.ProductResponse)
+		for _, row := range rows {
+			if len(responses) == limit {
+				break
+			}
+			if row.ID == product_id || slices.Contains(ids, row.ID) {
+				continue
+			}
+			responses = append(responses, models.RelatedProductResponse{CatalogProductResponse: models.ToCatalogProductResponse(row)})
+		}
+	}
+
+	return responses, nil
+}
+
+// RebuildCoPurchases recomputes the co-purchase relations from every
+// transaction.
+func (service *recommendationServiceImpl) RebuildCoPurchases() error {
+	relations, err := service.repository.FindCoPurchases()
+	if err != nil {
+		return err
+	}
+
+	return service.repository.ReplaceRelations(relations)
+}
+
+// findProducts loads published products by id. Products unpublished in the
+// meantime are missing from the result.
+func (service *recommendationServiceImpl) findProducts(ids []uint) (map[uint]models.CatalogProductResponse, error) {
+	products := map[uint]models.CatalogProductResponse{}
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	request := responder.Pagination{Limit: len(ids), Page: 1, Sort: models.ProductSortRelevance}
+	filter := models.ProductFilter{IDs: ids, Status: models.ProductStatusPublished}
+
+	pagination, err := service.repositoryProduct.FindAllPagination(request, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := pagination.Rows.([]models.ProductResponse)
+	for _, row := range rows {
+		products[row.ID] = models.ToCatalogProductResponse(row)
+	}
+
+	return products, nil
+}
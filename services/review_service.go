@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/repositories"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const NotificationNewReview = "new_review"
+
+// Contract
+//
+// ReviewService handles buyers' ratings of what they bought, not to be
+// confused with ProductReviewService, the admin moderation of listings.
+type ReviewService interface {
+	Create(input models.ReviewRequest, user_id uint) (models.ReviewResponse, error)
+	GetByProductSlug(slug string, rating int, limit int, page int) (models.ReviewListResponse, error)
+	Reply(id uint, user_id uint, input models.ReviewReplyRequest) (models.ReviewResponse, error)
+	Flag(id uint, user_id uint, input models.ReviewFlagRequest) error
+	GetFlagged(limit int, page int) (responder.Pagination, error)
+	GetFlags(id uint) ([]models.ReviewFlagResponse, error)
+	Hide(id uint) (models.ReviewResponse, error)
+	Show(id uint) (models.ReviewResponse, error)
+}
+
+type reviewServiceImpl struct {
+	repository          repositories.ReviewRepository
+	repositoryProduct   repositories.ProductRepository
+	notificationService NotificationService
+	mediaService        MediaService
+	access              storeAccess
+}
+
+func NewReviewService(
+	reviewRepository *repositories.ReviewRepository,
+	productRepository *repositories.ProductRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+	notificationService *NotificationService,
+	mediaService *MediaService,
+) ReviewService {
+	return &reviewServiceImpl{
+		repository:          *reviewRepository,
+		repositoryProduct:   *productRepository,
+		notificationService: *notificationService,
+		mediaService:        *mediaService,
+		access:              newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+// Create reviews a line item of one of the user's own transactions. Each
+// line item can be reviewed once.
+func (service *reviewServiceImpl) Create(input models.ReviewRequest, user_id uint) (models.ReviewResponse, error) {
+	response, err := service.create(input, user_id)
+	if err != nil {
+		for _, v := range input.PhotoURLs {
+			service.mediaService.Remove(v)
+		}
+	}
+	return response, err
+}
+
+func (service *reviewServiceImpl) create(input models.ReviewRequest, user_id uint) (models.ReviewResponse, error) {
+	if input.Rating < 1 || input.Rating > 5 {
+		return models.ReviewResponse{}, errors.New("rating must be between 1 and 5")
+	}
+	if len(input.PhotoURLs) > models.MaxReviewPhotos {
+		return models.ReviewResponse{}, fmt.Errorf("a review can have at most %d photos", models.MaxReviewPhotos)
+	}
+
+	detail, err := service.repository.FindDetail(input.DetailTrxID)
+	if err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	if detail.Trx.IDUser != user_id {
+		return models.ReviewResponse{}, errors.New("forbidden: only the buyer can review this item")
+	}
+
+	// Checkout alone proves nothing, the item has to have been delivered
+	if detail.Status != models.OrderStatusCompleted {
+		return models.ReviewResponse{}, errors.New("only items of completed orders can be reviewed")
+	}
+
+	_, err = service.repository.FindByDetail(detail.ID)
+	if err == nil {
+		return models.ReviewResponse{}, errors.New("this item has already been reviewed")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ReviewResponse{}, err
+	}
+
+	review := entities.Review{
+		IDDetailTrx: detail.ID,
+		IDProduk:    detail.ProductLog.IDProduk,
+		IDToko:      detail.IDToko,
+		IDUser:      user_id,
+		Rating:      input.Rating,
+		Status:      models.ReviewStatusVisible,
+	}
+	if ulasan := strings.TrimSpace(input.Ulasan); ulasan != "" {
+		review.Ulasan = &ulasan
+	}
+
+	review, err = service.repository.Insert(review, input.PhotoURLs)
+	if err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	judul := fmt.Sprintf("New %d-star review: %s", review.Rating, detail.ProductLog.NamaProduk)
+	pesan := "A buyer reviewed " + detail.ProductLog.NamaProduk + "."
+	if review.Ulasan != nil {
+		pesan = *review.Ulasan
+	}
+	if err := service.notificationService.Send(review.IDToko, &review.IDProduk, NotificationNewReview, judul, pesan); err != nil {
+		log.Printf("Failed to notify store %d of review %d: %v", review.IDToko, review.ID, err)
+	}
+
+	return service.getById(review.ID)
+}
+
+func (service *reviewServiceImpl) GetByProductSlug(slug string, rating int, limit int, page int) (models.ReviewListResponse, error) {
+	product, err := service.repositoryProduct.FindBySlug(slug)
+	if err == nil && product.Status != models.ProductStatusPublished {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return models.ReviewListResponse{}, err
+	}
+
+	return service.list(product, rating, limit, page)
+}
+
+func (service *reviewServiceImpl) list(product entities.Product, rating int, limit int, page int) (models.ReviewListResponse, error) {
+	if rating < 0 || rating > 5 {
+		return models.ReviewListResponse{}, errors.New("rating must be between 1 and 5")
+	}
+
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+
+	pagination, err := service.repository.FindByProductPagination(request, product.ID, rating)
+	if err != nil {
+		return models.ReviewListResponse{}, err
+	}
+
+	bintang, err := service.repository.CountByRating(product.ID)
+	if err != nil {
+		return models.ReviewListResponse{}, err
+	}
+
+	return models.ReviewListResponse{
+		Pagination:   pagination,
+		Rating:       models.AverageRating(product.TotalRating, product.JumlahUlasan),
+		JumlahUlasan: product.JumlahUlasan,
+		Bintang:      bintang,
+	}, nil
+}
+
+// Reply lets the seller answer a review of one of their products. A later
+// reply replaces the earlier one.
+func (service *reviewServiceImpl) Reply(id uint, user_id uint, input models.ReviewReplyRequest) (models.ReviewResponse, error) {
+	balasan := strings.TrimSpace(input.Balasan)
+	if balasan == "" {
+		return models.ReviewResponse{}, errors.New("balasan is required")
+	}
+
+	review, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	if err := service.access.check(review.IDToko, user_id, models.StorePermissionManageProducts); err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	if err := service.repository.Reply(id, balasan); err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	return service.getById(id)
+}
+
+// Flag reports a review to the admins. Each user can report a review once.
+func (service *reviewServiceImpl) Flag(id uint, user_id uint, input models.ReviewFlagRequest) error {
+	alasan := strings.TrimSpace(input.Alasan)
+	if alasan == "" {
+		return errors.New("alasan is required")
+	}
+
+	review, err := service.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	if review.IDUser == user_id {
+		return errors.New("you cannot report your own review")
+	}
+
+	flags, err := service.repository.FindFlags(id)
+	if err != nil {
+		return err
+	}
+	for _, flag := range flags {
+		if flag.IDUser == user_id {
+			return errors.New("you have already reported this review")
+		}
+	}
+
+	return service.repository.Flag(entities.ReviewFlag{
+		IDUlasan: id,
+		IDUser:   user_id,
+		Alasan:   alasan,
+	})
+}
+
+func (service *reviewServiceImpl) GetFlagged(limit int, page int) (responder.Pagination, error) {
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+
+	return service.repository.FindFlaggedPagination(request)
+}
+
+func (service *reviewServiceImpl) GetFlags(id uint) ([]models.ReviewFlagResponse, error) {
+	if _, err := service.repository.FindById(id); err != nil {
+		return nil, err
+	}
+
+	flags, err := service.repository.FindFlags(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.ReviewFlagResponse{}
+	for _, flag := range flags {
+		responses = append(responses, models.ReviewFlagResponse{
+			UserID:    flag.IDUser,
+			Alasan:    flag.Alasan,
+			CreatedAt: flag.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// Hide takes a review out of listings and ratings.
+func (service *reviewServiceImpl) Hide(id uint) (models.ReviewResponse, error) {
+	if err := service.repository.UpdateStatus(id, models.ReviewStatusHidden); err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	return service.getById(id)
+}
+
+// Show puts a review back and dismisses the reports against it.
+func (service *reviewServiceImpl) Show(id uint) (models.ReviewResponse, error) {
+	if err := service.repository.UpdateStatus(id, models.ReviewStatusVisible); err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	return service.getById(id)
+}
+
+func (service *reviewServiceImpl) getById(id uint) (models.ReviewResponse, error) {
+	review, err := service.repository.FindById(id)
+	if err != nil {
+		return models.ReviewResponse{}, err
+	}
+
+	return models.ToReviewResponse(review), nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"strings"
+)
+
+const (
+	NotificationLowStock       = "low_stock"
+	NotificationLowStockDigest = "low_stock_digest"
+)
+
+// Contract
+type StockAlertService interface {
+	Evaluate(before entities.Product, after entities.Product) error
+	SendDailyDigest() error
+}
+
+type stockAlertServiceImpl struct {
+	repositoryProduct   repositories.ProductRepository
+	notificationService NotificationService
+}
+
+func NewStockAlertService(productRepository *repositories.ProductRepository, notificationService *NotificationService) StockAlertService {
+	return &stockAlertServiceImpl{
+		repositoryProduct:   *productRepository,
+		notificationService: *notificationService,
+	}
+}
+
+func isLowStock(product entities.Product) bool {
+	return product.StokMinimum > 0 && product.Stok <= product.StokMinimum
+}
+
+// Evaluate notifies the owning store when a stock change moves a product
+// under its threshold. Products that were already low are not re-notified;
+// the daily digest keeps reminding about them.
+func (service *stockAlertServiceImpl) Evaluate(before entities.Product, after entities.Product) error {
+	if !isLowStock(after) || isLowStock(before) {
+		return nil
+	}
+
+	product_id := after.ID
+	judul := fmt.Sprintf("Low stock: %s", after.NamaProduk)
+	pesan := fmt.Sprintf("Stock of %s is %d, at or below the minimum of %d.", after.NamaProduk, after.Stok, after.StokMinimum)
+
+	return service.notificationService.Send(after.IDToko, &product_id, NotificationLowStock, judul, pesan)
+}
+
+func (service *stockAlertServiceImpl) SendDailyDigest() error {
+	products, err := service.repositoryProduct.FindLowStock()
+	if err != nil {
+		return err
+	}
+
+	byStore := map[uint][]entities.Product{}
+	var storeIDs []uint
+	for _, product := range products {
+		if _, ok := byStore[product.IDToko]; !ok {
+			storeIDs = append(storeIDs, product.IDToko)
+		}
+		byStore[product.IDToko] = append(byStore[product.IDToko], product)
+	}
+
+	var errs []error
+	for _, store_id := range storeIDs {
+		lines := []string{}
+		for _, product := range byStore[store_id] {
+			lines = append(lines, fmt.Sprintf("- %s: %d left (minimum %d)", product.NamaProduk, product.Stok, product.StokMinimum))
+		}
+
+		judul := fmt.Sprintf("%d products under stock threshold", len(byStore[store_id]))
+		pesan := strings.Join(lines, "\n")
+
+		// One failing store must not hold back the digests of the others
+		if err := service.notificationService.Send(store_id, nil, NotificationLowStockDigest, judul, pesan); err != nil {
+			log.Printf("Failed to send low stock digest to toko %d: %v", store_id, err)
+			errs = append(errs, fmt.Errorf("toko %d: %w", store_id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
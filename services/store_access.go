@@ -0,0 +1,66 @@
+package services
+
+import (
+	"errors"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+
+	"gorm.io/gorm"
+)
+
+// storeAccess decides what a user may do in a store through their
+// membership of it. Services that act for a store keep one.
+type storeAccess struct {
+	repositoryStore  repositories.StoreRepository
+	repositoryMember repositories.StoreMemberRepository
+}
+
+func newStoreAccess(storeRepository *repositories.StoreRepository, storeMemberRepository *repositories.StoreMemberRepository) storeAccess {
+	return storeAccess{
+		repositoryStore:  *storeRepository,
+		repositoryMember: *storeMemberRepository,
+	}
+}
+
+// store returns the store the user acts for after checking they have
+// permission in it. A store_id of 0 picks the user's own store.
+func (access storeAccess) store(store_id uint, user_id uint, permission string) (entities.Store, error) {
+	if store_id == 0 {
+		store, err := access.repositoryStore.FindByUserId(user_id)
+		if err != nil {
+			return entities.Store{}, err
+		}
+		store_id = store.ID
+	}
+
+	if _, err := access.member(store_id, user_id, permission); err != nil {
+		return entities.Store{}, err
+	}
+
+	return access.repositoryStore.FindById(store_id)
+}
+
+// check makes sure the user has permission in the store
+func (access storeAccess) check(store_id uint, user_id uint, permission string) error {
+	_, err := access.member(store_id, user_id, permission)
+	return err
+}
+
+// member returns the user's membership of the store when it grants
+// permission; an empty permission only asks for membership
+func (access storeAccess) member(store_id uint, user_id uint, permission string) (entities.StoreMember, error) {
+	member, err := access.repositoryMember.FindByStoreAndUser(store_id, user_id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.StoreMember{}, errors.New("forbidden")
+	}
+	if err != nil {
+		return entities.StoreMember{}, err
+	}
+
+	if permission != "" && !models.StoreMemberCan(member, permission) {
+		return entities.StoreMember{}, errors.New("forbidden")
+	}
+
+	return member, nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/repositories"
+	"time"
+)
+
+// Contract
+type StoreAnalyticsService interface {
+	RecordView(product_id uint)
+	GetAnalytics(store_id uint, user_id uint, input models.StoreAnalyticsRequest) (models.StoreAnalyticsResponse, error)
+	Rollup() error
+}
+
+type storeAnalyticsServiceImpl struct {
+	repository repositories.StoreAnalyticsRepository
+	access     storeAccess
+}
+
+// NewStoreAnalyticsService reports a store's figures from the daily rollups,
+// which Rollup brings up to date in the background.
+func NewStoreAnalyticsService(
+	storeAnalyticsRepository *repositories.StoreAnalyticsRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+) StoreAnalyticsService {
+	return &storeAnalyticsServiceImpl{
+		repository: *storeAnalyticsRepository,
+		access:     newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+// RecordView counts a product page view. Failures are logged; analytics must
+// never break the page.
+func (service *storeAnalyticsServiceImpl) RecordView(product_id uint) {
+	if err := service.repository.RecordView(product_id, time.Now()); err != nil {
+		log.Printf("Failed to count view of product %d: %v", product_id, err)
+	}
+}
+
+// GetAnalytics reports the store's figures over input's range, store_id 0
+// being the user's own store.
+func (service *storeAnalyticsServiceImpl) GetAnalytics(store_id uint, user_id uint, input models.StoreAnalyticsRequest) (models.StoreAnalyticsResponse, error) {
+	from, to, err := analyticsRange(input.From, input.To)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+
+	bucket, err := analyticsBucketOf(input.Bucket)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+
+	top := input.Top
+	if top <= 0 {
+		top = models.DefaultAnalyticsTopProducts
+	}
+	if top > models.MaxAnalyticsTopProducts {
+		top = models.MaxAnalyticsTopProducts
+	}
+
+	store, err := service.access.store(store_id, user_id, models.StorePermissionViewFinance)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+
+	from_date, to_date := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	stats, err := service.repository.FindStoreStats(store.ID, from_date, to_date)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+
+	buckets, index := analyticsBuckets(from, to, bucket)
+	series := make([]models.StoreAnalyticsPoint, len(buckets))
+	for i, date := range buckets {
+		series[i].Date = date
+	}
+
+	summary := models.StoreAnalyticsSummary{}
+	for _, stat := range stats {
+		i, ok := index[stat.Tanggal.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		series[i].Revenue += stat.Pendapatan
+		series[i].Orders += stat.Pesanan
+		series[i].UnitsSold += stat.Unit
+		series[i].Views += stat.Dilihat
+
+		summary.Revenue += stat.Pendapatan
+		summary.Orders += stat.Pesanan
+		summary.UnitsSold += stat.Unit
+		summary.Views += stat.Dilihat
+	}
+	for i := range series {
+		series[i].AOV = ratio(float64(series[i].Revenue), series[i].Orders)
+	}
+	summary.AOV = ratio(float64(summary.Revenue), summary.Orders)
+	summary.ConversionRate = ratio(float64(summary.Orders), summary.Views)
+
+	summary.Buyers, summary.RepeatBuyers, err = service.repository.CountBuyers(store.ID, from_date, to_date)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+	summary.RepeatBuyerRate = ratio(float64(summary.RepeatBuyers), summary.Buyers)
+
+	products, err := service.repository.FindTopProducts(store.ID, from_date, to_date, top)
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+	for i := range products {
+		products[i].ConversionRate = ratio(float64(products[i].Orders), products[i].Views)
+	}
+	if products == nil {
+		products = []models.StoreAnalyticsProduct{}
+	}
+
+	updated_at, err := service.repository.LastRollup()
+	if err != nil {
+		return models.StoreAnalyticsResponse{}, err
+	}
+
+	return models.StoreAnalyticsResponse{
+		StoreID:     store.ID,
+		From:        from_date,
+		To:          to_date,
+		Bucket:      bucket,
+		Summary:     summary,
+		Series:      series,
+		TopProducts: products,
+		UpdatedAt:   updated_at,
+	}, nil
+}
+
+// Rollup brings the daily rollups up to date, see
+// StoreAnalyticsRepository.Rollup.
+func (service *storeAnalyticsServiceImpl) Rollup() error {
+	days, err := service.repository.Rollup(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if days > 0 {
+		log.Printf("Store analytics: rebuilt the rollups of %d days", days)
+	}
+	return nil
+}
+
+// analyticsRange parses a YYYY-MM-DD range, both ends inclusive. The range
+// defaults to the last models.DefaultAnalyticsDays days up to today.
+func analyticsRange(from_value string, to_value string) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if to_value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, to_value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to, expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, 1-models.DefaultAnalyticsDays)
+	if from_value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, from_value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= time.Duration(models.MaxAnalyticsDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("the range can span at most %d days", models.MaxAnalyticsDays)
+	}
+
+	return from, to, nil
+}
+
+// analyticsBucketOf validates a bucket, day when empty
+func analyticsBucketOf(bucket string) (string, error) {
+	if bucket == "" {
+		return models.AnalyticsBucketDay, nil
+	}
+	if bucket != models.AnalyticsBucketDay && bucket != models.AnalyticsBucketWeek {
+		return "", fmt.Errorf("invalid bucket %q, expected %s or %s", bucket, models.AnalyticsBucketDay, models.AnalyticsBucketWeek)
+	}
+	return bucket, nil
+}
+
+// analyticsBuckets lists the start of every bucket in the range, empty ones
+// too so charts need no gap filling, and maps each day (YYYY-MM-DD) to the
+// position of its bucket.
+func analyticsBuckets(from time.Time, to time.Time, bucket string) ([]string, map[string]int) {
+	buckets := []string{}
+	index := map[string]int{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := analyticsBucket(day, from, bucket).Format(time.DateOnly)
+		if _, ok := index[key]; !ok {
+			index[key] = len(buckets)
+			buckets = append(buckets, key)
+		}
+		index[day.Format(time.DateOnly)] = index[key]
+	}
+	return buckets, index
+}
+
+// analyticsBucket returns the first day of the bucket day falls in. Weeks
+// start on Monday; the first week starts at from.
+func analyticsBucket(day time.Time, from time.Time, bucket string) time.Time {
+	if bucket != models.AnalyticsBucketWeek {
+		return day
+	}
+
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	start := day.AddDate(0, 0, -offset)
+	if start.Before(from) {
+		return from
+	}
+	return start
+}
+
+// ratio divides, returning 0 when there is nothing to divide by
+func ratio(value float64, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return value / float64(total)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"mini-project-evermos/models"
+	"mini-project-evermos/repositories"
+)
+
+// Contract
+type StoreFollowerService interface {
+	GetFollowing(user_id uint) ([]models.FollowedStoreResponse, error)
+	Follow(store_id uint, user_id uint) (models.StoreResponse, error)
+	Unfollow(store_id uint, user_id uint) (models.StoreResponse, error)
+}
+
+type storeFollowerServiceImpl struct {
+	repository      repositories.StoreFollowerRepository
+	repositoryStore repositories.StoreRepository
+}
+
+func NewStoreFollowerService(storeFollowerRepository *repositories.StoreFollowerRepository, storeRepository *repositories.StoreRepository) StoreFollowerService {
+	return &storeFollowerServiceImpl{
+		repository:      *storeFollowerRepository,
+		repositoryStore: *storeRepository,
+	}
+}
+
+func (service *storeFollowerServiceImpl) GetFollowing(user_id uint) ([]models.FollowedStoreResponse, error) {
+	follows, err := service.repository.FindByUserId(user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.FollowedStoreResponse{}
+	for _, follow := range follows {
+		responses = append(responses, models.FollowedStoreResponse{
+			StoreResponse: models.ToStoreResponse(follow.Store),
+			DiikutiSejak:  follow.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (service *storeFollowerServiceImpl) Follow(store_id uint, user_id uint) (models.StoreResponse, error) {
+	store, err := service.repositoryStore.FindById(store_id)
+	if err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	if store.IDUser == user_id {
+		return models.StoreResponse{}, errors.New("you cannot follow your own store")
+	}
+
+	if err := service.repository.Follow(store_id, user_id); err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	return service.store(store_id)
+}
+
+func (service *storeFollowerServiceImpl) Unfollow(store_id uint, user_id uint) (models.StoreResponse, error) {
+	if err := service.repository.Unfollow(store_id, user_id); err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	return service.store(store_id)
+}
+
+func (service *storeFollowerServiceImpl) store(store_id uint) (models.StoreResponse, error) {
+	store, err := service.repositoryStore.FindById(store_id)
+	if err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	return models.ToStoreResponse(store), nil
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/mailer"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contract
+type StoreMemberService interface {
+	GetMembers(store_id uint, user_id uint) ([]models.StoreMemberResponse, error)
+	UpdateMember(store_id uint, id uint, user_id uint, input models.StoreMemberRequest) (models.StoreMemberResponse, error)
+	RemoveMember(store_id uint, id uint, user_id uint) (models.StoreMemberResponse, error)
+	GetInvitations(store_id uint, user_id uint) ([]models.StoreInvitationResponse, error)
+	Invite(store_id uint, user_id uint, input models.StoreInvitationRequest) (models.StoreInvitationResponse, error)
+	CancelInvitation(store_id uint, id uint, user_id uint) (models.StoreInvitationResponse, error)
+	AcceptInvitation(user_id uint, input models.StoreInvitationAcceptRequest) (models.StoreMembershipResponse, error)
+	GetMemberships(user_id uint) ([]models.StoreMembershipResponse, error)
+}
+
+type storeMemberServiceImpl struct {
+	repository      repositories.StoreMemberRepository
+	repositoryStore repositories.StoreRepository
+	repositoryUser  repositories.UserRepository
+	mailer          mailer.Mailer
+	inviteURL       string
+	access          storeAccess
+}
+
+// NewStoreMemberService sends invitations through mailer. invite_url is the
+// page that accepts them; the token is added as its token parameter.
+func NewStoreMemberService(
+	storeMemberRepository *repositories.StoreMemberRepository,
+	storeRepository *repositories.StoreRepository,
+	userRepository *repositories.UserRepository,
+	mailer mailer.Mailer,
+	invite_url string,
+) StoreMemberService {
+	return &storeMemberServiceImpl{
+		repository:      *storeMemberRepository,
+		repositoryStore: *storeRepository,
+		repositoryUser:  *userRepository,
+		mailer:          mailer,
+		inviteURL:       invite_url,
+		access:          newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+func (service *storeMemberServiceImpl) GetMembers(store_id uint, user_id uint) ([]models.StoreMemberResponse, error) {
+	if err := service.access.check(store_id, user_id, models.StorePermissionManageStaff); err != nil {
+		return nil, err
+	}
+
+	members, err := service.repository.FindByStoreId(store_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.StoreMemberResponse{}
+	for _, member := range members {
+		responses = append(responses, models.ToStoreMemberResponse(member))
+	}
+	return responses, nil
+}
+
+// UpdateMember changes the role and permissions of a member. The owner stays
+// the owner.
+func (service *storeMemberServiceImpl) UpdateMember(store_id uint, id uint, user_id uint, input models.StoreMemberRequest) (models.StoreMemberResponse, error) {
+	actor, err := service.access.member(store_id, user_id, models.StorePermissionManageStaff)
+	if err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+
+	member, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+	if member.IDToko != store_id || !canManageRole(actor, member.Peran) {
+		return models.StoreMemberResponse{}, errors.New("forbidden")
+	}
+
+	peran, izin, err := validStoreRole(actor, input.Peran, input.Izin)
+	if err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+
+	if err := service.repository.Update(id, peran, izin); err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+
+	updated, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+
+	return models.ToStoreMemberResponse(updated), nil
+}
+
+// RemoveMember takes a member out of the store. Members other than the owner
+// can also leave on their own.
+func (service *storeMemberServiceImpl) RemoveMember(store_id uint, id uint, user_id uint) (models.StoreMemberResponse, error) {
+	member, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+	if member.IDToko != store_id || member.Peran == models.StoreRoleOwner {
+		return models.StoreMemberResponse{}, errors.New("forbidden")
+	}
+
+	if member.IDUser != user_id {
+		actor, err := service.access.member(store_id, user_id, models.StorePermissionManageStaff)
+		if err != nil {
+			return models.StoreMemberResponse{}, err
+		}
+		if !canManageRole(actor, member.Peran) {
+			return models.StoreMemberResponse{}, errors.New("forbidden")
+		}
+	}
+
+	if err := service.repository.Delete(id); err != nil {
+		return models.StoreMemberResponse{}, err
+	}
+
+	return models.ToStoreMemberResponse(member), nil
+}
+
+func (service *storeMemberServiceImpl) GetInvitations(store_id uint, user_id uint) ([]models.StoreInvitationResponse, error) {
+	if err := service.access.check(store_id, user_id, models.StorePermissionManageStaff); err != nil {
+		return nil, err
+	}
+
+	invitations, err := service.repository.FindPendingInvitations(store_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.StoreInvitationResponse{}
+	for _, invitation := range invitations {
+		responses = append(responses, models.ToStoreInvitationResponse(invitation))
+	}
+	return responses, nil
+}
+
+// Invite emails a link to join the store. Only a hash of its token is
+// stored, so a lost email means a new invitation.
+func (service *storeMemberServiceImpl) Invite(store_id uint, user_id uint, input models.StoreInvitationRequest) (models.StoreInvitationResponse, error) {
+	actor, err := service.access.member(store_id, user_id, models.StorePermissionManageStaff)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	store, err := service.repositoryStore.FindById(store_id)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return models.StoreInvitationResponse{}, errors.New("invalid email")
+	}
+
+	peran, izin, err := validStoreRole(actor, input.Peran, input.Izin)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	if user, err := service.repositoryUser.FindByEmail(email); err == nil {
+		if _, err := service.repository.FindByStoreAndUser(store_id, user.ID); err == nil {
+			return models.StoreInvitationResponse{}, errors.New("user is already a member of this store")
+		}
+	}
+
+	pending, err := service.repository.FindPendingInvitations(store_id)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+	for _, invitation := range pending {
+		if invitation.Email == email && invitation.KedaluwarsaPada.After(time.Now()) {
+			return models.StoreInvitationResponse{}, errors.New("email already has a pending invitation")
+		}
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	invitation, err := service.repository.InsertInvitation(entities.StoreInvitation{
+		IDToko:          store_id,
+		Email:           email,
+		Peran:           peran,
+		Izin:            izin,
+		Token:           hashInvitationToken(token),
+		DiundangOleh:    user_id,
+		KedaluwarsaPada: time.Now().Add(models.StoreInvitationValidity),
+	})
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	nama_toko := ""
+	if store.NamaToko != nil {
+		nama_toko = *store.NamaToko
+	}
+	subject := fmt.Sprintf("You are invited to join %s", nama_toko)
+	body := fmt.Sprintf("You are invited to join %s as %s.\n\nAccept the invitation before %s:\n%s\n",
+		nama_toko, peran, invitation.KedaluwarsaPada.Format("2006-01-02 15:04"), service.invitationLink(token))
+
+	// The token only exists in the email, an invitation that was not sent is useless
+	if err := service.mailer.Send(email, subject, body); err != nil {
+		if err := service.repository.DeleteInvitation(invitation.ID); err != nil {
+			log.Printf("Failed to delete unsent store invitation %d: %v", invitation.ID, err)
+		}
+		return models.StoreInvitationResponse{}, fmt.Errorf("failed to send invitation: %w", err)
+	}
+
+	return models.ToStoreInvitationResponse(invitation), nil
+}
+
+func (service *storeMemberServiceImpl) CancelInvitation(store_id uint, id uint, user_id uint) (models.StoreInvitationResponse, error) {
+	actor, err := service.access.member(store_id, user_id, models.StorePermissionManageStaff)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	invitation, err := service.repository.FindInvitationById(id)
+	if err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+	if invitation.IDToko != store_id || !canManageRole(actor, invitation.Peran) {
+		return models.StoreInvitationResponse{}, errors.New("forbidden")
+	}
+	if invitation.DiterimaPada != nil {
+		return models.StoreInvitationResponse{}, errors.New("invitation was already accepted")
+	}
+
+	if err := service.repository.DeleteInvitation(id); err != nil {
+		return models.StoreInvitationResponse{}, err
+	}
+
+	return models.ToStoreInvitationResponse(invitation), nil
+}
+
+// AcceptInvitation joins the store of the invitation. It must be accepted by
+// the account the invitation was sent to.
+func (service *storeMemberServiceImpl) AcceptInvitation(user_id uint, input models.StoreInvitationAcceptRequest) (models.StoreMembershipResponse, error) {
+	token := strings.TrimSpace(input.Token)
+	if token == "" {
+		return models.StoreMembershipResponse{}, errors.New("token is required")
+	}
+
+	invitation, err := service.repository.FindInvitationByToken(hashInvitationToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.StoreMembershipResponse{}, errors.New("invalid invitation")
+	}
+	if err != nil {
+		return models.StoreMembershipResponse{}, err
+	}
+	if invitation.DiterimaPada != nil {
+		return models.StoreMembershipResponse{}, errors.New("invitation was already accepted")
+	}
+	if !invitation.KedaluwarsaPada.After(time.Now()) {
+		return models.StoreMembershipResponse{}, errors.New("invitation has expired")
+	}
+
+	user, err := service.repositoryUser.FindById(user_id)
+	if err != nil {
+		return models.StoreMembershipResponse{}, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return models.StoreMembershipResponse{}, errors.New("invitation was sent to another email")
+	}
+
+	store, err := service.repositoryStore.FindById(invitation.IDToko)
+	if err != nil {
+		return models.StoreMembershipResponse{}, err
+	}
+
+	member, err := service.repository.AcceptInvitation(invitation.ID, user_id)
+	if err != nil {
+		return models.StoreMembershipResponse{}, err
+	}
+
+	return models.StoreMembershipResponse{
+		Peran: member.Peran,
+		Izin:  models.StoreMemberPermissions(member.Izin),
+		Store: models.ToStoreResponse(store),
+	}, nil
+}
+
+// GetMemberships lists the stores the user works in, their own included
+func (service *storeMemberServiceImpl) GetMemberships(user_id uint) ([]models.StoreMembershipResponse, error) {
+	members, err := service.repository.FindByUserId(user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.StoreMembershipResponse{}
+	for _, member := range members {
+		responses = append(responses, models.StoreMembershipResponse{
+			Peran: member.Peran,
+			Izin:  models.StoreMemberPermissions(member.Izin),
+			Store: models.ToStoreResponse(member.Store),
+		})
+	}
+	return responses, nil
+}
+
+func (service *storeMemberServiceImpl) invitationLink(token string) string {
+	if service.inviteURL == "" {
+		return "Invitation token: " + token
+	}
+
+	separator := "?"
+	if strings.Contains(service.inviteURL, "?") {
+		separator = "&"
+	}
+	return service.inviteURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// canManageRole reports whether actor may change, remove or invite members
+// with role peran. Owners manage managers and staff, managers only staff.
+func canManageRole(actor entities.StoreMember, peran string) bool {
+	switch actor.Peran {
+	case models.StoreRoleOwner:
+		return peran == models.StoreRoleManager || peran == models.StoreRoleStaff
+	case models.StoreRoleManager:
+		return peran == models.StoreRoleStaff
+	default:
+		return false
+	}
+}
+
+// validStoreRole checks a role actor wants to give and returns it with the
+// permissions to store. Managers can do everything, so only staff keep a
+// list of permissions.
+func validStoreRole(actor entities.StoreMember, peran string, izin []string) (string, string, error) {
+	if peran == "" {
+		peran = models.StoreRoleStaff
+	}
+	if peran != models.StoreRoleManager && peran != models.StoreRoleStaff {
+		return "", "", fmt.Errorf("invalid peran %q, expected %s or %s", peran, models.StoreRoleManager, models.StoreRoleStaff)
+	}
+	if !canManageRole(actor, peran) {
+		return "", "", errors.New("forbidden")
+	}
+	if peran == models.StoreRoleManager {
+		return peran, "", nil
+	}
+
+	permissions := []string{}
+	for _, v := range izin {
+		if !slices.Contains(models.StoreStaffPermissions, v) {
+			return "", "", fmt.Errorf("invalid izin %q, expected any of %s", v, strings.Join(models.StoreStaffPermissions, ", "))
+		}
+		if !slices.Contains(permissions, v) {
+			permissions = append(permissions, v)
+		}
+	}
+
+	return peran, strings.Join(permissions, ","), nil
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
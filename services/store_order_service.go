@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/responder"
+	"mini-project-evermos/repositories"
+	"slices"
+	"strings"
+)
+
+// Contract
+type StoreOrderService interface {
+	GetAll(limit int, page int, keyword string, status string, store_id uint, user_id uint) (responder.Pagination, error)
+	ChangeStatus(store_id uint, id uint, user_id uint, status string) (models.StoreOrderResponse, error)
+}
+
+type storeOrderServiceImpl struct {
+	repository repositories.StoreOrderRepository
+	access     storeAccess
+}
+
+func NewStoreOrderService(
+	storeOrderRepository *repositories.StoreOrderRepository,
+	storeRepository *repositories.StoreRepository,
+	storeMemberRepository *repositories.StoreMemberRepository,
+) StoreOrderService {
+	return &storeOrderServiceImpl{
+		repository: *storeOrderRepository,
+		access:     newStoreAccess(storeRepository, storeMemberRepository),
+	}
+}
+
+func (service *storeOrderServiceImpl) GetAll(limit int, page int, keyword string, status string, store_id uint, user_id uint) (responder.Pagination, error) {
+	if status != "" && !slices.Contains(models.OrderStatuses, status) {
+		return responder.Pagination{}, fmt.Errorf("invalid status %q, expected one of %s", status, strings.Join(models.OrderStatuses, ", "))
+	}
+
+	if err := service.access.check(store_id, user_id, models.StorePermissionProcessOrders); err != nil {
+		return responder.Pagination{}, err
+	}
+
+	request := responder.Pagination{}
+	request.Limit = limit
+	request.Page = page
+	request.Keyword = keyword
+
+	return service.repository.FindAllPagination(request, store_id, status)
+}
+
+// ChangeStatus moves one of the store's items along, see
+// models.StoreOrderTransitions.
+func (service *storeOrderServiceImpl) ChangeStatus(store_id uint, id uint, user_id uint, status string) (models.StoreOrderResponse, error) {
+	if err := service.access.check(store_id, user_id, models.StorePermissionProcessOrders); err != nil {
+		return models.StoreOrderResponse{}, err
+	}
+
+	detail, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreOrderResponse{}, err
+	}
+	if detail.IDToko != store_id {
+		return models.StoreOrderResponse{}, errors.New("forbidden")
+	}
+
+	if !slices.Contains(models.StoreOrderTransitions[detail.Status], status) {
+		return models.StoreOrderResponse{}, fmt.Errorf("cannot change status from %s to %s", detail.Status, status)
+	}
+
+	updated, err := service.repository.UpdateStatus(id, detail.Status, status)
+	if err != nil {
+		return models.StoreOrderResponse{}, err
+	}
+	if !updated {
+		return models.StoreOrderResponse{}, errors.New("order was changed in the meantime, try again")
+	}
+
+	detail, err = service.repository.FindById(id)
+	if err != nil {
+		return models.StoreOrderResponse{}, err
+	}
+
+	return models.ToStoreOrderResponse(detail), nil
+}
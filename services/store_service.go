@@ -2,11 +2,18 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"mini-project-evermos/models"
 	"mini-project-evermos/models/entities"
 	"mini-project-evermos/models/responder"
 	"mini-project-evermos/repositories"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Contract
@@ -14,18 +21,24 @@ type StoreService interface {
 	GetAll(limit int, page int, keyword string) (responder.Pagination, error)
 	GetByUserId(id uint) (models.StoreResponse, error)
 	GetById(id uint, user_id uint) (models.StoreResponse, error)
+	GetBySlug(slug string) (models.StoreResponse, error)
 	Create(input models.StoreProcess) (models.StoreResponse, error)
 	Edit(input models.StoreProcess) (models.StoreResponse, error) // Changed return type
 	Delete(id uint, user_id uint) (models.StoreResponse, error)   // Add this line
+	SetVacation(id uint, user_id uint, input models.StoreVacationRequest) (models.StoreResponse, error)
 }
 
 type storeServiceImpl struct {
-	repository repositories.StoreRepository
+	repository   repositories.StoreRepository
+	mediaService MediaService
+	access       storeAccess
 }
 
-func NewStoreService(storeRepository *repositories.StoreRepository) StoreService {
+func NewStoreService(storeRepository *repositories.StoreRepository, storeMemberRepository *repositories.StoreMemberRepository, mediaService *MediaService) StoreService {
 	return &storeServiceImpl{
-		repository: *storeRepository,
+		repository:   *storeRepository,
+		mediaService: *mediaService,
+		access:       newStoreAccess(storeRepository, storeMemberRepository),
 	}
 }
 
@@ -51,14 +64,7 @@ func (service *storeServiceImpl) GetByUserId(user_id uint) (models.StoreResponse
 		return models.StoreResponse{}, err
 	}
 
-	var response = models.StoreResponse{}
-	response.ID = store.ID
-	response.NamaToko = store.NamaToko
-	response.UrlFoto = store.UrlFoto
-	response.CreatedAt = store.CreatedAt
-	response.UpdatedAt = store.UpdatedAt
-
-	return response, nil
+	return models.ToStoreResponse(store), nil
 }
 
 func (service *storeServiceImpl) GetById(id uint, user_id uint) (models.StoreResponse, error) {
@@ -73,37 +79,42 @@ func (service *storeServiceImpl) GetById(id uint, user_id uint) (models.StoreRes
 	//     return models.StoreResponse{}, errors.New("forbidden")
 	// }
 
-	var response = models.StoreResponse{}
-	response.ID = store.ID
-	response.NamaToko = store.NamaToko
-	response.UrlFoto = store.UrlFoto
-	response.CreatedAt = store.CreatedAt
-	response.UpdatedAt = store.UpdatedAt
+	return models.ToStoreResponse(store), nil
+}
 
-	return response, nil
+func (service *storeServiceImpl) GetBySlug(slug string) (models.StoreResponse, error) {
+	store, err := service.repository.FindBySlug(slug)
+	if err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	return models.ToStoreResponse(store), nil
 }
 
 func (service *storeServiceImpl) Create(input models.StoreProcess) (models.StoreResponse, error) {
+	// A user has one store, made when they registered. Users who deleted
+	// theirs can open a new one.
+	if _, err := service.repository.FindByUserId(input.UserID); err == nil {
+		return models.StoreResponse{}, errors.New("user already has a store")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.StoreResponse{}, err
+	}
+
 	store := entities.Store{
 		IDUser:   input.UserID,
 		NamaToko: input.NamaToko,
 		UrlFoto:  &input.URL,
 	}
+	if err := applyStoreProfile(&store, input); err != nil {
+		return models.StoreResponse{}, err
+	}
 
 	created_store, err := service.repository.Insert(store)
 	if err != nil {
 		return models.StoreResponse{}, err
 	}
 
-	response := models.StoreResponse{
-		ID:        created_store.ID,
-		NamaToko:  created_store.NamaToko,
-		UrlFoto:   created_store.UrlFoto,
-		CreatedAt: created_store.CreatedAt,
-		UpdatedAt: created_store.UpdatedAt,
-	}
-
-	return response, nil
+	return models.ToStoreResponse(created_store), nil
 }
 
 func (service *storeServiceImpl) Edit(input models.StoreProcess) (models.StoreResponse, error) {
@@ -112,47 +123,51 @@ func (service *storeServiceImpl) Edit(input models.StoreProcess) (models.StoreRe
 		return models.StoreResponse{}, err
 	}
 
-	if store.IDUser != input.UserID {
-		return models.StoreResponse{}, errors.New("forbidden")
+	if err := service.access.check(store.ID, input.UserID, models.StorePermissionManageStore); err != nil {
+		return models.StoreResponse{}, err
 	}
 
-	date_now := time.Now()
-	string_date := date_now.Format("2006_01_02_15_04_05")
-	filename := string_date + "-" + input.URL
-
-	req := entities.Store{}
+	req := store
 	req.NamaToko = input.NamaToko
-	req.UrlFoto = &filename
+	req.UrlFoto = &input.URL
+	if err := applyStoreProfile(&req, input); err != nil {
+		return models.StoreResponse{}, err
+	}
 
 	success, err := service.repository.Update(input.ID, req)
 	if err != nil || !success {
 		return models.StoreResponse{}, err
 	}
 
+	// The replaced photos go once no other record shares them
+	if store.UrlFoto != nil && *store.UrlFoto != input.URL {
+		service.mediaService.Remove(*store.UrlFoto)
+	}
+	if store.UrlBanner != nil && (req.UrlBanner == nil || *store.UrlBanner != *req.UrlBanner) {
+		service.mediaService.Remove(*store.UrlBanner)
+	}
+
 	// Fetch updated store
 	updated_store, err := service.repository.FindById(input.ID)
 	if err != nil {
 		return models.StoreResponse{}, err
 	}
 
-	response := models.StoreResponse{
-		ID:        updated_store.ID,
-		NamaToko:  updated_store.NamaToko,
-		UrlFoto:   updated_store.UrlFoto,
-		CreatedAt: updated_store.CreatedAt,
-		UpdatedAt: updated_store.UpdatedAt,
-	}
-
-	return response, nil
+	return models.ToStoreResponse(updated_store), nil
 }
 
+// Delete closes the store. Only its owner can.
 func (service *storeServiceImpl) Delete(id uint, user_id uint) (models.StoreResponse, error) {
 	store, err := service.repository.FindById(id)
 	if err != nil {
 		return models.StoreResponse{}, err
 	}
 
-	if store.IDUser != user_id {
+	member, err := service.access.member(store.ID, user_id, "")
+	if err != nil {
+		return models.StoreResponse{}, err
+	}
+	if member.Peran != models.StoreRoleOwner {
 		return models.StoreResponse{}, errors.New("forbidden")
 	}
 
@@ -161,13 +176,143 @@ func (service *storeServiceImpl) Delete(id uint, user_id uint) (models.StoreResp
 		return models.StoreResponse{}, err
 	}
 
-	response := models.StoreResponse{
-		ID:        store.ID,
-		NamaToko:  store.NamaToko,
-		UrlFoto:   store.UrlFoto,
-		CreatedAt: store.CreatedAt,
-		UpdatedAt: store.UpdatedAt,
+	return models.ToStoreResponse(store), nil
+}
+
+func (service *storeServiceImpl) SetVacation(id uint, user_id uint, input models.StoreVacationRequest) (models.StoreResponse, error) {
+	store, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreResponse{}, err
 	}
 
-	return response, nil
+	if err := service.access.check(store.ID, user_id, models.StorePermissionManageStore); err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	if !input.Libur {
+		input.LiburSampai = nil
+		input.PesanLibur = ""
+	}
+	if input.LiburSampai != nil && !input.LiburSampai.After(time.Now()) {
+		return models.StoreResponse{}, errors.New("libur_sampai must be in the future")
+	}
+	if len(input.PesanLibur) > 255 {
+		return models.StoreResponse{}, errors.New("pesan_libur must be at most 255 characters")
+	}
+
+	if err := service.repository.SetVacation(id, input.Libur, input.LiburSampai, input.PesanLibur); err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	updated_store, err := service.repository.FindById(id)
+	if err != nil {
+		return models.StoreResponse{}, err
+	}
+
+	return models.ToStoreResponse(updated_store), nil
+}
+
+// applyStoreProfile copies the profile fields that were sent onto store and
+// checks them
+func applyStoreProfile(store *entities.Store, input models.StoreProcess) error {
+	if input.UrlBanner != nil {
+		store.UrlBanner = input.UrlBanner
+		if *input.UrlBanner == "" {
+			store.UrlBanner = nil
+		}
+	}
+	if input.Deskripsi != nil {
+		store.Deskripsi = strings.TrimSpace(*input.Deskripsi)
+	}
+	if input.IDProvinsi != nil {
+		store.IDProvinsi = strings.TrimSpace(*input.IDProvinsi)
+	}
+	if input.IDKota != nil {
+		store.IDKota = strings.TrimSpace(*input.IDKota)
+	}
+	if input.Telepon != nil {
+		store.Telepon = strings.TrimSpace(*input.Telepon)
+	}
+	if input.Whatsapp != nil {
+		store.Whatsapp = strings.TrimSpace(*input.Whatsapp)
+	}
+	if input.Email != nil {
+		store.Email = strings.TrimSpace(*input.Email)
+	}
+	if input.Instagram != nil {
+		store.Instagram = strings.TrimPrefix(strings.TrimSpace(*input.Instagram), "@")
+	}
+
+	if len(store.Deskripsi) > 2000 {
+		return errors.New("deskripsi must be at most 2000 characters")
+	}
+	// Regency ids start with the id of their province
+	if store.IDKota != "" && !strings.HasPrefix(store.IDKota, store.IDProvinsi) {
+		return fmt.Errorf("id_kota %s is not in id_provinsi %q", store.IDKota, store.IDProvinsi)
+	}
+	for _, phone := range []string{store.Telepon, store.Whatsapp} {
+		if phone != "" && !storePhone.MatchString(phone) {
+			return fmt.Errorf("invalid phone number %q", phone)
+		}
+	}
+	if store.Email != "" {
+		if _, err := mail.ParseAddress(store.Email); err != nil {
+			return fmt.Errorf("invalid email: %v", err)
+		}
+	}
+	if store.Instagram != "" && !storeInstagram.MatchString(store.Instagram) {
+		return fmt.Errorf("invalid instagram username %q", store.Instagram)
+	}
+
+	if input.JamOperasional != nil {
+		hours, err := toStoreHours(input.JamOperasional)
+		if err != nil {
+			return err
+		}
+		store.JamOperasional = hours
+	}
+
+	return nil
+}
+
+var (
+	storePhone     = regexp.MustCompile(`^\+?[0-9][0-9 -]{5,19}$`)
+	storeInstagram = regexp.MustCompile(`^[A-Za-z0-9._]{1,30}$`)
+)
+
+// toStoreHours checks the opening hours: one entry per day at most, HH:MM
+// times, opening before closing
+func toStoreHours(input []models.StoreHourRequest) ([]entities.StoreHour, error) {
+	hours := []entities.StoreHour{}
+	seen := map[int]bool{}
+	for _, v := range input {
+		if v.Hari < 0 || v.Hari > 6 {
+			return nil, fmt.Errorf("hari must be 0 (Sunday) to 6 (Saturday), got %d", v.Hari)
+		}
+		if seen[v.Hari] {
+			return nil, fmt.Errorf("hari %d is listed twice", v.Hari)
+		}
+		seen[v.Hari] = true
+
+		buka, err := time.Parse("15:04", v.Buka)
+		if err != nil {
+			return nil, fmt.Errorf("invalid buka %q, expected HH:MM", v.Buka)
+		}
+		tutup, err := time.Parse("15:04", v.Tutup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tutup %q, expected HH:MM", v.Tutup)
+		}
+		if !buka.Before(tutup) {
+			return nil, fmt.Errorf("hari %d: buka must be before tutup", v.Hari)
+		}
+
+		hours = append(hours, entities.StoreHour{
+			Hari:  v.Hari,
+			Buka:  buka.Format("15:04"),
+			Tutup: tutup.Format("15:04"),
+		})
+	}
+
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Hari < hours[j].Hari })
+	return hours, nil
 }
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"mini-project-evermos/utils/suggest"
+	"time"
+)
+
+type SuggestService interface {
+	Suggest(query string, limit int) (models.SuggestResponse, error)
+	LogQuery(keyword string, total int64, client string)
+	ProductSaved(product entities.Product)
+	ProductRemoved(id uint)
+	Rebuild() error
+}
+
+type suggestServiceImpl struct {
+	repository         repositories.SearchLogRepository
+	repositoryProduct  repositories.ProductRepository
+	repositoryCategory repositories.CategoryRepository
+	trie               *suggest.Trie
+}
+
+const (
+	// popularWindow is how far back searches count towards popularity.
+	popularWindow = 30 * 24 * time.Hour
+	popularLimit  = 1000
+	minQueryLen   = 2
+	// minQueryClients is how many clients must search a query before it is
+	// suggested to everyone, so one client cannot plant suggestions.
+	minQueryClients = 3
+)
+
+func NewSuggestService(searchLogRepository *repositories.SearchLogRepository, productRepository *repositories.ProductRepository, categoryRepository *repositories.CategoryRepository) SuggestService {
+	return &suggestServiceImpl{
+		repository:         *searchLogRepository,
+		repositoryProduct:  *productRepository,
+		repositoryCategory: *categoryRepository,
+		trie:               suggest.NewTrie(),
+	}
+}
+
+// Suggest completes a partial query. Without a query it only returns the
+// most popular searches.
+func (service *suggestServiceImpl) Suggest(query string, limit int) (models.SuggestResponse, error) {
+	response := models.SuggestResponse{
+		Query:      suggest.Normalize(query),
+		Products:   []models.SuggestItem{},
+		Categories: []models.SuggestItem{},
+		Stores:     []models.SuggestItem{},
+		Queries:    toSuggestItems(service.trie.Prefix(query, suggest.KindQuery, limit)),
+	}
+
+	if response.Query == "" {
+		return response, nil
+	}
+
+	response.Products = toSuggestItems(service.trie.Prefix(query, suggest.KindProduct, limit))
+	response.Categories = toSuggestItems(service.trie.Prefix(query, suggest.KindCategory, limit))
+	response.Stores = toSuggestItems(service.trie.Prefix(query, suggest.KindStore, limit))
+
+	return response, nil
+}
+
+// LogQuery records a catalog search by client, the searcher's IP. Searches
+// reach the suggestions on the next Rebuild, and only once enough clients
+// made them.
+func (service *suggestServiceImpl) LogQuery(keyword string, total int64, client string) {
+	keyword = suggest.Normalize(keyword)
+	if len(keyword) < minQueryLen {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(client))
+	_, err := service.repository.Insert(entities.SearchLog{KataKunci: keyword, JumlahHasil: total, Klien: hex.EncodeToString(sum[:])})
+	if err != nil {
+		log.Printf("Failed to log search %q: %v", keyword, err)
+	}
+}
+
+// ProductSaved refreshes a product's suggestion, keeping its popularity, or
+// drops it once the product leaves the catalog. Category and store counts
+// catch up on the next Rebuild.
+func (service *suggestServiceImpl) ProductSaved(product entities.Product) {
+	// Only catalog products are suggested
+	if product.Status != models.ProductStatusPublished {
+		service.trie.Remove(suggest.KindProduct, product.ID)
+		return
+	}
+
+	service.trie.AddWeight(suggest.Entry{
+		Kind:   suggest.KindProduct,
+		ID:     product.ID,
+		Text:   product.NamaProduk,
+		Slug:   product.Slug,
+		Weight: 1,
+	}, 0)
+
+	if product.Category.ID != 0 {
+		service.trie.AddWeight(suggest.Entry{Kind: suggest.KindCategory, ID: product.Category.ID, Text: product.Category.NamaCategory}, 0)
+	}
+	if product.Store.ID != 0 && product.Store.NamaToko != nil {
+		service.trie.AddWeight(suggest.Entry{Kind: suggest.KindStore, ID: product.Store.ID, Text: *product.Store.NamaToko}, 0)
+	}
+}
+
+func (service *suggestServiceImpl) ProductRemoved(id uint) {
+	service.trie.Remove(suggest.KindProduct, id)
+}
+
+// Rebuild loads every suggestion from the database into a fresh trie and
+// swaps it in. Products rank by units sold, categories and stores by their
+// product count and queries by how many clients searched them.
+func (service *suggestServiceImpl) Rebuild() error {
+	products, err := service.repositoryProduct.FindAll()
+	if err != nil {
+		return err
+	}
+
+	sold, err := service.repositoryProduct.FindSoldCounts()
+	if err != nil {
+		return err
+	}
+
+	categories, err := service.repositoryCategory.FindAll()
+	if err != nil {
+		return err
+	}
+
+	popular, err := service.repository.FindPopular(time.Now().Add(-popularWindow), minQueryClients, popularLimit)
+	if err != nil {
+		return err
+	}
+
+	category_count := map[uint]int{}
+	store_count := map[uint]int{}
+	stores := map[uint]entities.Store{}
+
+	trie := suggest.NewTrie()
+	for _, product := range products {
+		if product.Status != models.ProductStatusPublished {
+			continue
+		}
+
+		trie.Upsert(suggest.Entry{
+			Kind:   suggest.KindProduct,
+			ID:     product.ID,
+			Text:   product.NamaProduk,
+			Slug:   product.Slug,
+			Weight: float64(1 + sold[product.ID]),
+		})
+
+		category_count[product.IDCategory]++
+		store_count[product.IDToko]++
+		stores[product.IDToko] = product.Store
+	}
+
+	for _, category := range categories {
+		trie.Upsert(suggest.Entry{
+			Kind:   suggest.KindCategory,
+			ID:     category.ID,
+			Text:   category.NamaCategory,
+			Weight: float64(category_count[category.ID]),
+		})
+	}
+
+	for id, store := range stores {
+		if store.NamaToko == nil {
+			continue
+		}
+		trie.Upsert(suggest.Entry{
+			Kind:   suggest.KindStore,
+			ID:     id,
+			Text:   *store.NamaToko,
+			Weight: float64(store_count[id]),
+		})
+	}
+
+	for _, query := range popular {
+		trie.Upsert(suggest.Entry{
+			Kind:   suggest.KindQuery,
+			Text:   query.KataKunci,
+			Weight: float64(query.Total),
+		})
+	}
+
+	service.trie.Replace(trie)
+	return nil
+}
+
+func toSuggestItems(entries []suggest.Entry) []models.SuggestItem {
+	items := []models.SuggestItem{}
+	for _, entry := range entries {
+		items = append(items, models.SuggestItem{
+			ID:    entry.ID,
+			Text:  entry.Text,
+			Slug:  entry.Slug,
+			Score: entry.Weight,
+		})
+	}
+	return items
+}
@@ -55,6 +55,12 @@ func (service *transactionServiceImpl) Create(input models.TransactionRequest, u
 		if err != nil {
 			return models.TransactionResponse{}, err
 		}
+		if product.Status != models.ProductStatusPublished {
+			return models.TransactionResponse{}, fmt.Errorf("product %d is not available for sale", product.ID)
+		}
+		if models.StoreOnVacation(product.Store, date_now) {
+			return models.TransactionResponse{}, fmt.Errorf("product %d is not available for sale: the store is on vacation", product.ID)
+		}
 
 		stok, _ := strconv.Atoi(product.HargaKonsumen)
 		total_detail := stok * detail.Kuantitas
@@ -129,26 +135,15 @@ func (service *transactionServiceImpl) GetById(id uint, user_id uint) (models.Tr
 		// Convert product pictures
 		var photos []models.ProductPictureResponse
 		for _, pic := range detail.ProductLog.Product.ProductPicture {
-			photos = append(photos, models.ProductPictureResponse{
-				ID:        pic.ID,
-				IDProduk:  pic.IDProduk,
-				Url:       pic.Url,
-				CreatedAt: pic.CreatedAt,
-				UpdatedAt: pic.UpdatedAt,
-			})
+			photos = append(photos, models.ToProductPictureResponse(pic))
 		}
 
 		details = append(details, models.TransactionDetailResponse{
 			ID:         detail.ID,
 			Kuantitas:  detail.Kuantitas,
 			HargaTotal: detail.HargaTotal,
-			Store: models.StoreResponse{
-				ID:        detail.Store.ID,
-				NamaToko:  detail.Store.NamaToko,
-				UrlFoto:   detail.Store.UrlFoto,
-				CreatedAt: detail.Store.CreatedAt,
-				UpdatedAt: detail.Store.UpdatedAt,
-			},
+			Status:     detail.Status,
+			Store:      models.ToStoreResponse(detail.Store),
 			Product: models.ProductResponse{
 				ID:            detail.ProductLog.Product.ID,
 				NamaProduk:    detail.ProductLog.Product.NamaProduk,
@@ -157,13 +152,7 @@ func (service *transactionServiceImpl) GetById(id uint, user_id uint) (models.Tr
 				HargaKonsumen: detail.ProductLog.Product.HargaKonsumen,
 				Stok:          detail.ProductLog.Product.Stok,
 				Deskripsi:     detail.ProductLog.Product.Deskripsi,
-				Store: models.StoreResponse{
-					ID:        detail.ProductLog.Product.Store.ID,
-					NamaToko:  detail.ProductLog.Product.Store.NamaToko,
-					UrlFoto:   detail.ProductLog.Product.Store.UrlFoto,
-					CreatedAt: detail.ProductLog.Product.Store.CreatedAt,
-					UpdatedAt: detail.ProductLog.Product.Store.UpdatedAt,
-				},
+				Store:         models.ToStoreResponse(detail.ProductLog.Product.Store),
 				Category: models.CategoryResponse{
 					ID:           detail.ProductLog.Product.Category.ID,
 					NamaCategory: detail.ProductLog.Product.Category.NamaCategory,
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mini-project-evermos/models"
+	"mini-project-evermos/models/entities"
+	"mini-project-evermos/repositories"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationPriceDrop   = "price_drop"
+	NotificationBackInStock = "back_in_stock"
+)
+
+const (
+	// maxConcurrentWishlistNotices bounds the products whose watchers are
+	// being notified at once.
+	maxConcurrentWishlistNotices = 4
+	// wishlistNoticeCooldown keeps a price moving up and down from notifying
+	// the same users about the same product over and over.
+	wishlistNoticeCooldown = 24 * time.Hour
+)
+
+// Contract
+type WishlistService interface {
+	GetAll(user_id uint) ([]models.WishlistResponse, error)
+	GetById(id uint, user_id uint) (models.WishlistResponse, error)
+	Create(input models.WishlistRequest, user_id uint) (models.WishlistResponse, error)
+	Rename(id uint, user_id uint, input models.WishlistRequest) (models.WishlistResponse, error)
+	Delete(id uint, user_id uint) error
+	AddItem(id uint, user_id uint, input models.WishlistItemRequest) (models.WishlistResponse, error)
+	AddToDefault(user_id uint, input models.WishlistItemRequest) (models.WishlistResponse, error)
+	RemoveItem(id uint, user_id uint, product_id uint) (models.WishlistResponse, error)
+	ProductChanged(before entities.Product, after entities.Product)
+}
+
+type wishlistServiceImpl struct {
+	repository          repositories.WishlistRepository
+	repositoryProduct   repositories.ProductRepository
+	notificationService NotificationService
+	slots               chan struct{}
+	mutex               sync.Mutex
+	pending             map[string]bool // product and type of the notices queued or being sent
+}
+
+func NewWishlistService(wishlistRepository *repositories.WishlistRepository, productRepository *repositories.ProductRepository, notificationService *NotificationService) WishlistService {
+	return &wishlistServiceImpl{
+		repository:          *wishlistRepository,
+		repositoryProduct:   *productRepository,
+		notificationService: *notificationService,
+		slots:               make(chan struct{}, maxConcurrentWishlistNotices),
+		pending:             map[string]bool{},
+	}
+}
+
+func (service *wishlistServiceImpl) GetAll(user_id uint) ([]models.WishlistResponse, error) {
+	wishlists, err := service.repository.FindByUserId(user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []models.WishlistResponse{}
+	for _, wishlist := range wishlists {
+		responses = append(responses, models.WishlistResponse{
+			ID:         wishlist.ID,
+			Nama:       wishlist.Nama,
+			JumlahItem: len(wishlist.Items),
+			CreatedAt:  wishlist.CreatedAt,
+			UpdatedAt:  wishlist.UpdatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (service *wishlistServiceImpl) GetById(id uint, user_id uint) (models.WishlistResponse, error) {
+	wishlist, err := service.repository.FindById(id)
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	if wishlist.IDUser != user_id {
+		return models.WishlistResponse{}, errors.New("forbidden")
+	}
+
+	items := []models.WishlistItemResponse{}
+	for _, item := range wishlist.Items {
+		items = append(items, models.WishlistItemResponse{
+			ID:        item.ID,
+			Product:   models.ToCatalogProductResponse(models.ToProductResponse(item.Product)),
+			CreatedAt: item.CreatedAt,
+		})
+	}
+
+	return models.WishlistResponse{
+		ID:         wishlist.ID,
+		Nama:       wishlist.Nama,
+		JumlahItem: len(items),
+		Items:      items,
+		CreatedAt:  wishlist.CreatedAt,
+		UpdatedAt:  wishlist.UpdatedAt,
+	}, nil
+}
+
+func (service *wishlistServiceImpl) Create(input models.WishlistRequest, user_id uint) (models.WishlistResponse, error) {
+	nama, err := service.validName(input.Nama, user_id, 0)
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	wishlist, err := service.repository.Insert(entities.Wishlist{IDUser: user_id, Nama: nama})
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	return service.GetById(wishlist.ID, user_id)
+}
+
+func (service *wishlistServiceImpl) Rename(id uint, user_id uint, input models.WishlistRequest) (models.WishlistResponse, error) {
+	if _, err := service.owned(id, user_id); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	nama, err := service.validName(input.Nama, user_id, id)
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	if err := service.repository.Rename(id, nama); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	return service.GetById(id, user_id)
+}
+
+func (service *wishlistServiceImpl) Delete(id uint, user_id uint) error {
+	if _, err := service.owned(id, user_id); err != nil {
+		return err
+	}
+
+	return service.repository.Delete(id)
+}
+
+func (service *wishlistServiceImpl) AddItem(id uint, user_id uint, input models.WishlistItemRequest) (models.WishlistResponse, error) {
+	if _, err := service.owned(id, user_id); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	product, err := service.repositoryProduct.FindById(input.ProductID)
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+	if product.Status != models.ProductStatusPublished {
+		return models.WishlistResponse{}, errors.New("product is not available")
+	}
+
+	if err := service.repository.AddItem(id, product.ID); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	return service.GetById(id, user_id)
+}
+
+// AddToDefault saves a product to the user's default wishlist, creating the
+// wishlist on first use.
+func (service *wishlistServiceImpl) AddToDefault(user_id uint, input models.WishlistItemRequest) (models.WishlistResponse, error) {
+	wishlist, err := service.repository.FindByName(user_id, models.DefaultWishlistName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		wishlist, err = service.repository.Insert(entities.Wishlist{IDUser: user_id, Nama: models.DefaultWishlistName})
+	}
+	if err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	return service.AddItem(wishlist.ID, user_id, input)
+}
+
+func (service *wishlistServiceImpl) RemoveItem(id uint, user_id uint, product_id uint) (models.WishlistResponse, error) {
+	if _, err := service.owned(id, user_id); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	if err := service.repository.RemoveItem(id, product_id); err != nil {
+		return models.WishlistResponse{}, err
+	}
+
+	return service.GetById(id, user_id)
+}
+
+// ProductChanged notifies users who wishlisted a published product when its
+// price drops or it comes back in stock. The notices go out in the
+// background; failures are logged.
+func (service *wishlistServiceImpl) ProductChanged(before entities.Product, after entities.Product) {
+	if after.Status != models.ProductStatusPublished {
+		return
+	}
+
+	var tipe, judul, pesan string
+	old_price, err_old := strconv.Atoi(before.HargaKonsumen)
+	new_price, err_new := strconv.Atoi(after.HargaKonsumen)
+	switch {
+	case before.Stok <= 0 && after.Stok > 0:
+		tipe = NotificationBackInStock
+		judul = fmt.Sprintf("Back in stock: %s", after.NamaProduk)
+		pesan = fmt.Sprintf("%s from your wishlist is available again.", after.NamaProduk)
+	case err_old == nil && err_new == nil && new_price < old_price && after.Stok > 0:
+		tipe = NotificationPriceDrop
+		judul = fmt.Sprintf("Price drop: %s", after.NamaProduk)
+		pesan = fmt.Sprintf("%s from your wishlist dropped from %d to %d.", after.NamaProduk, old_price, new_price)
+	default:
+		return
+	}
+
+	// A notice of the same kind still on its way covers this change too
+	key := fmt.Sprintf("%d:%s", after.ID, tipe)
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	if service.pending[key] {
+		return
+	}
+	service.pending[key] = true
+
+	go service.notifyWatchers(key, after.ID, tipe, judul, pesan)
+}
+
+// notifyWatchers sends a notice to everyone watching the product, outside the
+// seller's request since delivery can be slow.
+func (service *wishlistServiceImpl) notifyWatchers(key string, product_id uint, tipe string, judul string, pesan string) {
+	service.slots <- struct{}{}
+	defer func() {
+		<-service.slots
+
+		service.mutex.Lock()
+		delete(service.pending, key)
+		service.mutex.Unlock()
+	}()
+
+	user_ids, err := service.repository.FindWatchers(product_id, tipe, time.Now().Add(-wishlistNoticeCooldown))
+	if err != nil {
+		log.Printf("Failed to find wishlists of product %d: %v", product_id, err)
+		return
+	}
+
+	for _, user_id := range user_ids {
+		if err := service.notificationService.SendToUser(user_id, &product_id, tipe, judul, pesan); err != nil {
+			log.Printf("Failed to notify user %d of product %d: %v", user_id, product_id, err)
+		}
+	}
+}
+
+func (service *wishlistServiceImpl) owned(id uint, user_id uint) (entities.Wishlist, error) {
+	wishlist, err := service.repository.FindById(id)
+	if err != nil {
+		return entities.Wishlist{}, err
+	}
+
+	if wishlist.IDUser != user_id {
+		return entities.Wishlist{}, errors.New("forbidden")
+	}
+
+	return wishlist, nil
+}
+
+// validName trims a wishlist name and checks the user has no wishlist other
+// than id by that name.
+func (service *wishlistServiceImpl) validName(nama string, user_id uint, id uint) (string, error) {
+	nama = strings.TrimSpace(nama)
+	if nama == "" {
+		return "", errors.New("nama is required")
+	}
+	if len(nama) > 100 {
+		return "", errors.New("nama must be at most 100 characters")
+	}
+
+	existing, err := service.repository.FindByName(user_id, nama)
+	if err == nil && existing.ID != id {
+		return "", fmt.Errorf("you already have a wishlist named %q", nama)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return nama, nil
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Mailer sends plain text email.
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// New picks a mailer from MAIL_DRIVER ("log" or "smtp"), defaulting to log.
+func New() Mailer {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "smtp":
+		return NewSMTPMailer(
+			net.JoinHostPort(os.Getenv("MAIL_SMTP_HOST"), os.Getenv("MAIL_SMTP_PORT")),
+			os.Getenv("MAIL_SMTP_USERNAME"),
+			os.Getenv("MAIL_SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	default:
+		return NewLogMailer()
+	}
+}
+
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(to string, subject string, body string) error {
+	log.Printf("[mailer] to=%s subject=%q body=%q", to, subject, body)
+	return nil
+}
+
+type smtpMailer struct {
+	address  string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(address string, username string, password string, from string) Mailer {
+	return &smtpMailer{
+		address:  address,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	if m.from == "" {
+		return fmt.Errorf("mail sender is not configured")
+	}
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+
+	// MAIL_FROM may carry a display name, the envelope only takes the address
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, err := net.SplitHostPort(m.address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	message := "From: " + m.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(m.address, auth, sender.Address, []string{to}, []byte(message))
+}
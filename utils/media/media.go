@@ -0,0 +1,249 @@
+// Package media validates uploaded images and prepares the files stored for
+// them: the original re-encoded without its metadata plus resized variants,
+// all named after a hash of their content so identical uploads share files.
+package media
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const SizeOriginal = "original"
+
+// Size is a variant that fits inside a Max x Max box. Images smaller than
+// the box keep their dimensions, they are never upscaled.
+type Size struct {
+	Name string
+	Max  int
+}
+
+var Sizes = []Size{
+	{Name: "thumb", Max: 150},
+	{Name: "small", Max: 300},
+	{Name: "medium", Max: 600},
+	{Name: "large", Max: 1200},
+}
+
+type Limits struct {
+	MaxBytes     int64
+	MaxDimension int // longest side in pixels
+	// MaxPixels caps width x height. A small compressed file can decode to
+	// gigabytes, every pixel takes 4 bytes and more while processing.
+	MaxPixels int
+}
+
+var DefaultLimits = Limits{
+	MaxBytes:     4 << 20,
+	MaxDimension: 8000,
+	MaxPixels:    40_000_000,
+}
+
+var (
+	ErrEmpty       = errors.New("image is empty")
+	ErrUnsupported = errors.New("image must be a JPEG, PNG or GIF")
+)
+
+// File is one stored file of an image
+type File struct {
+	Name string
+	Size string
+	Data []byte
+}
+
+type Image struct {
+	Name     string // file name of the original, the value kept in the database
+	MimeType string
+	Width    int
+	Height   int
+	Files    []File
+}
+
+var formats = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "png", // only the first frame is kept
+}
+
+var mimeTypes = map[string]string{
+	"jpg": "image/jpeg",
+	"png": "image/png",
+}
+
+// Process checks an upload against the limits and renders its files. The
+// type is sniffed from the content, the client's file name is not trusted.
+func Process(data []byte, limits Limits) (Image, error) {
+	if len(data) == 0 {
+		return Image{}, ErrEmpty
+	}
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return Image{}, fmt.Errorf("image is larger than %d bytes", limits.MaxBytes)
+	}
+
+	ext, ok := formats[http.DetectContentType(data)]
+	if !ok {
+		return Image{}, ErrUnsupported
+	}
+
+	// Check the dimensions before decoding so oversized images are never
+	// allocated
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return Image{}, ErrUnsupported
+	}
+	if limits.MaxDimension > 0 && (config.Width > limits.MaxDimension || config.Height > limits.MaxDimension) {
+		return Image{}, fmt.Errorf("image is larger than %dx%d pixels", limits.MaxDimension, limits.MaxDimension)
+	}
+	if limits.MaxPixels > 0 && config.Width*config.Height > limits.MaxPixels {
+		return Image{}, fmt.Errorf("image has more than %d pixels", limits.MaxPixels)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupported
+	}
+
+	// Re-encoding drops EXIF and every other metadata block, so the
+	// orientation it carried is applied to the pixels first
+	img := orient(toRGBA(decoded), jpegOrientation(data))
+
+	original, err := encode(img, ext)
+	if err != nil {
+		return Image{}, err
+	}
+
+	sum := sha256.Sum256(original)
+	name := hex.EncodeToString(sum[:16]) + "." + ext
+
+	bounds := img.Bounds()
+	result := Image{
+		Name:     name,
+		MimeType: mimeTypes[ext],
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Files:    []File{{Name: name, Size: SizeOriginal, Data: original}},
+	}
+
+	for _, size := range Sizes {
+		width, height := fit(bounds.Dx(), bounds.Dy(), size.Max)
+
+		variant := original
+		if width != bounds.Dx() || height != bounds.Dy() {
+			variant, err = encode(resize(img, width, height), ext)
+			if err != nil {
+				return Image{}, err
+			}
+		}
+
+		result.Files = append(result.Files, File{
+			Name: VariantName(name, size.Name),
+			Size: size.Name,
+			Data: variant,
+		})
+	}
+
+	return result, nil
+}
+
+func encode(img image.Image, ext string) ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error
+	switch ext {
+	case "jpg":
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 85})
+	default:
+		err = png.Encode(&buffer, img)
+	}
+	return buffer.Bytes(), err
+}
+
+// fit scales width x height down to fit inside a max x max box
+func fit(width int, height int, max int) (int, int) {
+	if width <= max && height <= max {
+		return width, height
+	}
+	if width >= height {
+		return max, clampDimension(height * max / width)
+	}
+	return clampDimension(width * max / height), max
+}
+
+func clampDimension(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+var hashedName = regexp.MustCompile(`^[0-9a-f]{32}\.(jpg|png)$`)
+
+// IsHashed reports whether name was produced by Process. Other names, such
+// as links or uploads from before the media pipeline, have no variants.
+func IsHashed(name string) bool {
+	return hashedName.MatchString(name)
+}
+
+// IsHashedFile is IsHashed that also accepts the names of the sizes
+func IsHashedFile(name string) bool {
+	if IsHashed(name) {
+		return true
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for _, size := range Sizes {
+		if original, found := strings.CutSuffix(base, "_"+size.Name); found && IsHashed(original+ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// VariantName is the file name of a size of the image stored as name
+func VariantName(name string, size string) string {
+	if size == SizeOriginal {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_" + size + ext
+}
+
+// Variants maps every size, the original included, to its file name. It
+// returns nil for names that were not produced by Process.
+func Variants(name string) map[string]string {
+	if !IsHashed(name) {
+		return nil
+	}
+
+	variants := map[string]string{SizeOriginal: name}
+	for _, size := range Sizes {
+		variants[size.Name] = VariantName(name, size.Name)
+	}
+	return variants
+}
+
+// FileNames lists the files stored for name
+func FileNames(name string) []string {
+	if !IsHashed(name) {
+		return []string{name}
+	}
+
+	names := []string{name}
+	for _, size := range Sizes {
+		names = append(names, VariantName(name, size.Name))
+	}
+	return names
+}
@@ -0,0 +1,327 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/url"
+	"testing"
+)
+
+// halves draws an image whose left half is red and right half is blue
+func halves(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// withOrientation inserts an EXIF APP1 segment holding orientation right
+// after the start of image marker
+func withOrientation(data []byte, orientation uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.BigEndian {
+		copy(tiff, "MM")
+	} else {
+		copy(tiff, "II")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, header...)
+	result = append(result, segment...)
+	return append(result, data[2:]...)
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		limits Limits
+		err    error
+	}{
+		{"empty", nil, DefaultLimits, ErrEmpty},
+		{"text", []byte("not an image"), DefaultLimits, ErrUnsupported},
+		{"truncated png", encodePNG(t, halves(4, 4))[:40], DefaultLimits, ErrUnsupported},
+		{"too many bytes", encodePNG(t, halves(4, 4)), Limits{MaxBytes: 10}, nil},
+		{"too wide", encodePNG(t, halves(40, 4)), Limits{MaxDimension: 32}, nil},
+		{"too many pixels", encodePNG(t, halves(40, 40)), Limits{MaxDimension: 64, MaxPixels: 1000}, nil},
+		{"too many pixels by default", encodePNG(t, image.NewGray(image.Rect(0, 0, 7000, 7000))), DefaultLimits, nil},
+	}
+
+	for _, test := range tests {
+		_, err := Process(test.data, test.limits)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		ext      string
+	}{
+		{"png", encodePNG(t, halves(400, 200)), "image/png", ".png"},
+		{"jpeg", encodeJPEG(t, halves(400, 200)), "image/jpeg", ".jpg"},
+	}
+
+	// Variants no larger than the original reuse its data
+	want := map[string][2]int{
+		SizeOriginal: {400, 200},
+		"thumb":      {150, 75},
+		"small":      {300, 150},
+		"medium":     {400, 200},
+		"large":      {400, 200},
+	}
+
+	for _, test := range tests {
+		result, err := Process(test.data, DefaultLimits)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !IsHashed(result.Name) || result.Name[len(result.Name)-4:] != test.ext {
+			t.Errorf("%s: unexpected name %q", test.name, result.Name)
+		}
+		if result.MimeType != test.mimeType || result.Width != 400 || result.Height != 200 {
+			t.Errorf("%s: got %s %dx%d", test.name, result.MimeType, result.Width, result.Height)
+		}
+		if len(result.Files) != len(Sizes)+1 {
+			t.Errorf("%s: got %d files, want %d", test.name, len(result.Files), len(Sizes)+1)
+			continue
+		}
+
+		for _, file := range result.Files {
+			if file.Name != VariantName(result.Name, file.Size) {
+				t.Errorf("%s: %s is stored as %q", test.name, file.Size, file.Name)
+			}
+			config, _, err := image.DecodeConfig(bytes.NewReader(file.Data))
+			if err != nil {
+				t.Errorf("%s: %s: %v", test.name, file.Size, err)
+				continue
+			}
+			if dimensions := want[file.Size]; config.Width != dimensions[0] || config.Height != dimensions[1] {
+				t.Errorf("%s: %s is %dx%d, want %dx%d", test.name, file.Size, config.Width, config.Height, dimensions[0], dimensions[1])
+			}
+		}
+
+		again, err := Process(test.data, DefaultLimits)
+		if err != nil || again.Name != result.Name {
+			t.Errorf("%s: the same upload got another name %q", test.name, again.Name)
+		}
+	}
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	// Orientation 6 turns the image clockwise, so the red left half ends up
+	// on top; 1 keeps it on the left
+	tests := []struct {
+		orientation uint16
+		width       int
+		height      int
+		red         image.Point
+		blue        image.Point
+	}{
+		{1, 32, 16, image.Pt(4, 8), image.Pt(28, 8)},
+		{6, 16, 32, image.Pt(8, 4), image.Pt(8, 28)},
+		{8, 16, 32, image.Pt(8, 28), image.Pt(8, 4)},
+		{3, 32, 16, image.Pt(28, 8), image.Pt(4, 8)},
+	}
+
+	for _, test := range tests {
+		data := withOrientation(encodeJPEG(t, halves(32, 16)), test.orientation, binary.LittleEndian)
+		result, err := Process(data, DefaultLimits)
+		if err != nil {
+			t.Errorf("orientation %d: %v", test.orientation, err)
+			continue
+		}
+		if result.Width != test.width || result.Height != test.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", test.orientation, result.Width, result.Height, test.width, test.height)
+			continue
+		}
+
+		img, err := jpeg.Decode(bytes.NewReader(result.Files[0].Data))
+		if err != nil {
+			t.Errorf("orientation %d: %v", test.orientation, err)
+			continue
+		}
+		if r, _, b, _ := img.At(test.red.X, test.red.Y).RGBA(); r <= b {
+			t.Errorf("orientation %d: %v is not red", test.orientation, test.red)
+		}
+		if r, _, b, _ := img.At(test.blue.X, test.blue.Y).RGBA(); b <= r {
+			t.Errorf("orientation %d: %v is not blue", test.orientation, test.blue)
+		}
+		if jpegOrientation(result.Files[0].Data) != 1 {
+			t.Errorf("orientation %d: the stored file kept its EXIF", test.orientation)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeJPEG(t, halves(8, 8))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", plain, 1},
+		{"little endian", withOrientation(plain, 6, binary.LittleEndian), 6},
+		{"big endian", withOrientation(plain, 8, binary.BigEndian), 8},
+		{"out of range", withOrientation(plain, 9, binary.LittleEndian), 1},
+		{"truncated", withOrientation(plain, 6, binary.LittleEndian)[:20], 1},
+		{"png", encodePNG(t, halves(8, 8)), 1},
+		{"empty", nil, 1},
+	}
+
+	for _, test := range tests {
+		if got := jpegOrientation(test.data); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Where the top left pixel of a 3x2 image ends up
+	tests := []struct {
+		orientation int
+		width       int
+		height      int
+		corner      image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0)},
+		{2, 3, 2, image.Pt(2, 0)},
+		{3, 3, 2, image.Pt(2, 1)},
+		{4, 3, 2, image.Pt(0, 1)},
+		{5, 2, 3, image.Pt(0, 0)},
+		{6, 2, 3, image.Pt(1, 0)},
+		{7, 2, 3, image.Pt(1, 2)},
+		{8, 2, 3, image.Pt(0, 2)},
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.RGBA{255, 255, 255, 255})
+
+	for _, test := range tests {
+		dst := orient(src, test.orientation)
+		if dst.Bounds().Dx() != test.width || dst.Bounds().Dy() != test.height {
+			t.Errorf("orientation %d: got %v, want %dx%d", test.orientation, dst.Bounds(), test.width, test.height)
+			continue
+		}
+		if dst.RGBAAt(test.corner.X, test.corner.Y).R != 255 {
+			t.Errorf("orientation %d: the corner is not at %v", test.orientation, test.corner)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height, max    int
+		wantWidth, wantHeight int
+	}{
+		{100, 50, 150, 100, 50},
+		{400, 200, 150, 150, 75},
+		{200, 400, 150, 75, 150},
+		{3000, 1, 150, 150, 1},
+	}
+
+	for _, test := range tests {
+		width, height := fit(test.width, test.height, test.max)
+		if width != test.wantWidth || height != test.wantHeight {
+			t.Errorf("fit(%d, %d, %d) = %d, %d, want %d, %d", test.width, test.height, test.max, width, height, test.wantWidth, test.wantHeight)
+		}
+	}
+}
+
+func TestIsHashedFile(t *testing.T) {
+	hashed := "0123456789abcdef0123456789abcdef.jpg"
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{hashed, true},
+		{VariantName(hashed, "thumb"), true},
+		{"0123456789abcdef0123456789abcdef_huge.jpg", false},
+		{"0123456789ABCDEF0123456789ABCDEF.jpg", false},
+		{"foto.jpg", false},
+		{"../" + hashed, false},
+	}
+
+	for _, test := range tests {
+		if got := IsHashedFile(test.name); got != test.want {
+			t.Errorf("IsHashedFile(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	defer SetURLResolver(func(name string) string { return name })
+
+	local := func(name string) string { return "/uploads/" + url.PathEscape(name) }
+	signed := func(name string) string { return "https://bucket/" + url.PathEscape(name) + "?X-Amz-Signature=abc" }
+
+	tests := []struct {
+		resolver func(name string) string
+		value    string
+		want     string
+	}{
+		{local, "/uploads/foto.jpg", "foto.jpg"},
+		{local, "/uploads/foto%20produk.jpg", "foto produk.jpg"},
+		{local, "https://example.com/foto.jpg", "https://example.com/foto.jpg"},
+		{local, "foto.jpg", "foto.jpg"},
+		{signed, "https://bucket/foto.jpg?X-Amz-Signature=def", "foto.jpg"},
+		{signed, "https://other/foto.jpg?X-Amz-Signature=def", "https://other/foto.jpg?X-Amz-Signature=def"},
+	}
+
+	for _, test := range tests {
+		SetURLResolver(test.resolver)
+		if got := Name(test.value); got != test.want {
+			t.Errorf("Name(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package media
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// orient turns src the way the EXIF orientation says it should be shown
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dst_width, dst_height := width, height
+	if orientation >= 5 {
+		// 5 to 8 swap the axes
+		dst_width, dst_height = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dst_width, dst_height))
+	for y := 0; y < dst_height; y++ {
+		for x := 0; x < dst_width; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = width-1-x, y
+			case 3: // rotated 180
+				sx, sy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, height-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs a clockwise turn
+				sx, sy = y, height-1-x
+			case 7: // transversed
+				sx, sy = width-1-y, height-1-x
+			case 8: // needs a counter-clockwise turn
+				sx, sy = width-1-y, x
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+type contribution struct {
+	index  int
+	weight float64
+}
+
+// boxWeights averages the source pixels each destination pixel covers,
+// counting partially covered pixels by the covered fraction
+func boxWeights(src int, dst int) [][]contribution {
+	scale := float64(src) / float64(dst)
+	weights := make([][]contribution, dst)
+	for i := range weights {
+		start := float64(i) * scale
+		end := start + scale
+		for j := int(start); j < src && float64(j) < end; j++ {
+			covered := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if covered > 0 {
+				weights[i] = append(weights[i], contribution{j, covered / scale})
+			}
+		}
+	}
+	return weights
+}
+
+// resize scales src down to width x height with an area average, one axis
+// at a time. The RGBA pixels are premultiplied so transparent edges do not
+// darken.
+func resize(src *image.RGBA, width int, height int) *image.RGBA {
+	src_width, src_height := src.Bounds().Dx(), src.Bounds().Dy()
+	columns := boxWeights(src_width, width)
+	rows := boxWeights(src_height, height)
+
+	horizontal := make([]float64, width*src_height*4)
+	for y := 0; y < src_height; y++ {
+		for x, weights := range columns {
+			out := (y*width + x) * 4
+			for _, w := range weights {
+				in := src.PixOffset(w.index, y)
+				for c := 0; c < 4; c++ {
+					horizontal[out+c] += float64(src.Pix[in+c]) * w.weight
+				}
+			}
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, weights := range rows {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+			for _, w := range weights {
+				in := (w.index*width + x) * 4
+				for c := 0; c < 4; c++ {
+					sum[c] += horizontal[in+c] * w.weight
+				}
+			}
+
+			out := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[out+c] = uint8(math.Min(255, math.Round(sum[c])))
+			}
+		}
+	}
+
+	return dst
+}
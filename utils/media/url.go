@@ -0,0 +1,56 @@
+package media
+
+import (
+	"net/url"
+	"strings"
+)
+
+// resolve turns a stored file name into the URL clients fetch it from. main
+// points it at the blob storage on startup.
+var resolve = func(name string) string {
+	return name
+}
+
+// SetURLResolver sets how stored file names become URLs
+func SetURLResolver(resolver func(name string) string) {
+	resolve = resolver
+}
+
+// URL is where clients fetch the upload stored as name. Values that are not
+// plain file names, such as links saved by hand, are returned unchanged.
+func URL(name string) string {
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return name
+	}
+	return resolve(name)
+}
+
+// URLs maps every size of an upload, the original included, to its URL. It
+// returns nil for names that were not produced by Process.
+func URLs(name string) map[string]string {
+	variants := Variants(name)
+	for size, file := range variants {
+		variants[size] = URL(file)
+	}
+	return variants
+}
+
+// Name undoes URL so a URL sent back by a client is stored as the file
+// name. Anything else is returned unchanged. The query is ignored: signed
+// URLs carry a signature that changes every time one is made.
+func Name(value string) string {
+	base, _, _ := strings.Cut(value, "?")
+	i := strings.LastIndex(base, "/")
+	if i < 0 {
+		return value
+	}
+
+	name, err := url.PathUnescape(base[i+1:])
+	if err != nil || name == "" {
+		return value
+	}
+	if resolved, _, _ := strings.Cut(URL(name), "?"); resolved != base {
+		return value
+	}
+	return name
+}
@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Message is what gets delivered to a store, or a user when UserID is set,
+// outside of the in-app inbox.
+type Message struct {
+	StoreID   uint   `json:"store_id"`
+	UserID    uint   `json:"user_id,omitempty"`
+	ProductID *uint  `json:"product_id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+}
+
+// Notifier delivers a message through some external channel.
+type Notifier interface {
+	Notify(message Message) error
+}
+
+// New picks a notifier from NOTIFIER_DRIVER ("log" or "webhook"), defaulting to log.
+func New() Notifier {
+	switch os.Getenv("NOTIFIER_DRIVER") {
+	case "webhook":
+		return NewWebhookNotifier(os.Getenv("NOTIFIER_WEBHOOK_URL"))
+	default:
+		return NewLogNotifier()
+	}
+}
+
+type logNotifier struct{}
+
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(message Message) error {
+	if message.UserID != 0 {
+		log.Printf("[notifier] user=%d type=%s title=%q body=%q", message.UserID, message.Type, message.Title, message.Body)
+		return nil
+	}
+	log.Printf("[notifier] toko=%d type=%s title=%q body=%q", message.StoreID, message.Type, message.Title, message.Body)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Notify(message Message) error {
+	if n.url == "" {
+		return fmt.Errorf("webhook url is not configured")
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	response, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
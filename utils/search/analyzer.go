@@ -0,0 +1,131 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"yang": true, "dan": true, "di": true, "ke": true, "dari": true, "untuk": true,
+	"dengan": true, "ini": true, "itu": true, "atau": true, "pada": true, "juga": true,
+	"ada": true, "adalah": true, "akan": true, "dalam": true, "tidak": true, "bisa": true,
+	"sudah": true, "saya": true, "kami": true, "kita": true, "anda": true, "mereka": true,
+	"oleh": true, "sebagai": true, "karena": true, "jadi": true, "agar": true, "para": true,
+	"serta": true, "sangat": true, "lebih": true, "telah": true, "sama": true, "hanya": true,
+	"tersebut": true, "bagi": true, "buat": true, "nya": true, "pun": true, "lah": true,
+	"kah": true, "the": true, "a": true, "an": true, "of": true, "for": true, "and": true,
+}
+
+// Analyze lowercases, tokenizes, drops stop words and stems text into index terms.
+func Analyze(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := []string{}
+	for _, field := range fields {
+		if stopWords[field] {
+			continue
+		}
+		terms = append(terms, Stem(field))
+	}
+	return terms
+}
+
+// minStem keeps the stemmer from reducing short words to meaningless roots.
+const minStem = 4
+
+var particles = []string{"lah", "kah", "tah", "pun"}
+var possessives = []string{"nya", "ku", "mu"}
+var derivationalSuffixes = []string{"kan", "an"}
+
+// Stem is a light, dictionary-free Indonesian stemmer loosely following
+// Nazief-Adriani: inflectional suffixes, then one derivational prefix, then
+// derivational suffixes. Index and query share it, so over-stemming still
+// matches consistently.
+func Stem(word string) string {
+	if !isAlpha(word) {
+		return word
+	}
+
+	word = trimSuffix(word, particles)
+	word = trimSuffix(word, possessives)
+	word = trimPrefix(word)
+	word = trimSuffix(word, derivationalSuffixes)
+
+	// "-i" ends many roots ("pakai", "beli"), so only strip it from long words
+	if strings.HasSuffix(word, "i") && len(word)-1 > minStem {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+func trimSuffix(word string, suffixes []string) string {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minStem {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
+
+func trimPrefix(word string) string {
+	candidates := []string{}
+
+	switch {
+	case strings.HasPrefix(word, "meny"), strings.HasPrefix(word, "peny"):
+		candidates = append(candidates, "s"+word[4:])
+	case strings.HasPrefix(word, "meng"), strings.HasPrefix(word, "peng"):
+		candidates = append(candidates, word[4:])
+	case strings.HasPrefix(word, "mem"), strings.HasPrefix(word, "pem"):
+		rest := word[3:]
+		if startsWithVowel(rest) {
+			candidates = append(candidates, "p"+rest)
+		} else {
+			candidates = append(candidates, rest)
+		}
+	case strings.HasPrefix(word, "men"), strings.HasPrefix(word, "pen"):
+		rest := word[3:]
+		if startsWithVowel(rest) {
+			candidates = append(candidates, "t"+rest)
+		} else {
+			candidates = append(candidates, rest)
+		}
+	case strings.HasPrefix(word, "me"):
+		candidates = append(candidates, word[2:])
+	case strings.HasPrefix(word, "ber"), strings.HasPrefix(word, "ter"), strings.HasPrefix(word, "per"):
+		candidates = append(candidates, word[3:])
+	case strings.HasPrefix(word, "di"):
+		candidates = append(candidates, word[2:])
+	}
+
+	for _, candidate := range candidates {
+		if len(candidate) >= minStem && !startsWithConsonantCluster(candidate) {
+			return candidate
+		}
+	}
+	return word
+}
+
+func isAlpha(word string) bool {
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+func startsWithVowel(word string) bool {
+	return word != "" && isVowel(word[0])
+}
+
+// Indonesian roots rarely start with two consonants, so a cluster after
+// prefix removal ("diskon" -> "skon") means the prefix was part of the root.
+func startsWithConsonantCluster(word string) bool {
+	return len(word) >= 2 && !isVowel(word[0]) && !isVowel(word[1]) && word[:2] != "ng" && word[:2] != "ny"
+}
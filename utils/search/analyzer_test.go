@@ -0,0 +1,49 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"sepatu", "sepatu"},
+		{"bajunya", "baju"},
+		{"membeli", "beli"},
+		{"pemakaian", "pakai"},
+		{"menyapu", "sapu"},
+		{"berlari", "lari"},
+		{"mainkan", "main"},
+		// the prefix belongs to the root when removing it leaves a cluster
+		{"diskon", "diskon"},
+		// short words and non-letters are left alone
+		{"beli", "beli"},
+		{"usb3", "usb3"},
+	}
+
+	for _, test := range tests {
+		if got := Stem(test.word); got != test.want {
+			t.Errorf("Stem(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Sepatu yang Bagus, untuk Lari!", []string{"sepatu", "bagus", "lari"}},
+		{"  ", []string{}},
+		{"Kabel USB3 1m", []string{"kabel", "usb3", "1m"}},
+	}
+
+	for _, test := range tests {
+		if got := Analyze(test.text); !slices.Equal(got, test.want) {
+			t.Errorf("Analyze(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package search
+
+import "sync"
+
+// vocabulary tracks known index terms so misspelled query terms can be
+// expanded to their closest neighbours.
+type vocabulary struct {
+	mutex sync.RWMutex
+	terms map[string]bool
+}
+
+func newVocabulary() *vocabulary {
+	return &vocabulary{terms: map[string]bool{}}
+}
+
+func (v *vocabulary) add(terms ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	for _, term := range terms {
+		v.terms[term] = true
+	}
+}
+
+// expansion is a term to look up and how much a match on it is worth.
+type expansion struct {
+	Term   string
+	Weight float64
+}
+
+// fuzzyWeight discounts matches that needed a typo correction.
+const fuzzyWeight = 0.6
+
+// expand returns the term itself when known, otherwise the known terms within
+// the allowed edit distance.
+func (v *vocabulary) expand(term string) []expansion {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	if v.terms[term] {
+		return []expansion{{Term: term, Weight: 1}}
+	}
+
+	maxDistance := allowedDistance(term)
+	if maxDistance == 0 {
+		return []expansion{{Term: term, Weight: 1}}
+	}
+
+	expansions := []expansion{}
+	for candidate := range v.terms {
+		if abs(len(candidate)-len(term)) > maxDistance {
+			continue
+		}
+		if distance := editDistance(term, candidate); distance <= maxDistance {
+			expansions = append(expansions, expansion{Term: candidate, Weight: fuzzyWeight / float64(distance)})
+		}
+	}
+
+	if len(expansions) == 0 {
+		return []expansion{{Term: term, Weight: 1}}
+	}
+	return expansions
+}
+
+// allowedDistance tolerates one typo in medium words and two in long ones.
+func allowedDistance(term string) int {
+	switch {
+	case len(term) >= 8:
+		return 2
+	case len(term) >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions each cost one.
+func editDistance(a string, b string) int {
+	before := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], before[j-2]+1)
+			}
+		}
+		before, previous, current = previous, current, before
+	}
+
+	return previous[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"same", "same", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"sepatu", "sepatv", 1},
+		// adjacent transpositions cost one
+		{"abc", "acb", 1},
+		{"sepatu", "sepaut", 1},
+		// but a substring is never edited twice
+		{"ca", "abc", 3},
+	}
+
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestAllowedDistance(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"tas", 0},
+		{"baju", 1},
+		{"sepatu", 1},
+		{"kemejapria", 2},
+	}
+
+	for _, test := range tests {
+		if got := allowedDistance(test.term); got != test.want {
+			t.Errorf("allowedDistance(%q) = %d, want %d", test.term, got, test.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	v := newVocabulary()
+	v.add("sepatu", "sandal", "kemeja", "tas")
+
+	tests := []struct {
+		term string
+		want []expansion
+	}{
+		{"sepatu", []expansion{{Term: "sepatu", Weight: 1}}},
+		{"sepatv", []expansion{{Term: "sepatu", Weight: fuzzyWeight}}},
+		// too short to correct
+		{"taz", []expansion{{Term: "taz", Weight: 1}}},
+		// nothing close enough: searched as is
+		{"zzzzzz", []expansion{{Term: "zzzzzz", Weight: 1}}},
+	}
+
+	for _, test := range tests {
+		if got := v.expand(test.term); !slices.Equal(got, test.want) {
+			t.Errorf("expand(%q) = %v, want %v", test.term, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// memoryIndex is an embedded inverted index, for tests and databases
+// without FULLTEXT support.
+type memoryIndex struct {
+	mutex      sync.RWMutex
+	postings   map[string]map[uint]int
+	documents  map[uint][]string
+	vocabulary *vocabulary
+}
+
+func NewMemoryIndex() SearchIndex {
+	return &memoryIndex{
+		postings:   map[string]map[uint]int{},
+		documents:  map[uint][]string{},
+		vocabulary: newVocabulary(),
+	}
+}
+
+func (index *memoryIndex) Index(document Document) error {
+	terms := weightedTerms(document)
+
+	index.mutex.Lock()
+	index.removeLocked(document.ID)
+	for _, term := range terms {
+		if index.postings[term] == nil {
+			index.postings[term] = map[uint]int{}
+		}
+		index.postings[term][document.ID]++
+	}
+	index.documents[document.ID] = terms
+	index.mutex.Unlock()
+
+	index.vocabulary.add(terms...)
+	return nil
+}
+
+func (index *memoryIndex) Remove(id uint) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.removeLocked(id)
+	return nil
+}
+
+func (index *memoryIndex) removeLocked(id uint) {
+	for _, term := range index.documents[id] {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	delete(index.documents, id)
+}
+
+// Search scores documents with a saturated tf-idf over the query terms,
+// discounting terms that were matched through typo correction.
+func (index *memoryIndex) Search(query string, limit int) ([]Hit, error) {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	total := float64(len(index.documents))
+	scores := map[uint]float64{}
+
+	for _, term := range Analyze(query) {
+		for _, expanded := range index.vocabulary.expand(term) {
+			postings := index.postings[expanded.Term]
+			if len(postings) == 0 {
+				continue
+			}
+
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, tf := range postings {
+				scores[id] += expanded.Weight * idf * float64(tf) / float64(tf+1)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].ID > hits[j].ID
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func hitIDs(hits []Hit) []uint {
+	ids := []uint{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	index := NewMemoryIndex()
+	documents := []Document{
+		{ID: 1, Name: "Sepatu Lari", Category: "Sepatu", Store: "Toko Olahraga"},
+		{ID: 2, Name: "Kaos Polos", Description: "Nyaman untuk lari pagi", Category: "Pakaian"},
+		{ID: 3, Name: "Sandal Jepit", Category: "Sandal"},
+	}
+	for _, document := range documents {
+		if err := index.Index(document); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		limit int
+		want  []uint
+	}{
+		{"sepatu", 0, []uint{1}},
+		// name matches outrank description matches
+		{"lari", 0, []uint{1, 2}},
+		{"lari", 1, []uint{1}},
+		// typos are corrected against the indexed terms
+		{"sepatv", 0, []uint{1}},
+		{"sandl jepit", 0, []uint{3}},
+		{"kulkas", 0, []uint{}},
+		{"", 0, []uint{}},
+	}
+
+	for _, test := range tests {
+		hits, err := index.Search(test.query, test.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hitIDs(hits); !slices.Equal(got, test.want) {
+			t.Errorf("Search(%q, %d) = %v, want %v", test.query, test.limit, got, test.want)
+		}
+	}
+}
+
+func TestMemoryIndexReindexAndRemove(t *testing.T) {
+	index := NewMemoryIndex()
+	index.Index(Document{ID: 1, Name: "Sepatu Lari"})
+	index.Index(Document{ID: 1, Name: "Tas Ransel"})
+
+	hits, _ := index.Search("sepatu", 0)
+	if len(hits) != 0 {
+		t.Errorf("old terms still match after reindexing: %v", hitIDs(hits))
+	}
+
+	hits, _ = index.Search("ransel", 0)
+	if got := hitIDs(hits); !slices.Equal(got, []uint{1}) {
+		t.Errorf("Search(ransel) = %v, want [1]", got)
+	}
+
+	index.Remove(1)
+	hits, _ = index.Search("ransel", 0)
+	if len(hits) != 0 {
+		t.Errorf("removed document still matches: %v", hitIDs(hits))
+	}
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"mini-project-evermos/models/entities"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mysqlIndex stores analyzed product text in a FULLTEXT indexed table and
+// ranks with MATCH ... AGAINST. Stemming and typo expansion happen in Go
+// because MySQL has neither for Indonesian.
+type mysqlIndex struct {
+	database   *gorm.DB
+	vocabulary *vocabulary
+}
+
+func NewMySQLIndex(database *gorm.DB) (SearchIndex, error) {
+	index := &mysqlIndex{
+		database:   database,
+		vocabulary: newVocabulary(),
+	}
+
+	var contents []string
+	err := database.Model(&entities.ProductSearchDocument{}).Pluck("konten", &contents).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, content := range contents {
+		index.vocabulary.add(strings.Fields(content)...)
+	}
+
+	return index, nil
+}
+
+func (index *mysqlIndex) Index(document Document) error {
+	terms := weightedTerms(document)
+
+	row := entities.ProductSearchDocument{
+		IDProduk: document.ID,
+		Konten:   strings.Join(terms, " "),
+	}
+
+	err := index.database.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&row).Error
+	if err != nil {
+		return err
+	}
+
+	index.vocabulary.add(terms...)
+	return nil
+}
+
+func (index *mysqlIndex) Remove(id uint) error {
+	return index.database.Where("id_produk = ?", id).Delete(&entities.ProductSearchDocument{}).Error
+}
+
+func (index *mysqlIndex) Search(query string, limit int) ([]Hit, error) {
+	// Boolean mode without + or - ORs the terms; typo expansions get a "<"
+	// so they still match but contribute less to the score.
+	words := []string{}
+	for _, term := range Analyze(query) {
+		for _, expanded := range index.vocabulary.expand(term) {
+			if expanded.Weight < 1 {
+				words = append(words, "<"+expanded.Term)
+			} else {
+				words = append(words, expanded.Term)
+			}
+		}
+	}
+
+	if len(words) == 0 {
+		return []Hit{}, nil
+	}
+
+	against := strings.Join(words, " ")
+
+	var rows []struct {
+		IDProduk uint
+		Score    float64
+	}
+
+	query_db := index.database.Model(&entities.ProductSearchDocument{}).
+		Select("id_produk, MATCH(konten) AGAINST(? IN BOOLEAN MODE) AS score", against).
+		Where("MATCH(konten) AGAINST(? IN BOOLEAN MODE)", against).
+		Order("score desc")
+	if limit > 0 {
+		query_db = query_db.Limit(limit)
+	}
+
+	err := query_db.Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hits := []Hit{}
+	for _, row := range rows {
+		hits = append(hits, Hit{ID: row.IDProduk, Score: row.Score})
+	}
+	return hits, nil
+}
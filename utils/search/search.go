@@ -0,0 +1,58 @@
+package search
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Document is the searchable view of a product.
+type Document struct {
+	ID          uint
+	Name        string
+	Description string
+	Category    string
+	Store       string
+}
+
+// Hit is a matching document id and its relevance score, higher is better.
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+// SearchIndex keeps product documents searchable by relevance.
+type SearchIndex interface {
+	Index(document Document) error
+	Remove(id uint) error
+	Search(query string, limit int) ([]Hit, error)
+}
+
+// New builds the index selected by SEARCH_DRIVER: "mysql" for the FULLTEXT
+// backed index, "memory" for the embedded inverted index.
+func New(driver string, database *gorm.DB) (SearchIndex, error) {
+	switch driver {
+	case "", "mysql":
+		return NewMySQLIndex(database)
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unknown search driver %q", driver)
+	}
+}
+
+// weightedTerms analyzes a document, repeating name terms so they weigh more
+// than description terms.
+func weightedTerms(document Document) []string {
+	terms := []string{}
+	name := Analyze(document.Name)
+	for i := 0; i < nameWeight; i++ {
+		terms = append(terms, name...)
+	}
+	terms = append(terms, Analyze(document.Category)...)
+	terms = append(terms, Analyze(document.Store)...)
+	terms = append(terms, Analyze(document.Description)...)
+	return terms
+}
+
+const nameWeight = 3
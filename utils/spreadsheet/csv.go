@@ -0,0 +1,80 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+func readCSV(data []byte) ([][]string, error) {
+	// Excel prefixes UTF-8 CSV files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for i, value := range row {
+			row[i] = unescapeFormula(value)
+		}
+	}
+	return rows, nil
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) Writer {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+// WriteRow escapes cells that spreadsheet apps would run as formulas, so
+// values typed by users can not execute when an export is opened
+func (w *csvWriter) WriteRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = escapeFormula(value)
+	}
+	return w.writer.Write(escaped)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// formulaTriggers are the first characters that make Excel, LibreOffice and
+// Google Sheets read a CSV cell as a formula
+const formulaTriggers = "=+-@\t\r"
+
+// isFormula reports whether value starts with a trigger, possibly behind
+// quotes, so values that already start with a quote round trip too
+func isFormula(value string) bool {
+	value = strings.TrimLeft(value, "'")
+	return value != "" && strings.IndexByte(formulaTriggers, value[0]) >= 0
+}
+
+// escapeFormula prefixes a quote, which spreadsheet apps take as "this is
+// text" and do not show
+func escapeFormula(value string) string {
+	if isFormula(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula undoes escapeFormula so exports can be imported again
+func unescapeFormula(value string) string {
+	if strings.HasPrefix(value, "'") && isFormula(value) {
+		return value[1:]
+	}
+	return value
+}
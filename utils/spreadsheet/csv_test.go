@@ -0,0 +1,108 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{"plain", "nama,harga\nKaos,15000\n", [][]string{{"nama", "harga"}, {"Kaos", "15000"}}},
+		{"byte order mark", "\xef\xbb\xbfnama,harga\n", [][]string{{"nama", "harga"}}},
+		{"ragged rows", "a,b,c\nd\n", [][]string{{"a", "b", "c"}, {"d"}}},
+		{"quoted", "\"Kaos, Polos\", \"15\"\"\"\n", [][]string{{"Kaos, Polos", "15\""}}},
+	}
+
+	for _, test := range tests {
+		got, err := Read(FormatCSV, []byte(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !slices.EqualFunc(got, test.want, slices.Equal[[]string]) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		ok       bool
+	}{
+		{"produk.csv", FormatCSV, true},
+		{"Produk.XLSX", FormatXLSX, true},
+		{"produk.xls", "xls", false},
+		{"produk", "", false},
+	}
+
+	for _, test := range tests {
+		got, err := FormatOf(test.filename)
+		if got != test.want || (err == nil) != test.ok {
+			t.Errorf("FormatOf(%q) = %q, %v; want %q, ok %v", test.filename, got, err, test.want, test.ok)
+		}
+	}
+}
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Kaos Polos", "Kaos Polos\n"},
+		{"=HYPERLINK(\"http://evil\")", "\"'=HYPERLINK(\"\"http://evil\"\")\"\n"},
+		{"+62812", "'+62812\n"},
+		{"-5", "'-5\n"},
+		{"@SUM(A1)", "'@SUM(A1)\n"},
+		{"\tcmd", "'\tcmd\n"},
+		{"\rcmd", "\"'\rcmd\"\n"},
+		{"'=1", "''=1\n"},
+		{"'Kaos", "'Kaos\n"},
+		{"a=b", "a=b\n"},
+		{"", "\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		writer, err := NewWriter(FormatCSV, &buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.WriteRow([]string{test.value}); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if buffer.String() != test.want {
+			t.Errorf("%q: wrote %q, want %q", test.value, buffer.String(), test.want)
+		}
+
+		rows, err := Read(FormatCSV, buffer.Bytes())
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+		if test.value != "" && (len(rows) != 1 || len(rows[0]) != 1 || rows[0][0] != test.value) {
+			t.Errorf("%q: read back %q", test.value, rows)
+		}
+	}
+}
+
+func TestReadCSVUnescapesFormulas(t *testing.T) {
+	rows, err := Read(FormatCSV, []byte("'Kaos',',''=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"'Kaos'", "'", "'=1"}
+	if len(rows) != 1 || !slices.Equal(rows[0], want) {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,63 @@
+// Package spreadsheet reads and writes the tabular files used for bulk
+// product import and export. Only the first sheet of an XLSX workbook is used.
+package spreadsheet
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Writer emits rows one at a time so large exports can be streamed.
+type Writer interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+// FormatOf picks the format from a file name's extension.
+func FormatOf(filename string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	return format, Validate(format)
+}
+
+func Validate(format string) error {
+	if format != FormatCSV && format != FormatXLSX {
+		return fmt.Errorf("unsupported format %q, expected csv or xlsx", format)
+	}
+	return nil
+}
+
+// Read returns every row of the file, header included.
+func Read(format string, data []byte) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	}
+	return nil, Validate(format)
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, Validate(format)
+}
+
+// ContentType is the MIME type to serve a format with.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
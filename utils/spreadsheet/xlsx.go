@@ -0,0 +1,319 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Limits on uploaded workbooks, so a small file cannot expand into more than
+// the server can hold. Rows and columns are those of Excel itself.
+const (
+	maxXLSXRows    = 1048576
+	maxXLSXColumns = 16384 // XFD
+	// maxXLSXCells caps the cells of a sheet, counting the blanks filled in
+	// for gaps
+	maxXLSXCells = 4 << 20
+	// maxXLSXPartSize caps a decompressed part of the workbook
+	maxXLSXPartSize = 64 << 20
+)
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxText covers both plain and rich text runs.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (text xlsxText) String() string {
+	if len(text.Runs) == 0 {
+		return text.T
+	}
+	var builder strings.Builder
+	for _, run := range text.Runs {
+		builder.WriteString(run.T)
+	}
+	return builder.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string    `xml:"r,attr"`
+			T      string    `xml:"t,attr"`
+			V      string    `xml:"v"`
+			Inline *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %v", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheet_path, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	file, ok := files[sheet_path]
+	if !ok {
+		return nil, fmt.Errorf("invalid xlsx file: missing %s", sheet_path)
+	}
+
+	var sheet xlsxSheet
+	if err := decodeZipXML(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	cells := 0
+	for _, row := range sheet.Rows {
+		// Blank rows are left out of the XML, keep numbering aligned
+		number := row.R
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		if number < 0 || number > maxXLSXRows {
+			return nil, fmt.Errorf("invalid row number %d", number)
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, []string{})
+		}
+
+		values := []string{}
+		for j, cell := range row.Cells {
+			column := j
+			if cell.R != "" {
+				column, err = columnIndex(cell.R)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: %v", number, err)
+				}
+			}
+			cells += max(column-len(values), 0) + 1
+			if cells > maxXLSXCells {
+				return nil, fmt.Errorf("the sheet has more than %d cells", maxXLSXCells)
+			}
+			for len(values) < column {
+				values = append(values, "")
+			}
+
+			var value string
+			switch cell.T {
+			case "s":
+				index, err := strconv.Atoi(cell.V)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("row %d: invalid shared string %q", number, cell.V)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					value = cell.Inline.String()
+				}
+			default:
+				value = cell.V
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	file, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("invalid xlsx file: missing workbook")
+	}
+	if err := decodeZipXML(file, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("invalid xlsx file: workbook has no sheets")
+	}
+
+	var relationships xlsxRelationships
+	if file, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodeZipXML(file, &relationships); err != nil {
+			return "", err
+		}
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipXML(file *zip.File, target interface{}) error {
+	if file.UncompressedSize64 > maxXLSXPartSize {
+		return fmt.Errorf("invalid xlsx file: %s is larger than %d MB", file.Name, maxXLSXPartSize>>20)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The size in the header is not to be trusted
+	limited := io.LimitReader(reader, maxXLSXPartSize)
+	if err := xml.NewDecoder(limited).Decode(target); err != nil {
+		return fmt.Errorf("invalid xlsx file: %s: %v", file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex turns a cell reference such as "AB12" into a zero based column.
+func columnIndex(reference string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range reference {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+		if index > maxXLSXColumns {
+			return 0, fmt.Errorf("cell reference %q is past column XFD", reference)
+		}
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", reference)
+	}
+	return index - 1, nil
+}
+
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+const xlsxRootRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+// xlsxWriter writes a single sheet workbook of inline strings, streaming the
+// rows straight into the zip entry.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	row     int
+}
+
+func newXLSXWriter(w io.Writer) (Writer, error) {
+	archive := zip.NewWriter(w)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRelationships},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRelationships},
+	}
+	for _, part := range parts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+func (w *xlsxWriter) WriteRow(row []string) error {
+	w.row++
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, `<row r="%d">`, w.row)
+	for i, value := range row {
+		fmt.Fprintf(&builder, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(i), w.row)
+		if err := xml.EscapeText(&builder, []byte(value)); err != nil {
+			return err
+		}
+		builder.WriteString(`</t></is></c>`)
+	}
+	builder.WriteString(`</row>`)
+
+	_, err := io.WriteString(w.sheet, builder.String())
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
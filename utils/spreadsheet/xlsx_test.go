@@ -0,0 +1,168 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// buildXLSX zips a minimal workbook around the given sheet and shared
+// strings; shared may be empty.
+func buildXLSX(t *testing.T, sheet string, shared string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	parts := map[string]string{
+		"xl/workbook.xml":            xlsxWorkbookXML,
+		"xl/_rels/workbook.xml.rels": xlsxWorkbookRelationships,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheet + `</sheetData></worksheet>`,
+	}
+	if shared != "" {
+		parts["xl/sharedStrings.xml"] = `<sst>` + shared + `</sst>`
+	}
+	for name, content := range parts {
+		entry, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(entry, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheet  string
+		shared string
+		want   [][]string
+	}{
+		{
+			name:   "shared, inline and plain values",
+			sheet:  `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>harga</t></is></c></row><row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2"><v>15000</v></c></row>`,
+			shared: `<si><t>nama</t></si><si><r><t>Kaos </t></r><r><t>Polos</t></r></si>`,
+			want:   [][]string{{"nama", "harga"}, {"Kaos Polos", "15000"}},
+		},
+		{
+			name:  "blank rows and cells keep their position",
+			sheet: `<row r="1"><c r="A1"><v>a</v></c></row><row r="3"><c r="C3"><v>c</v></c></row>`,
+			want:  [][]string{{"a"}, {}, {"", "", "c"}},
+		},
+		{
+			name:  "cells without references follow each other",
+			sheet: `<row><c><v>a</v></c><c><v>b</v></c></row>`,
+			want:  [][]string{{"a", "b"}},
+		},
+		{
+			name:  "last column",
+			sheet: `<row r="1"><c r="XFD1"><v>x</v></c></row>`,
+			want:  [][]string{append(make([]string, maxXLSXColumns-1), "x")},
+		},
+	}
+
+	for _, test := range tests {
+		rows, err := readXLSX(buildXLSX(t, test.sheet, test.shared))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !slices.EqualFunc(rows, test.want, slices.Equal[[]string]) {
+			t.Errorf("%s: got %q, want %q", test.name, rows, test.want)
+		}
+	}
+}
+
+func TestReadXLSXRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheet string
+	}{
+		{"column past XFD", `<row r="1"><c r="XFE1"><v>x</v></c></row>`},
+		{"far out column", `<row r="1"><c r="ZZZZZZ1"><v>x</v></c></row>`},
+		{"row past the last", `<row r="1048577"><c r="A1048577"><v>x</v></c></row>`},
+		{"huge row number", `<row r="2000000000"><c><v>x</v></c></row>`},
+		{"invalid shared string", `<row r="1"><c r="A1" t="s"><v>7</v></c></row>`},
+		{"too many cells", strings.Repeat(`<row><c r="XFD1"><v>x</v></c></row>`, maxXLSXCells/maxXLSXColumns+1)},
+	}
+
+	for _, test := range tests {
+		if _, err := readXLSX(buildXLSX(t, test.sheet, "")); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestReadXLSXRejectsLargeParts(t *testing.T) {
+	// Whitespace compresses to next to nothing
+	padding := strings.Repeat(" ", maxXLSXPartSize)
+	data := buildXLSX(t, `<row r="1"><c r="A1"><v>x</v></c></row>`+padding, "")
+	if len(data) > 1<<20 {
+		t.Fatalf("test archive is %d bytes, expected a small one", len(data))
+	}
+
+	if _, err := readXLSX(data); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("got %v, want a size error", err)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      int
+		ok        bool
+	}{
+		{"A1", 0, true},
+		{"Z9", 25, true},
+		{"AA10", 26, true},
+		{"XFD1", maxXLSXColumns - 1, true},
+		{"XFE1", 0, false},
+		{"ZZZZZZZZZZZZZZ1", 0, false},
+		{"12", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := columnIndex(test.reference)
+		if (err == nil) != test.ok || got != test.want {
+			t.Errorf("columnIndex(%q) = %d, %v; want %d, ok %v", test.reference, got, err, test.want, test.ok)
+		}
+	}
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"nama_produk", "harga"},
+		{"Kaos <Polos> & Co", "15000"},
+		{"", "", "third"},
+	}
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(FormatXLSX, &buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(FormatXLSX, buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(got, rows, slices.Equal[[]string]) {
+		t.Errorf("got %q, want %q", got, rows)
+	}
+}
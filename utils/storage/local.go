@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type localStorage struct {
+	directory  string
+	public_url string
+}
+
+// NewLocalStorage keeps files in directory. URLs are public_url followed by
+// the file name.
+func NewLocalStorage(directory string, public_url string) (BlobStorage, error) {
+	if directory == "" {
+		directory = "uploads"
+	}
+	if public_url == "" {
+		public_url = "/uploads"
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+
+	return &localStorage{
+		directory:  directory,
+		public_url: strings.TrimSuffix(public_url, "/"),
+	}, nil
+}
+
+func (storage *localStorage) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(storage.directory, key), nil
+}
+
+func (storage *localStorage) Put(key string, data []byte, content_type string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Write under a temporary name first so a half written file is never
+	// read under the final one
+	tmp, err := os.CreateTemp(storage.directory, ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
+
+func (storage *localStorage) Open(key string, offset int64, length int64) (io.ReadCloser, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if length < 0 {
+		return file, nil
+	}
+
+	return limitedFile{io.LimitReader(file, length), file}, nil
+}
+
+func (storage *localStorage) Stat(key string) (Object, error) {
+	path, err := storage.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	return Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (storage *localStorage) Delete(key string) error {
+	path, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (storage *localStorage) URL(key string) string {
+	return storage.public_url + "/" + url.PathEscape(key)
+}
+
+// SignedURL is the plain URL: local files are served publicly by the media
+// route, so there is nothing to grant.
+func (storage *localStorage) SignedURL(key string, expires time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return storage.URL(key), nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLocalStorage(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "https://api.example.com/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.Put("foto.jpg", []byte("0123456789"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset int64
+		length int64
+		want   string
+	}{
+		{0, -1, "0123456789"},
+		{4, -1, "456789"},
+		{2, 3, "234"},
+		{8, 10, "89"},
+	}
+
+	for _, test := range tests {
+		reader, err := storage.Open("foto.jpg", test.offset, test.length)
+		if err != nil {
+			t.Errorf("%d+%d: %v", test.offset, test.length, err)
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil || string(data) != test.want {
+			t.Errorf("%d+%d: got %q, %v, want %q", test.offset, test.length, data, err, test.want)
+		}
+	}
+
+	object, err := storage.Stat("foto.jpg")
+	if err != nil || object.Size != 10 || object.ContentType != "image/jpeg" {
+		t.Errorf("got %+v, %v", object, err)
+	}
+
+	if got := storage.URL("foto produk.jpg"); got != "https://api.example.com/uploads/foto%20produk.jpg" {
+		t.Errorf("got URL %q", got)
+	}
+	if got, err := storage.SignedURL("foto.jpg", time.Hour); err != nil || got != storage.URL("foto.jpg") {
+		t.Errorf("got signed URL %q, %v", got, err)
+	}
+
+	if err := storage.Delete("foto.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Delete("foto.jpg"); err != nil {
+		t.Errorf("deleting twice: %v", err)
+	}
+	if _, err := storage.Open("foto.jpg", 0, -1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v after delete, want ErrNotFound", err)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key string
+		ok  bool
+	}{
+		{"foto.jpg", true},
+		{".hidden", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../foto.jpg", false},
+		{"a\\b.jpg", false},
+		{"foto\x00.jpg", false},
+	}
+
+	for _, test := range tests {
+		if err := validKey(test.key); (err == nil) != test.ok {
+			t.Errorf("validKey(%q) = %v", test.key, err)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateFormat      = "20060102T150405Z"
+	s3MaxPresign      = 7 * 24 * time.Hour
+)
+
+type s3Storage struct {
+	config     S3Config
+	endpoint   *url.URL
+	public_url string
+	client     *http.Client
+}
+
+// NewS3Storage talks to an S3 compatible API with path style URLs
+// (endpoint/bucket/key), which AWS and MinIO both accept. Requests are signed
+// with AWS Signature Version 4.
+func NewS3Storage(config S3Config, public_url string) (BlobStorage, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("s3 storage needs an endpoint, bucket, access key and secret key")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+
+	if public_url == "" {
+		public_url = endpoint.String() + "/" + config.Bucket
+	}
+
+	return &s3Storage{
+		config:     config,
+		endpoint:   endpoint,
+		public_url: strings.TrimSuffix(public_url, "/"),
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (storage *s3Storage) objectURL(key string) *url.URL {
+	object := *storage.endpoint
+	object.Path = storage.endpoint.Path + "/" + storage.config.Bucket + "/" + key
+	object.RawPath = storage.endpoint.EscapedPath() + "/" + s3Escape(storage.config.Bucket) + "/" + s3Escape(key)
+	return &object
+}
+
+func (storage *s3Storage) Put(key string, data []byte, content_type string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if content_type != "" {
+		header.Set("Content-Type", content_type)
+	}
+
+	response, err := storage.do(http.MethodPut, key, data, header)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (storage *s3Storage) Open(key string, offset int64, length int64) (io.ReadCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	header := http.Header{}
+	if length > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := storage.do(http.MethodGet, key, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+func (storage *s3Storage) Stat(key string) (Object, error) {
+	if err := validKey(key); err != nil {
+		return Object{}, err
+	}
+
+	response, err := storage.do(http.MethodHead, key, nil, http.Header{})
+	if err != nil {
+		return Object{}, err
+	}
+	response.Body.Close()
+
+	modified, _ := http.ParseTime(response.Header.Get("Last-Modified"))
+
+	return Object{
+		Key:         key,
+		Size:        response.ContentLength,
+		ContentType: response.Header.Get("Content-Type"),
+		ModTime:     modified,
+	}, nil
+}
+
+func (storage *s3Storage) Delete(key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	// S3 answers 204 whether or not the object existed
+	response, err := storage.do(http.MethodDelete, key, nil, http.Header{})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (storage *s3Storage) URL(key string) string {
+	return storage.public_url + "/" + s3Escape(key)
+}
+
+// SignedURL presigns a GET for buckets that are not publicly readable. S3
+// caps the lifetime at seven days.
+func (storage *s3Storage) SignedURL(key string, expires time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if expires < time.Second {
+		expires = time.Second
+	}
+	if expires > s3MaxPresign {
+		expires = s3MaxPresign
+	}
+
+	now := time.Now().UTC()
+	object := storage.objectURL(key)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    storage.config.AccessKey + "/" + storage.scope(now),
+		"X-Amz-Date":          now.Format(s3DateFormat),
+		"X-Amz-Expires":       strconv.Itoa(int(expires.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	canonical_query := s3CanonicalQuery(query)
+
+	canonical_request := strings.Join([]string{
+		http.MethodGet,
+		object.EscapedPath(),
+		canonical_query,
+		"host:" + object.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	object.RawQuery = canonical_query + "&X-Amz-Signature=" + storage.signature(now, canonical_request)
+	return object.String(), nil
+}
+
+func (storage *s3Storage) do(method string, key string, body []byte, header http.Header) (*http.Response, error) {
+	object := storage.objectURL(key)
+
+	request, err := http.NewRequest(method, object.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header = header
+
+	payload_hash := sha256.Sum256(body)
+	storage.sign(request, hex.EncodeToString(payload_hash[:]), time.Now().UTC())
+
+	response, err := storage.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		defer response.Body.Close()
+		return nil, s3Error(method, key, response)
+	}
+
+	return response, nil
+}
+
+// sign adds the Signature Version 4 Authorization header, signing the host
+// and x-amz-* headers
+func (storage *s3Storage) sign(request *http.Request, payload_hash string, now time.Time) {
+	request.Header.Set("X-Amz-Date", now.Format(s3DateFormat))
+	request.Header.Set("X-Amz-Content-Sha256", payload_hash)
+
+	headers := map[string]string{
+		"host":                 request.URL.Host,
+		"x-amz-content-sha256": payload_hash,
+		"x-amz-date":           now.Format(s3DateFormat),
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical_headers strings.Builder
+	for _, name := range names {
+		canonical_headers.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed_headers := strings.Join(names, ";")
+
+	canonical_request := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		"",
+		canonical_headers.String(),
+		signed_headers,
+		payload_hash,
+	}, "\n")
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, storage.config.AccessKey, storage.scope(now), signed_headers, storage.signature(now, canonical_request),
+	))
+}
+
+func (storage *s3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + storage.config.Region + "/s3/aws4_request"
+}
+
+func (storage *s3Storage) signature(now time.Time, canonical_request string) string {
+	request_hash := sha256.Sum256([]byte(canonical_request))
+	string_to_sign := strings.Join([]string{
+		s3Algorithm,
+		now.Format(s3DateFormat),
+		storage.scope(now),
+		hex.EncodeToString(request_hash[:]),
+	}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+storage.config.SecretKey), now.Format("20060102"))
+	key = s3HMAC(key, storage.config.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+
+	return hex.EncodeToString(s3HMAC(key, string_to_sign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3CanonicalQuery(query map[string]string) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, s3Escape(name)+"="+s3Escape(query[name]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent-encodes everything but the RFC 3986 unreserved
+// characters, the encoding Signature Version 4 expects
+func s3Escape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func s3Error(method string, key string, response *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s: %s", method, key, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 %s %s: %s", method, key, response.Status)
+}
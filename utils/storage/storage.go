@@ -0,0 +1,93 @@
+// Package storage keeps uploaded files in a blob store: the local file
+// system or an S3 compatible bucket such as AWS S3 or MinIO.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid file name")
+)
+
+// Object describes a stored file
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// BlobStorage stores files under flat keys, the upload file names.
+type BlobStorage interface {
+	Put(key string, data []byte, content_type string) error
+	// Open reads length bytes from offset, or up to the end when length is
+	// negative.
+	Open(key string, offset int64, length int64) (io.ReadCloser, error)
+	Stat(key string) (Object, error)
+	// Delete succeeds when the file is already gone.
+	Delete(key string) error
+	// URL is where clients can fetch a publicly readable file.
+	URL(key string) string
+	// SignedURL grants access to key until expires has passed, for stores
+	// that are not publicly readable.
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+type Config struct {
+	Driver    string // "local" or "s3"
+	Directory string // local: where files are kept
+	PublicURL string // base of URL, defaults to the bucket URL for s3
+	S3        S3Config
+}
+
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.ap-southeast-1.amazonaws.com or http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// New builds the storage selected by STORAGE_DRIVER, defaulting to local.
+func New(config Config) (BlobStorage, error) {
+	switch config.Driver {
+	case "", "local":
+		return NewLocalStorage(config.Directory, config.PublicURL)
+	case "s3":
+		return NewS3Storage(config.S3, config.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
+	}
+}
+
+// URLResolver returns how stored file names become the URLs put in
+// responses. Buckets that are not publicly readable need signed URLs, which
+// last expires; a URL that fails to sign falls back to the plain one.
+func URLResolver(storage BlobStorage, signed bool, expires time.Duration) func(key string) string {
+	if !signed {
+		return storage.URL
+	}
+
+	return func(key string) string {
+		url, err := storage.SignedURL(key, expires)
+		if err != nil {
+			return storage.URL(key)
+		}
+		return url
+	}
+}
+
+// validKey only accepts plain file names so keys can not escape the
+// directory or bucket prefix they are stored under
+func validKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, "/\\\x00") {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Kinds of suggestions kept in the trie
+const (
+	KindProduct  = "product"
+	KindCategory = "category"
+	KindStore    = "store"
+	KindQuery    = "query"
+)
+
+// Entry is a suggestion. Entries are identified by Kind and ID; queries use
+// their text as identity and leave ID zero.
+type Entry struct {
+	Kind   string
+	ID     uint
+	Text   string
+	Slug   string
+	Weight float64
+}
+
+type entryKey struct {
+	kind string
+	id   uint
+	text string
+}
+
+func keyOf(entry Entry) entryKey {
+	if entry.Kind == KindQuery {
+		return entryKey{kind: entry.Kind, text: Normalize(entry.Text)}
+	}
+	return entryKey{kind: entry.Kind, id: entry.ID}
+}
+
+type node struct {
+	children map[rune]*node
+	entries  map[entryKey]bool
+}
+
+func newNode() *node {
+	return &node{children: map[rune]*node{}, entries: map[entryKey]bool{}}
+}
+
+// Trie matches entries by the prefix of any word in their text, so "syari"
+// finds "Gamis Syari".
+type Trie struct {
+	mutex   sync.RWMutex
+	root    *node
+	entries map[entryKey]Entry
+	keys    map[entryKey][]string
+}
+
+func NewTrie() *Trie {
+	return &Trie{
+		root:    newNode(),
+		entries: map[entryKey]Entry{},
+		keys:    map[entryKey][]string{},
+	}
+}
+
+// Normalize lowercases text and collapses anything that is not a letter or
+// digit into single spaces.
+func Normalize(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Upsert adds an entry or replaces the one with the same identity.
+func (trie *Trie) Upsert(entry Entry) {
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+
+	key := keyOf(entry)
+	trie.removeLocked(key)
+
+	normalized := Normalize(entry.Text)
+	if normalized == "" {
+		return
+	}
+
+	// Index the text from the start of every word
+	words := strings.Fields(normalized)
+	paths := []string{}
+	for i := range words {
+		paths = append(paths, strings.Join(words[i:], " "))
+	}
+
+	for _, path := range paths {
+		current := trie.root
+		for _, r := range path {
+			next, ok := current.children[r]
+			if !ok {
+				next = newNode()
+				current.children[r] = next
+			}
+			current = next
+		}
+		current.entries[key] = true
+	}
+
+	trie.entries[key] = entry
+	trie.keys[key] = paths
+}
+
+// AddWeight bumps an entry's weight, inserting it when missing.
+func (trie *Trie) AddWeight(entry Entry, weight float64) {
+	trie.mutex.RLock()
+	existing, ok := trie.entries[keyOf(entry)]
+	trie.mutex.RUnlock()
+
+	if ok {
+		entry.Weight = existing.Weight
+	}
+	entry.Weight += weight
+	trie.Upsert(entry)
+}
+
+func (trie *Trie) Remove(kind string, id uint) {
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+
+	trie.removeLocked(entryKey{kind: kind, id: id})
+}
+
+func (trie *Trie) removeLocked(key entryKey) {
+	for _, path := range trie.keys[key] {
+		current := trie.root
+		for _, r := range path {
+			current = current.children[r]
+			if current == nil {
+				break
+			}
+		}
+		if current != nil {
+			delete(current.entries, key)
+		}
+	}
+	delete(trie.keys, key)
+	delete(trie.entries, key)
+}
+
+// Prefix returns up to limit entries of the given kind whose words start with
+// prefix, most popular first. A limit of zero returns every match.
+func (trie *Trie) Prefix(prefix string, kind string, limit int) []Entry {
+	trie.mutex.RLock()
+	defer trie.mutex.RUnlock()
+
+	// An empty prefix matches everything of the kind
+	normalized := Normalize(prefix)
+
+	current := trie.root
+	for _, r := range normalized {
+		current = current.children[r]
+		if current == nil {
+			return []Entry{}
+		}
+	}
+
+	found := map[entryKey]bool{}
+	stack := []*node{current}
+	for len(stack) > 0 {
+		last := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for key := range last.entries {
+			if key.kind == kind {
+				found[key] = true
+			}
+		}
+		for _, child := range last.children {
+			stack = append(stack, child)
+		}
+	}
+
+	results := []Entry{}
+	for key := range found {
+		results = append(results, trie.entries[key])
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Weight == results[j].Weight {
+			return results[i].Text < results[j].Text
+		}
+		return results[i].Weight > results[j].Weight
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Replace swaps in the contents of another trie, used after a full rebuild.
+func (trie *Trie) Replace(other *Trie) {
+	other.mutex.RLock()
+	defer other.mutex.RUnlock()
+	trie.mutex.Lock()
+	defer trie.mutex.Unlock()
+
+	trie.root = other.root
+	trie.entries = other.entries
+	trie.keys = other.keys
+}
@@ -0,0 +1,115 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+func texts(entries []Entry) []string {
+	result := []string{}
+	for _, entry := range entries {
+		result = append(result, entry.Text)
+	}
+	return result
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Gamis Syari", "gamis syari"},
+		{"  Kaos--Polos!! ", "kaos polos"},
+		{"USB-C 3.1", "usb c 3 1"},
+		{"???", ""},
+	}
+
+	for _, test := range tests {
+		if got := Normalize(test.text); got != test.want {
+			t.Errorf("Normalize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestTriePrefix(t *testing.T) {
+	trie := NewTrie()
+	trie.Upsert(Entry{Kind: KindProduct, ID: 1, Text: "Gamis Syari", Weight: 5})
+	trie.Upsert(Entry{Kind: KindProduct, ID: 2, Text: "Gamis Polos", Weight: 9})
+	trie.Upsert(Entry{Kind: KindProduct, ID: 3, Text: "Kaos Polos", Weight: 1})
+	trie.Upsert(Entry{Kind: KindCategory, ID: 1, Text: "Gamis", Weight: 100})
+
+	tests := []struct {
+		prefix string
+		kind   string
+		limit  int
+		want   []string
+	}{
+		// heaviest first
+		{"gam", KindProduct, 0, []string{"Gamis Polos", "Gamis Syari"}},
+		// any word can start the match
+		{"syar", KindProduct, 0, []string{"Gamis Syari"}},
+		{"polos", KindProduct, 0, []string{"Gamis Polos", "Kaos Polos"}},
+		{"gamis p", KindProduct, 0, []string{"Gamis Polos"}},
+		{"GAM", KindProduct, 1, []string{"Gamis Polos"}},
+		{"gam", KindCategory, 0, []string{"Gamis"}},
+		{"sepatu", KindProduct, 0, []string{}},
+		// an empty prefix lists the kind
+		{"", KindProduct, 0, []string{"Gamis Polos", "Gamis Syari", "Kaos Polos"}},
+	}
+
+	for _, test := range tests {
+		if got := texts(trie.Prefix(test.prefix, test.kind, test.limit)); !slices.Equal(got, test.want) {
+			t.Errorf("Prefix(%q, %s, %d) = %q, want %q", test.prefix, test.kind, test.limit, got, test.want)
+		}
+	}
+}
+
+func TestTrieUpsertReplaces(t *testing.T) {
+	trie := NewTrie()
+	trie.Upsert(Entry{Kind: KindProduct, ID: 1, Text: "Gamis Syari"})
+	trie.Upsert(Entry{Kind: KindProduct, ID: 1, Text: "Kaos Polos"})
+
+	if got := trie.Prefix("gamis", KindProduct, 0); len(got) != 0 {
+		t.Errorf("renamed entry still matches its old text: %q", texts(got))
+	}
+	if got := texts(trie.Prefix("kaos", KindProduct, 0)); !slices.Equal(got, []string{"Kaos Polos"}) {
+		t.Errorf("Prefix(kaos) = %q, want [Kaos Polos]", got)
+	}
+
+	trie.Remove(KindProduct, 1)
+	if got := trie.Prefix("kaos", KindProduct, 0); len(got) != 0 {
+		t.Errorf("removed entry still matches: %q", texts(got))
+	}
+}
+
+func TestTrieAddWeight(t *testing.T) {
+	trie := NewTrie()
+	trie.AddWeight(Entry{Kind: KindQuery, Text: "gamis"}, 1)
+	trie.AddWeight(Entry{Kind: KindQuery, Text: "Gamis!"}, 2)
+	trie.AddWeight(Entry{Kind: KindQuery, Text: "gamis syari"}, 2)
+
+	got := trie.Prefix("gamis", KindQuery, 0)
+	if len(got) != 2 {
+		t.Fatalf("Prefix(gamis) = %q, want two queries", texts(got))
+	}
+	// queries are identified by their normalized text
+	if got[0].Weight != 3 || got[1].Weight != 2 {
+		t.Errorf("weights = %v and %v, want 3 and 2", got[0].Weight, got[1].Weight)
+	}
+}
+
+func TestTrieReplace(t *testing.T) {
+	trie := NewTrie()
+	trie.Upsert(Entry{Kind: KindProduct, ID: 1, Text: "Gamis Syari"})
+
+	fresh := NewTrie()
+	fresh.Upsert(Entry{Kind: KindProduct, ID: 2, Text: "Kaos Polos"})
+	trie.Replace(fresh)
+
+	if got := trie.Prefix("gamis", KindProduct, 0); len(got) != 0 {
+		t.Errorf("replaced entry still matches: %q", texts(got))
+	}
+	if got := texts(trie.Prefix("kaos", KindProduct, 0)); !slices.Equal(got, []string{"Kaos Polos"}) {
+		t.Errorf("Prefix(kaos) = %q, want [Kaos Polos]", got)
+	}
+}